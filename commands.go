@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+	"github.com/jwilander/contributor-leaderboard/web"
+)
+
+// runCommand runs a command given on the command line instead of starting
+// the server, returning the exit code
+func runCommand(config *model.Config, args []string) int {
+	switch args[0] {
+	case "replay":
+		return replayCommand(config, args[1:])
+	case "recompute":
+		return recomputeCommand(config, args[1:])
+	case "backfill":
+		return backfillCommand(config, args[1:])
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %v\n", args[0])
+	return 2
+}
+
+// replayCommand replays archived deliveries, either the ids given as
+// arguments or those matching the filters
+func replayCommand(config *model.Config, args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	commit := flags.Bool("commit", false, "award the points instead of reporting them")
+	eventType := flags.String("event-type", "", "only replay deliveries of this event type")
+	username := flags.String("username", "", "only replay deliveries sent by this user")
+	since := flags.String("since", "", "only replay deliveries received on or after this date, YYYY-MM-DD")
+	until := flags.String("until", "", "only replay deliveries received on or before this date, YYYY-MM-DD")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	request := &model.ReplayRequest{
+		Ids:       flags.Args(),
+		EventType: *eventType,
+		Username:  *username,
+		Since:     *since,
+		Until:     *until,
+		Commit:    *commit,
+	}
+
+	if err := web.InitServer(*config); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to start, err=%v\n", err.Error())
+		return 1
+	}
+	defer web.StopServer()
+
+	report, err := web.ReplayDeliveries(request, "command line")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to replay deliveries, err=%v\n", err.Error())
+		return 1
+	}
+
+	fmt.Println(report.ToJson())
+	return 0
+}
+
+// recomputeCommand rebuilds the configured leaderboard's scores from its
+// ledger under the current scoring settings
+func recomputeCommand(config *model.Config, args []string) int {
+	flags := flag.NewFlagSet("recompute", flag.ContinueOnError)
+	commit := flags.Bool("commit", false, "save the recomputed scores instead of reporting the changes")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := web.InitServer(*config); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to start, err=%v\n", err.Error())
+		return 1
+	}
+	defer web.StopServer()
+
+	report, err := web.RecomputeScores(web.Srv.Leaderboard.Id, *commit, "command line")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to recompute scores, err=%v\n", err.Error())
+		return 1
+	}
+
+	fmt.Println(report.ToJson())
+	return 0
+}
+
+// backfillCommand scores the history of a repository, from the source
+// named by the first argument
+func backfillCommand(config *model.Config, args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "github":
+			return backfillGitHubCommand(config, args[1:])
+		case "git":
+			return backfillGitCommand(config, args[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: leaderboard backfill github|git [flags]")
+	return 2
+}
+
+func backfillGitHubCommand(config *model.Config, args []string) int {
+	flags := flag.NewFlagSet("backfill github", flag.ContinueOnError)
+	commit := flags.Bool("commit", false, "award the points instead of reporting them")
+	repository := flags.String("repository", "", "the repository to backfill, owner/name")
+	dir := flags.String("dir", "", "read exported API responses from this directory instead of the API")
+	apiUrl := flags.String("api-url", utils.GITHUB_API_URL, "the GitHub API base URL")
+	token := flags.String("token", os.Getenv("GITHUB_TOKEN"), "the GitHub API token, $GITHUB_TOKEN by default")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if len(*repository) == 0 {
+		fmt.Fprintln(os.Stderr, "A -repository is required")
+		return 2
+	}
+
+	client := utils.NewGitHubApiClient(*apiUrl, *repository, *token)
+	if len(*dir) > 0 {
+		var err error
+		if client, err = utils.NewGitHubFileClient(*dir, *repository); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read exported data, err=%v\n", err.Error())
+			return 1
+		}
+	}
+
+	events, err := client.GetEvents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read repository history, err=%v\n", err.Error())
+		return 1
+	}
+
+	if err := web.InitServer(*config); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to start, err=%v\n", err.Error())
+		return 1
+	}
+	defer web.StopServer()
+
+	fmt.Println(web.BackfillEvents(events, *commit, "command line").ToJson())
+	return 0
+}
+
+func backfillGitCommand(config *model.Config, args []string) int {
+	flags := flag.NewFlagSet("backfill git", flag.ContinueOnError)
+	commit := flags.Bool("commit", false, "award the points instead of reporting them")
+	dir := flags.String("dir", ".", "the git repository to backfill")
+	branch := flags.String("branch", "HEAD", "the branch whose history is scored")
+	mergesOnly := flags.Bool("merges-only", false, "only score merges into the branch, as merged pull requests")
+	repository := flags.String("repository", "", "the repository name shown with each contribution, the directory's name by default")
+	repositoryUrl := flags.String("url", "", "the repository's web address, such as https://github.com/owner/name, for linking commits")
+	identities := flags.String("identities", "", "a JSON file mapping author emails to usernames")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if len(*repository) == 0 {
+		if abs, err := filepath.Abs(*dir); err == nil {
+			*repository = filepath.Base(abs)
+		}
+	}
+
+	mapping := map[string]string{}
+	if len(*identities) > 0 {
+		file, err := os.Open(*identities)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read identities, err=%v\n", err.Error())
+			return 1
+		}
+
+		err = json.NewDecoder(file).Decode(&mapping)
+		file.Close()
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to parse identities, err=%v\n", err.Error())
+			return 1
+		}
+	}
+
+	commits, err := utils.ReadGitLog(*dir, *branch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read repository history, err=%v\n", err.Error())
+		return 1
+	}
+
+	if err := web.InitServer(*config); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to start, err=%v\n", err.Error())
+		return 1
+	}
+	defer web.StopServer()
+
+	history := &model.GitHistory{
+		Repository: *repository,
+		Url:        *repositoryUrl,
+		MergesOnly: *mergesOnly,
+	}
+
+	report, err := web.BackfillGitHistory(history, commits, mapping, *commit, "command line")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to backfill repository history, err=%v\n", err.Error())
+		return 1
+	}
+
+	fmt.Println(report.ToJson())
+	return 0
+}
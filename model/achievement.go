@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	BADGE_RULE_MERGED_PULL_REQUESTS = "merged_pull_requests"
+	BADGE_RULE_REVIEWS              = "reviews"
+	BADGE_RULE_WEEKLY_STREAK        = "weekly_streak"
+	BADGE_RULE_REPOSITORIES         = "repositories"
+)
+
+// BadgeDefinition describes a badge that is earned once the user's value for
+// Rule reaches Threshold
+type BadgeDefinition struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Rule        string `json:"rule"`
+	Threshold   int    `json:"threshold"`
+}
+
+type Achievement struct {
+	LeaderboardId string           `json:"leaderboard_id"`
+	Username      string           `json:"username"`
+	BadgeId       string           `json:"badge_id"`
+	EarnedAt      int64            `json:"earned_at"`
+	Badge         *BadgeDefinition `json:"badge,omitempty" db:"-"`
+}
+
+func (a *Achievement) EarnedTime() time.Time {
+	return time.Unix(0, a.EarnedAt*int64(time.Millisecond))
+}
+
+func DefaultBadgeDefinitions() []*BadgeDefinition {
+	return []*BadgeDefinition{
+		{Id: "first_pull_request", Name: "First PR", Description: "Got a first pull request merged", Rule: BADGE_RULE_MERGED_PULL_REQUESTS, Threshold: 1},
+		{Id: "ten_pull_requests", Name: "10 PRs", Description: "Got 10 pull requests merged", Rule: BADGE_RULE_MERGED_PULL_REQUESTS, Threshold: 10},
+		{Id: "first_review", Name: "Reviewer", Description: "Reviewed a first pull request", Rule: BADGE_RULE_REVIEWS, Threshold: 1},
+		{Id: "five_week_streak", Name: "5 Week Streak", Description: "Contributed 5 weeks in a row", Rule: BADGE_RULE_WEEKLY_STREAK, Threshold: 5},
+		{Id: "three_repositories", Name: "Explorer", Description: "Contributed to 3 repositories", Rule: BADGE_RULE_REPOSITORIES, Threshold: 3},
+	}
+}
+
+func BadgeDefinitionListToJson(l []*BadgeDefinition) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AchievementListToJson(l []*Achievement) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// EvaluateAchievements replays a user's ledger history and returns the time
+// each badge was earned at, keyed by badge id. Merged pull requests that were
+// later reverted stop counting towards badges from the time of the revert.
+func EvaluateAchievements(definitions []*BadgeDefinition, history []*LedgerEntry) map[string]int64 {
+	entries := make([]*LedgerEntry, len(history))
+	copy(entries, history)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreateAt < entries[j].CreateAt
+	})
+
+	earned := make(map[string]int64)
+	merged := make(map[string]bool)
+	repositories := make(map[string]bool)
+	reviews := 0
+	streak := newStreakCounter(STREAK_PERIOD_WEEK)
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case LEDGER_TYPE_PULL_REQUEST_MERGED:
+			merged[entry.Id] = true
+			repositories[entry.Repository] = true
+		case LEDGER_TYPE_REVIEW:
+			reviews++
+		case LEDGER_TYPE_REVERT:
+			delete(merged, entry.RelatedId)
+		}
+
+		if entry.IsContribution() {
+			streak.Add(entry.CreateAt)
+		}
+
+		values := map[string]int{
+			BADGE_RULE_MERGED_PULL_REQUESTS: len(merged),
+			BADGE_RULE_REVIEWS:              reviews,
+			BADGE_RULE_WEEKLY_STREAK:        streak.longest,
+			BADGE_RULE_REPOSITORIES:         len(repositories),
+		}
+
+		for _, definition := range definitions {
+			if _, ok := earned[definition.Id]; ok {
+				continue
+			}
+
+			if value, ok := values[definition.Rule]; ok && value >= definition.Threshold {
+				earned[definition.Id] = entry.CreateAt
+			}
+		}
+	}
+
+	return earned
+}
+
+// GroupBadges groups the badges of the achievements by who earned them, by
+// contributor name for logins that belong to a contributor like the rankings
+// they're shown with. A contributor with the same badge on several logins
+// has it once.
+func GroupBadges(achievements []*Achievement, byLogin map[string]*Contributor) map[string][]*BadgeDefinition {
+	badges := make(map[string][]*BadgeDefinition)
+	seen := make(map[string]bool)
+
+	for _, achievement := range achievements {
+		name := achievement.Username
+		if contributor, ok := byLogin[strings.ToLower(name)]; ok {
+			name = contributor.Name
+		}
+
+		if key := name + ":" + achievement.BadgeId; !seen[key] {
+			seen[key] = true
+			badges[name] = append(badges[name], achievement.Badge)
+		}
+	}
+
+	return badges
+}
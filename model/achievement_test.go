@@ -0,0 +1,80 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestEvaluateAchievements(t *testing.T) {
+	week := int64(7 * MILLIS_PER_DAY)
+	start := int64(1000) * week
+
+	history := []*LedgerEntry{}
+	for i := int64(0); i < 5; i++ {
+		repository := "mattermost/platform"
+		if i >= 3 {
+			repository = "mattermost/docs"
+		}
+
+		history = append(history, &LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_PULL_REQUEST_MERGED, Repository: repository, CreateAt: start + i*week})
+	}
+
+	history = append(history, &LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_REVIEW, Repository: "mattermost/platform", CreateAt: start + 2*week + 1})
+
+	earned := EvaluateAchievements(DefaultBadgeDefinitions(), history)
+
+	if earned["first_pull_request"] != start {
+		t.Fatal("should have earned first pull request badge with the first pull request")
+	}
+
+	if earned["first_review"] != start+2*week+1 {
+		t.Fatal("should have earned first review badge")
+	}
+
+	if earned["five_week_streak"] != start+4*week {
+		t.Fatal("should have earned streak badge in the fifth week")
+	}
+
+	if _, ok := earned["ten_pull_requests"]; ok {
+		t.Fatal("should not have earned ten pull requests badge")
+	}
+
+	if _, ok := earned["three_repositories"]; ok {
+		t.Fatal("should not have earned repositories badge")
+	}
+
+	first := &LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start}
+	revert := &LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_REVERT, RelatedId: first.Id, CreateAt: start + 1}
+	second := &LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start + 2}
+
+	definitions := []*BadgeDefinition{{Id: "two", Rule: BADGE_RULE_MERGED_PULL_REQUESTS, Threshold: 2}}
+	if _, ok := EvaluateAchievements(definitions, []*LedgerEntry{second, revert, first})["two"]; ok {
+		t.Fatal("reverted pull requests should not count")
+	}
+}
+
+func TestGroupBadges(t *testing.T) {
+	first := &BadgeDefinition{Id: "first", Name: "First"}
+	second := &BadgeDefinition{Id: "second", Name: "Second"}
+
+	byLogin := map[string]*Contributor{
+		"jwilander":      {Id: "c1", Name: "Joram"},
+		"jwilander-work": {Id: "c1", Name: "Joram"},
+	}
+
+	achievements := []*Achievement{
+		{Username: "JWilander", BadgeId: first.Id, Badge: first},
+		{Username: "jwilander-work", BadgeId: first.Id, Badge: first},
+		{Username: "jwilander-work", BadgeId: second.Id, Badge: second},
+		{Username: "crspeller", BadgeId: first.Id, Badge: first},
+	}
+
+	badges := GroupBadges(achievements, byLogin)
+
+	if len(badges) != 2 || len(badges["Joram"]) != 2 || badges["Joram"][0] != first || badges["Joram"][1] != second {
+		t.Fatal("the contributor's logins should share their badges once", badges)
+	}
+
+	if len(badges["crspeller"]) != 1 {
+		t.Fatal("logins without a contributor should keep their badges", badges)
+	}
+}
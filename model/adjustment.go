@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	ADJUSTMENT_REASON_MAX_LENGTH = 512
+)
+
+// Adjustment is an admin's request to grant or, with negative points, deduct
+// points by hand. A reason is always required so the history explains it.
+type Adjustment struct {
+	Username string `json:"username"`
+	Points   int    `json:"points"`
+	Reason   string `json:"reason"`
+}
+
+func (a *Adjustment) IsValid() error {
+	if len(a.Username) == 0 || len(a.Username) > 128 {
+		return errors.New("Invalid adjustment username")
+	}
+
+	if a.Points == 0 {
+		return errors.New("Invalid adjustment points, must not be zero")
+	}
+
+	if len(strings.TrimSpace(a.Reason)) == 0 || len(a.Reason) > ADJUSTMENT_REASON_MAX_LENGTH {
+		return errors.New("Invalid adjustment reason, a reason is required")
+	}
+
+	return nil
+}
+
+// ToLedgerEntry returns the ledger entry that applies the adjustment
+func (a *Adjustment) ToLedgerEntry(leaderboardId string) *LedgerEntry {
+	return &LedgerEntry{
+		LeaderboardId: leaderboardId,
+		Username:      a.Username,
+		Type:          LEDGER_TYPE_ADJUSTMENT,
+		Points:        a.Points,
+		Reason:        strings.TrimSpace(a.Reason),
+	}
+}
+
+func (a *Adjustment) ToJson() string {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AdjustmentFromJson(data io.Reader) *Adjustment {
+	decoder := json.NewDecoder(data)
+	var o Adjustment
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// ReverseAdjustment returns the ledger entry undoing the adjustment entry, or an error
+// if entry isn't an adjustment or has already been reversed by one of related.
+func ReverseAdjustment(entry *LedgerEntry, related []*LedgerEntry, reason string) (*LedgerEntry, error) {
+	if entry.Type != LEDGER_TYPE_ADJUSTMENT {
+		return nil, errors.New("Only adjustments can be reversed, id=" + entry.Id)
+	}
+
+	for _, r := range related {
+		if r.Type == LEDGER_TYPE_ADJUSTMENT_REVERSAL {
+			return nil, errors.New("Adjustment was already reversed, id=" + entry.Id)
+		}
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) == 0 || len(reason) > ADJUSTMENT_REASON_MAX_LENGTH {
+		return nil, errors.New("Invalid reversal reason, a reason is required")
+	}
+
+	return &LedgerEntry{
+		LeaderboardId: entry.LeaderboardId,
+		Username:      entry.Username,
+		Type:          LEDGER_TYPE_ADJUSTMENT_REVERSAL,
+		Points:        -entry.Points,
+		Reason:        reason,
+		RelatedId:     entry.Id,
+	}, nil
+}
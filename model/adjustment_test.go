@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdjustmentIsValid(t *testing.T) {
+	a := &Adjustment{Username: "jwilander", Points: 5, Reason: "hackathon winner"}
+	if err := a.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Points = 0
+	if err := a.IsValid(); err == nil {
+		t.Fatal("should be invalid without points")
+	}
+
+	a.Points = -3
+	a.Reason = "  "
+	if err := a.IsValid(); err == nil {
+		t.Fatal("should be invalid without a reason")
+	}
+
+	a.Reason = strings.Repeat("a", ADJUSTMENT_REASON_MAX_LENGTH+1)
+	if err := a.IsValid(); err == nil {
+		t.Fatal("should be invalid with a long reason")
+	}
+
+	a.Reason = "scoring mistake"
+	a.Username = ""
+	if err := a.IsValid(); err == nil {
+		t.Fatal("should be invalid without a username")
+	}
+}
+
+func TestReverseAdjustment(t *testing.T) {
+	entry := (&Adjustment{Username: "jwilander", Points: 5, Reason: "hackathon winner"}).ToLedgerEntry("lb")
+	entry.Id = NewId()
+
+	reversal, err := ReverseAdjustment(entry, nil, "wrong user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reversal.Points != -5 || reversal.RelatedId != entry.Id || reversal.Type != LEDGER_TYPE_ADJUSTMENT_REVERSAL || reversal.Username != "jwilander" {
+		t.Fatal("reversal should undo the adjustment")
+	}
+
+	if _, err := ReverseAdjustment(entry, []*LedgerEntry{reversal}, "again"); err == nil {
+		t.Fatal("should not reverse an adjustment twice")
+	}
+
+	if _, err := ReverseAdjustment(entry, nil, ""); err == nil {
+		t.Fatal("should require a reason")
+	}
+
+	if _, err := ReverseAdjustment(&LedgerEntry{Id: NewId(), Type: LEDGER_TYPE_PULL_REQUEST_MERGED, Points: 1}, nil, "reason"); err == nil {
+		t.Fatal("should only reverse adjustments")
+	}
+}
@@ -0,0 +1,231 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	ARCHIVE_DATE_FORMAT = "2006-01-02"
+
+	DEFAULT_ARCHIVE_PER_PAGE = 50
+	MAX_ARCHIVE_PER_PAGE     = 200
+)
+
+// ARCHIVE_HIDDEN_HEADERS aren't archived, since they may hold credentials
+var ARCHIVE_HIDDEN_HEADERS = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// ArchivedDelivery is a raw GitHub webhook delivery, kept so that past
+// events can be searched and replayed
+type ArchivedDelivery struct {
+	Id         string    `json:"id"`
+	DeliveryId string    `json:"delivery_id"`
+	EventType  string    `json:"event_type"`
+	Action     string    `json:"action"`
+	Username   string    `json:"username"`
+	Repository string    `json:"repository"`
+	Headers    StringMap `json:"headers"`
+	Payload    string    `json:"payload"`
+	CreateAt   int64     `json:"create_at"`
+}
+
+// NewArchivedDelivery archives a delivery with the request's headers and
+// the event parsed from its body
+func NewArchivedDelivery(header http.Header, event *Event, payload []byte) *ArchivedDelivery {
+	headers := StringMap{}
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+
+	for _, name := range ARCHIVE_HIDDEN_HEADERS {
+		delete(headers, name)
+	}
+
+	eventType := header.Get("X-GitHub-Event")
+
+	return &ArchivedDelivery{
+		DeliveryId: header.Get("X-GitHub-Delivery"),
+		EventType:  eventType,
+		Action:     event.Action,
+		Username:   event.PartitionKey(eventType),
+		Repository: event.Repository.FullName,
+		Headers:    headers,
+		Payload:    string(payload),
+	}
+}
+
+func (o *ArchivedDelivery) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
+
+func (o *ArchivedDelivery) CreateTime() time.Time {
+	return time.Unix(0, o.CreateAt*int64(time.Millisecond))
+}
+
+func (o *ArchivedDelivery) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ArchivedDeliveryListToJson(l []*ArchivedDelivery) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// DeliverySearch filters archived deliveries. Since and Until are times in
+// milliseconds, zero for no limit.
+type DeliverySearch struct {
+	EventType string `json:"event_type"`
+	Username  string `json:"username"`
+	Since     int64  `json:"since"`
+	Until     int64  `json:"until"`
+	Page      int    `json:"page"`
+	PerPage   int    `json:"per_page"`
+}
+
+func parseArchiveDate(value string, endOfDay bool) (int64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	t, err := time.ParseInLocation(ARCHIVE_DATE_FORMAT, value, time.Local)
+	if err != nil {
+		return 0, errors.New("Invalid date, expected YYYY-MM-DD, date=" + value)
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+		return t.UnixNano()/int64(time.Millisecond) - 1, nil
+	}
+
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
+
+// DeliverySearchFromQuery reads a search from the event_type, username,
+// since, until, page and per_page query parameters. Dates are YYYY-MM-DD,
+// and until includes the whole day.
+func DeliverySearchFromQuery(query url.Values) (*DeliverySearch, error) {
+	search := &DeliverySearch{
+		EventType: query.Get("event_type"),
+		Username:  query.Get("username"),
+		PerPage:   DEFAULT_ARCHIVE_PER_PAGE,
+	}
+
+	var err error
+	if search.Since, err = parseArchiveDate(query.Get("since"), false); err != nil {
+		return nil, err
+	}
+
+	if search.Until, err = parseArchiveDate(query.Get("until"), true); err != nil {
+		return nil, err
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
+		search.Page = page
+	}
+
+	if perPage, err := strconv.Atoi(query.Get("per_page")); err == nil && perPage > 0 {
+		search.PerPage = perPage
+	}
+
+	if search.PerPage > MAX_ARCHIVE_PER_PAGE {
+		search.PerPage = MAX_ARCHIVE_PER_PAGE
+	}
+
+	return search, nil
+}
+
+// ReplayRequest selects archived deliveries to replay, either by id or by
+// search. Without Commit the replay is a dry run.
+type ReplayRequest struct {
+	Ids       []string `json:"ids"`
+	EventType string   `json:"event_type"`
+	Username  string   `json:"username"`
+	Since     string   `json:"since"`
+	Until     string   `json:"until"`
+	Commit    bool     `json:"commit"`
+}
+
+func ReplayRequestFromJson(data io.Reader) *ReplayRequest {
+	decoder := json.NewDecoder(data)
+	var o ReplayRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// ToSearch returns the search selecting the request's deliveries, when they
+// aren't selected by id
+func (o *ReplayRequest) ToSearch(limit int) (*DeliverySearch, error) {
+	search := &DeliverySearch{
+		EventType: o.EventType,
+		Username:  o.Username,
+		PerPage:   limit,
+	}
+
+	var err error
+	if search.Since, err = parseArchiveDate(o.Since, false); err != nil {
+		return nil, err
+	}
+
+	if search.Until, err = parseArchiveDate(o.Until, true); err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// ReplayResult lists the ledger entries replaying a delivery awarded, or
+// would award in a dry run, and the ones skipped since they're already in
+// the ledger
+type ReplayResult struct {
+	ArchiveId string         `json:"archive_id,omitempty"`
+	EventType string         `json:"event_type"`
+	Awarded   []*LedgerEntry `json:"awarded"`
+	Skipped   []*LedgerEntry `json:"skipped"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type ReplayReport struct {
+	Commit  bool            `json:"commit"`
+	Points  int             `json:"points"`
+	Results []*ReplayResult `json:"results"`
+}
+
+func (o *ReplayReport) Add(result *ReplayResult) {
+	o.Results = append(o.Results, result)
+	for _, entry := range result.Awarded {
+		o.Points += entry.Points
+	}
+}
+
+func (o *ReplayReport) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewArchivedDelivery(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", EVENT_TYPE_PULL_REQUEST_REVIEW)
+	header.Set("X-GitHub-Delivery", "abc")
+	header.Set("Authorization", "Bearer secret")
+
+	event := &Event{
+		Action:      "submitted",
+		Review:      EventReview{User: EventUser{Login: "Reviewer"}},
+		PullRequest: EventPullRequest{User: EventUser{Login: "author"}},
+		Repository:  EventRepository{FullName: "org/repo"},
+	}
+
+	o := NewArchivedDelivery(header, event, []byte("{}"))
+	o.PreSave()
+
+	if o.DeliveryId != "abc" || o.EventType != EVENT_TYPE_PULL_REQUEST_REVIEW || o.Action != "submitted" || o.Username != "reviewer" || o.Repository != "org/repo" || o.Payload != "{}" {
+		t.Fatal("should archive the delivery")
+	}
+
+	if o.Headers["X-Github-Delivery"] != "abc" {
+		t.Fatal("should keep the headers")
+	}
+
+	if _, ok := o.Headers["Authorization"]; ok {
+		t.Fatal("should not keep credentials")
+	}
+}
+
+func TestDeliverySearchFromQuery(t *testing.T) {
+	search, err := DeliverySearchFromQuery(url.Values{
+		"event_type": {"issues"},
+		"since":      {"2024-01-02"},
+		"until":      {"2024-01-02"},
+		"page":       {"2"},
+		"per_page":   {"1000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local).UnixNano() / int64(time.Millisecond)
+	if search.EventType != "issues" || search.Since != start || search.Until != start+MILLIS_PER_DAY-1 {
+		t.Fatal("should cover the whole day")
+	}
+
+	if search.Page != 2 || search.PerPage != MAX_ARCHIVE_PER_PAGE {
+		t.Fatal("bad paging")
+	}
+
+	if _, err := DeliverySearchFromQuery(url.Values{"since": {"yesterday"}}); err == nil {
+		t.Fatal("should reject bad dates")
+	}
+
+	if search, _ := DeliverySearchFromQuery(url.Values{}); search.Since != 0 || search.Until != 0 || search.PerPage != DEFAULT_ARCHIVE_PER_PAGE {
+		t.Fatal("should not filter by default")
+	}
+}
+
+func TestReplayReport(t *testing.T) {
+	report := &ReplayReport{}
+	report.Add(&ReplayResult{Awarded: []*LedgerEntry{{Points: 2}, {Points: -1}}, Skipped: []*LedgerEntry{{Points: 5}}})
+	report.Add(&ReplayResult{Awarded: []*LedgerEntry{{Points: 3}}})
+
+	if report.Points != 4 || len(report.Results) != 2 {
+		t.Fatal("should total the points awarded")
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AUDIT_ACTION_ADJUST_POINTS      = "adjust_points"
+	AUDIT_ACTION_REVERSE_ADJUSTMENT = "reverse_adjustment"
+	AUDIT_ACTION_REPLAY_DELIVERIES  = "replay_deliveries"
+	AUDIT_ACTION_RECOMPUTE_SCORES   = "recompute_scores"
+	AUDIT_ACTION_BACKFILL           = "backfill"
+)
+
+// Audit records who changed what by hand, when and why
+type Audit struct {
+	Id            string `json:"id"`
+	Actor         string `json:"actor"`
+	Action        string `json:"action"`
+	LeaderboardId string `json:"leaderboard_id"`
+	Username      string `json:"username"`
+	Delta         int    `json:"delta"`
+	Reason        string `json:"reason"`
+	RelatedId     string `json:"related_id"`
+	CreateAt      int64  `json:"create_at"`
+}
+
+func (a *Audit) PreSave() {
+	if a.Id == "" {
+		a.Id = NewId()
+	}
+
+	a.CreateAt = GetMillis()
+}
+
+func (a *Audit) CreateTime() time.Time {
+	return time.Unix(0, a.CreateAt*int64(time.Millisecond))
+}
+
+func AuditListToJson(l []*Audit) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
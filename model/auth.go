@@ -0,0 +1,167 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+const (
+	ROLE_VIEWER    = "viewer"
+	ROLE_MODERATOR = "moderator"
+	ROLE_ADMIN     = "admin"
+
+	SESSION_COOKIE = "LBSESSION"
+)
+
+var roleLevels = map[string]int{
+	ROLE_VIEWER:    1,
+	ROLE_MODERATOR: 2,
+	ROLE_ADMIN:     3,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// RoleAllows returns true if someone with role may do what required needs.
+// Moderators can do everything viewers can and admins everything moderators can.
+func RoleAllows(role string, required string) bool {
+	return roleLevels[role] > 0 && roleLevels[role] >= roleLevels[required]
+}
+
+// Identity is whoever made a request, as established by a token or session
+type Identity struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (i *Identity) ToJson() string {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// NewSecret returns a random hex encoded secret, used for tokens and sessions
+func NewSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// HashSecret returns the hash of a token or session secret. Only hashes are
+// stored so that a leaked database doesn't leak working credentials.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewCsrfToken returns the token forms must carry when posted with the given
+// session or basic auth secret. It can't be worked out without the secret,
+// which a cross-site request can't read.
+func NewCsrfToken(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("csrf"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ApiToken authenticates API requests with the token's role. The token itself
+// is only returned once, when it's created.
+type ApiToken struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+	Hash       string `json:"-"`
+	Token      string `json:"token,omitempty" db:"-"`
+	CreateAt   int64  `json:"create_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+}
+
+func (t *ApiToken) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	if t.Hash == "" {
+		t.Token = NewSecret()
+		t.Hash = HashSecret(t.Token)
+	}
+
+	t.CreateAt = GetMillis()
+}
+
+func (t *ApiToken) IsValid() error {
+	if len(t.Name) == 0 || len(t.Name) > 64 {
+		return errors.New("Invalid token name")
+	}
+
+	if !IsValidRole(t.Role) {
+		return errors.New("Invalid token role, role=" + t.Role)
+	}
+
+	return nil
+}
+
+func (t *ApiToken) ToJson() string {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ApiTokenFromJson(data io.Reader) *ApiToken {
+	decoder := json.NewDecoder(data)
+	var o ApiToken
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ApiTokenListToJson(l []*ApiToken) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// Session is a logged in browser. Like API tokens it's stored by the hash of
+// the secret kept in the session cookie.
+type Session struct {
+	Hash     string `json:"-"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Token    string `json:"-" db:"-"`
+	CreateAt int64  `json:"create_at"`
+	ExpireAt int64  `json:"expire_at"`
+}
+
+func (s *Session) PreSave() {
+	if s.Hash == "" {
+		s.Token = NewSecret()
+		s.Hash = HashSecret(s.Token)
+	}
+
+	s.CreateAt = GetMillis()
+}
+
+func (s *Session) IsExpired() bool {
+	return s.ExpireAt > 0 && GetMillis() > s.ExpireAt
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleAllows(ROLE_ADMIN, ROLE_MODERATOR) || !RoleAllows(ROLE_MODERATOR, ROLE_VIEWER) || !RoleAllows(ROLE_VIEWER, ROLE_VIEWER) {
+		t.Fatal("higher roles should be allowed lower role actions")
+	}
+
+	if RoleAllows(ROLE_VIEWER, ROLE_MODERATOR) || RoleAllows(ROLE_MODERATOR, ROLE_ADMIN) {
+		t.Fatal("lower roles should not be allowed higher role actions")
+	}
+
+	if RoleAllows("", ROLE_VIEWER) || RoleAllows("root", ROLE_VIEWER) {
+		t.Fatal("unknown roles should not be allowed anything")
+	}
+}
+
+func TestApiTokenPreSave(t *testing.T) {
+	token := &ApiToken{Name: "ci", Role: ROLE_MODERATOR}
+	if err := token.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	token.PreSave()
+
+	if len(token.Token) == 0 || token.Hash != HashSecret(token.Token) {
+		t.Fatal("token should be generated and stored hashed")
+	}
+
+	if token.Hash == token.Token {
+		t.Fatal("hash should not be the token")
+	}
+
+	if (&ApiToken{Name: "ci", Role: "root"}).IsValid() == nil {
+		t.Fatal("should be invalid with an unknown role")
+	}
+}
+
+func TestNewCsrfToken(t *testing.T) {
+	token := NewCsrfToken("secret")
+
+	if len(token) != 64 {
+		t.Fatal("token should be a hex encoded sha256 hmac", token)
+	}
+
+	if NewCsrfToken("secret") != token {
+		t.Fatal("token should be the same for the same secret")
+	}
+
+	if NewCsrfToken("other") == token || token == HashSecret("secret") {
+		t.Fatal("token should only be derivable with the secret")
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// BackfillEvent is a historical contribution converted into the webhook
+// event GitHub would have sent for it, along with when it happened
+type BackfillEvent struct {
+	Type     string
+	Event    *Event
+	CreateAt int64
+}
+
+func timeToMillis(t *time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// PullRequestEvents converts a pull request and its reviews into the events
+// they would have sent. Only merged pull requests and submitted reviews
+// are converted.
+func PullRequestEvents(repository string, pr *EventPullRequest, reviews []*EventReview) []*BackfillEvent {
+	events := []*BackfillEvent{}
+
+	if pr.MergedAt != nil {
+		merged := *pr
+		merged.Merged = true
+
+		events = append(events, &BackfillEvent{
+			Type: EVENT_TYPE_PULL_REQUEST,
+			Event: &Event{
+				Action:      "closed",
+				PullRequest: merged,
+				Repository:  EventRepository{FullName: repository},
+			},
+			CreateAt: timeToMillis(pr.MergedAt),
+		})
+	}
+
+	for _, review := range reviews {
+		if review.SubmittedAt == nil {
+			continue
+		}
+
+		events = append(events, &BackfillEvent{
+			Type: EVENT_TYPE_PULL_REQUEST_REVIEW,
+			Event: &Event{
+				Action:      "submitted",
+				PullRequest: *pr,
+				Review:      *review,
+				Repository:  EventRepository{FullName: repository},
+			},
+			CreateAt: timeToMillis(review.SubmittedAt),
+		})
+	}
+
+	return events
+}
+
+// IssueEvent converts an issue into the event opening it would have sent,
+// returning nil for pull requests listed as issues
+func IssueEvent(repository string, issue *EventIssue) *BackfillEvent {
+	if issue.PullRequest != nil || issue.CreatedAt == nil {
+		return nil
+	}
+
+	return &BackfillEvent{
+		Type: EVENT_TYPE_ISSUES,
+		Event: &Event{
+			Action:     "opened",
+			Issue:      *issue,
+			Repository: EventRepository{FullName: repository},
+		},
+		CreateAt: timeToMillis(issue.CreatedAt),
+	}
+}
+
+// SortBackfillEvents sorts events oldest first, so they are scored in the
+// order they happened
+func SortBackfillEvents(events []*BackfillEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreateAt < events[j].CreateAt
+	})
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullRequestEvents(t *testing.T) {
+	merged := time.Date(2015, 3, 1, 12, 0, 0, 0, time.UTC)
+	submitted := time.Date(2015, 2, 28, 12, 0, 0, 0, time.UTC)
+
+	pr := &EventPullRequest{Number: 7, HtmlUrl: "https://github.com/o/r/pull/7", User: EventUser{Login: "author"}, MergedAt: &merged}
+	reviews := []*EventReview{
+		{HtmlUrl: "https://github.com/o/r/pull/7#review", User: EventUser{Login: "reviewer"}, SubmittedAt: &submitted},
+		{User: EventUser{Login: "pending"}},
+	}
+
+	events := PullRequestEvents("o/r", pr, reviews)
+	if len(events) != 2 {
+		t.Fatal("should convert the merge and the submitted review")
+	}
+
+	if events[0].Type != EVENT_TYPE_PULL_REQUEST || events[0].Event.Action != "closed" || !events[0].Event.PullRequest.Merged {
+		t.Fatal("bad merge event")
+	}
+
+	if events[0].CreateAt != merged.Unix()*1000 || events[0].Event.Repository.FullName != "o/r" {
+		t.Fatal("bad merge time or repository")
+	}
+
+	if events[1].Type != EVENT_TYPE_PULL_REQUEST_REVIEW || events[1].Event.Review.User.Login != "reviewer" || events[1].Event.PullRequest.User.Login != "author" {
+		t.Fatal("bad review event")
+	}
+
+	SortBackfillEvents(events)
+	if events[0].Type != EVENT_TYPE_PULL_REQUEST_REVIEW {
+		t.Fatal("the review happened first")
+	}
+
+	pr.MergedAt = nil
+	if events := PullRequestEvents("o/r", pr, nil); len(events) != 0 {
+		t.Fatal("unmerged pull requests shouldn't be converted")
+	}
+}
+
+func TestIssueEvent(t *testing.T) {
+	created := time.Date(2015, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	issue := &EventIssue{Number: 3, User: EventUser{Login: "reporter"}, CreatedAt: &created}
+	if event := IssueEvent("o/r", issue); event == nil || event.Type != EVENT_TYPE_ISSUES || event.Event.Action != "opened" || event.CreateAt != created.Unix()*1000 {
+		t.Fatal("bad issue event")
+	}
+
+	issue.PullRequest = &EventIssuePullRequest{Url: "https://api.github.com/repos/o/r/pulls/3"}
+	if IssueEvent("o/r", issue) != nil {
+		t.Fatal("pull requests listed as issues should be skipped")
+	}
+}
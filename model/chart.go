@@ -0,0 +1,60 @@
+package model
+
+import (
+	"sort"
+)
+
+// CumulativePoints returns each user's running total of points at the end of
+// each of the given days, which are the starts of UTC days in ascending order
+func CumulativePoints(timeline []*LedgerEntry, usernames []string, days []int64) map[string][]float64 {
+	sorted := make([]*LedgerEntry, len(timeline))
+	copy(sorted, timeline)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreateAt < sorted[j].CreateAt
+	})
+
+	totals := make(map[string]float64, len(usernames))
+	values := make(map[string][]float64, len(usernames))
+	for _, username := range usernames {
+		totals[username] = 0
+		values[username] = make([]float64, len(days))
+	}
+
+	next := 0
+	for i, day := range days {
+		for ; next < len(sorted) && sorted[next].CreateAt < day+MILLIS_PER_DAY; next++ {
+			if _, ok := totals[sorted[next].Username]; ok {
+				totals[sorted[next].Username] += float64(sorted[next].Points)
+			}
+		}
+
+		for _, username := range usernames {
+			values[username][i] = totals[username]
+		}
+	}
+
+	return values
+}
+
+// WeekStart returns the start of the week numbered by PeriodIndex
+func WeekStart(week int64) int64 {
+	return (week*7 - 3) * MILLIS_PER_DAY
+}
+
+// ContributionsPerWeek counts the contributions in each of the given number
+// of weeks, starting with the week numbered by PeriodIndex
+func ContributionsPerWeek(timeline []*LedgerEntry, firstWeek int64, weeks int) []float64 {
+	counts := make([]float64, weeks)
+
+	for _, entry := range timeline {
+		if !entry.IsContribution() {
+			continue
+		}
+
+		if i := PeriodIndex(entry.CreateAt, STREAK_PERIOD_WEEK) - firstWeek; i >= 0 && i < int64(weeks) {
+			counts[i]++
+		}
+	}
+
+	return counts
+}
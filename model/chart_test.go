@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestCumulativePoints(t *testing.T) {
+	start := int64(20000) * MILLIS_PER_DAY
+	days := []int64{start, start + MILLIS_PER_DAY, start + 2*MILLIS_PER_DAY}
+
+	timeline := []*LedgerEntry{
+		{Username: "a", Points: 2, CreateAt: start - 5*MILLIS_PER_DAY},
+		{Username: "b", Points: 1, CreateAt: start + MILLIS_PER_DAY + 5},
+		{Username: "a", Points: 1, CreateAt: start + 10},
+		{Username: "c", Points: 9, CreateAt: start + 10},
+		{Username: "a", Points: -1, CreateAt: start + 2*MILLIS_PER_DAY},
+	}
+
+	values := CumulativePoints(timeline, []string{"a", "b"}, days)
+
+	expected := map[string][]float64{"a": {3, 3, 2}, "b": {0, 1, 1}}
+	for username, series := range expected {
+		for i, v := range series {
+			if values[username][i] != v {
+				t.Fatalf("%v should have %v points on day %v, got %v", username, v, i, values[username][i])
+			}
+		}
+	}
+
+	if _, ok := values["c"]; ok {
+		t.Fatal("should only include the given users")
+	}
+}
+
+func TestContributionsPerWeek(t *testing.T) {
+	week := PeriodIndex(int64(20000)*MILLIS_PER_DAY, STREAK_PERIOD_WEEK)
+
+	if PeriodIndex(WeekStart(week), STREAK_PERIOD_WEEK) != week || PeriodIndex(WeekStart(week)-1, STREAK_PERIOD_WEEK) != week-1 {
+		t.Fatal("week start should begin the week")
+	}
+
+	timeline := []*LedgerEntry{
+		{Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: WeekStart(week)},
+		{Type: LEDGER_TYPE_REVIEW, CreateAt: WeekStart(week) + 5},
+		{Type: LEDGER_TYPE_STREAK_BONUS, CreateAt: WeekStart(week) + 5},
+		{Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: WeekStart(week + 2)},
+		{Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: WeekStart(week + 3)},
+		{Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: WeekStart(week) - 1},
+	}
+
+	counts := ContributionsPerWeek(timeline, week, 3)
+
+	if len(counts) != 3 || counts[0] != 2 || counts[1] != 0 || counts[2] != 1 {
+		t.Fatal("bad weekly contribution counts")
+	}
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	PROVIDER_GITHUB = "github"
+	PROVIDER_GITLAB = "gitlab"
+)
+
+// Contributor is a person who may contribute through several accounts. The
+// contributor's Name is shown on the leaderboard in place of the logins of
+// their linked accounts.
+type Contributor struct {
+	Id       string                `json:"id"`
+	Name     string                `json:"name"`
+	Emails   StringArray           `json:"emails"`
+	CreateAt int64                 `json:"create_at"`
+	UpdateAt int64                 `json:"update_at"`
+	Accounts []*ContributorAccount `json:"accounts" db:"-"`
+}
+
+type ContributorAccount struct {
+	ContributorId string `json:"contributor_id"`
+	Provider      string `json:"provider"`
+	Login         string `json:"login"`
+	CreateAt      int64  `json:"create_at"`
+}
+
+func (c *Contributor) PreSave() {
+	if c.Id == "" {
+		c.Id = NewId()
+	}
+
+	if c.Emails == nil {
+		c.Emails = StringArray{}
+	}
+
+	for i, email := range c.Emails {
+		c.Emails[i] = strings.ToLower(strings.TrimSpace(email))
+	}
+
+	c.CreateAt = GetMillis()
+	c.UpdateAt = c.CreateAt
+}
+
+func (c *Contributor) PreUpdate() {
+	for i, email := range c.Emails {
+		c.Emails[i] = strings.ToLower(strings.TrimSpace(email))
+	}
+
+	c.UpdateAt = GetMillis()
+}
+
+func (c *Contributor) IsValid() error {
+	if len(c.Name) == 0 || len(c.Name) > 128 {
+		return errors.New("Invalid contributor name")
+	}
+
+	return nil
+}
+
+func (c *Contributor) ToJson() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ContributorFromJson(data io.Reader) *Contributor {
+	decoder := json.NewDecoder(data)
+	var o Contributor
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ContributorListToJson(l []*Contributor) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func (a *ContributorAccount) PreSave() {
+	if a.Provider == "" {
+		a.Provider = PROVIDER_GITHUB
+	}
+
+	a.CreateAt = GetMillis()
+}
+
+func (a *ContributorAccount) IsValid() error {
+	if len(a.ContributorId) != 26 {
+		return errors.New("Invalid contributor id")
+	}
+
+	if a.Provider != PROVIDER_GITHUB && a.Provider != PROVIDER_GITLAB {
+		return errors.New("Invalid account provider, provider=" + a.Provider)
+	}
+
+	if len(a.Login) == 0 || len(a.Login) > 128 {
+		return errors.New("Invalid account login")
+	}
+
+	return nil
+}
+
+func ContributorAccountFromJson(data io.Reader) *ContributorAccount {
+	decoder := json.NewDecoder(data)
+	var o ContributorAccount
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// ContributorsByLogin maps each linked account's login to its contributor.
+// Logins are assumed to be unique across providers.
+func ContributorsByLogin(contributors []*Contributor, accounts []*ContributorAccount) map[string]*Contributor {
+	byId := make(map[string]*Contributor, len(contributors))
+	for _, contributor := range contributors {
+		byId[contributor.Id] = contributor
+	}
+
+	byLogin := make(map[string]*Contributor, len(accounts))
+	for _, account := range accounts {
+		if contributor, ok := byId[account.ContributorId]; ok {
+			byLogin[strings.ToLower(account.Login)] = contributor
+		}
+	}
+
+	return byLogin
+}
+
+// AggregateRankings combines the entries of logins that belong to the same
+// contributor into one entry under the contributor's name, keeping the
+// rankings sorted by points.
+func AggregateRankings(entries []*LeaderboardEntry, byLogin map[string]*Contributor) []*LeaderboardEntry {
+	aggregated := make([]*LeaderboardEntry, 0, len(entries))
+	byContributor := make(map[string]*LeaderboardEntry)
+
+	for _, entry := range entries {
+		contributor, ok := byLogin[strings.ToLower(entry.Username)]
+		if !ok {
+			aggregated = append(aggregated, entry)
+			continue
+		}
+
+		if existing, ok := byContributor[contributor.Id]; ok {
+			existing.Points += entry.Points
+			existing.ActiveScore += entry.ActiveScore
+		} else {
+			combined := *entry
+			combined.Username = contributor.Name
+			byContributor[contributor.Id] = &combined
+			aggregated = append(aggregated, &combined)
+		}
+	}
+
+	sort.SliceStable(aggregated, func(i, j int) bool {
+		return aggregated[i].Points > aggregated[j].Points
+	})
+
+	return aggregated
+}
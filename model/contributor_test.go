@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestAggregateRankings(t *testing.T) {
+	contributor := &Contributor{Name: "jwilander"}
+	contributor.PreSave()
+
+	accounts := []*ContributorAccount{
+		{ContributorId: contributor.Id, Provider: PROVIDER_GITHUB, Login: "jwilander"},
+		{ContributorId: contributor.Id, Provider: PROVIDER_GITHUB, Login: "JoramWork"},
+		{ContributorId: NewId(), Provider: PROVIDER_GITHUB, Login: "orphan"},
+	}
+
+	entries := []*LeaderboardEntry{
+		{Username: "someone", Points: 5},
+		{Username: "jwilander", Points: 3},
+		{Username: "joramwork", Points: 4},
+		{Username: "orphan", Points: 1},
+	}
+
+	rankings := AggregateRankings(entries, ContributorsByLogin([]*Contributor{contributor}, accounts))
+
+	if len(rankings) != 3 {
+		t.Fatal("should have combined linked accounts")
+	}
+
+	if rankings[0].Username != "jwilander" || rankings[0].Points != 7 {
+		t.Fatal("contributor should be ranked first with combined points")
+	}
+
+	if rankings[1].Username != "someone" || rankings[2].Username != "orphan" {
+		t.Fatal("other entries should be unchanged")
+	}
+
+	if entries[1].Points != 3 {
+		t.Fatal("should not have modified the original entries")
+	}
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	DIGEST_DELIVERY_CHAT  = "chat"
+	DIGEST_DELIVERY_EMAIL = "email"
+	DIGEST_DELIVERY_FILE  = "file"
+
+	DIGEST_DAYS = 7
+)
+
+// DigestDelivery sends the digest of one leaderboard, or of every leaderboard
+// if Leaderboard is empty, to a chat webhook, to email addresses or to a
+// Markdown file in a directory
+type DigestDelivery struct {
+	Leaderboard string   `json:"leaderboard"`
+	Type        string   `json:"type"`
+	Url         string   `json:"url"`
+	Channel     string   `json:"channel"`
+	Username    string   `json:"username"`
+	To          []string `json:"to"`
+	Directory   string   `json:"directory"`
+}
+
+func (o *DigestDelivery) Matches(leaderboardName string) bool {
+	return len(o.Leaderboard) == 0 || o.Leaderboard == leaderboardName
+}
+
+// Digest sums up a week on a leaderboard
+type Digest struct {
+	LeaderboardName string              `json:"leaderboard_name"`
+	StartAt         int64               `json:"start_at"`
+	EndAt           int64               `json:"end_at"`
+	Top             []*LeaderboardEntry `json:"top"`
+	Newcomers       []*LeaderboardEntry `json:"newcomers"`
+	Movers          []*RankMovement     `json:"movers"`
+	Badges          []*Achievement      `json:"badges"`
+}
+
+// BiggestMovers returns up to limit users who moved the most places, up or
+// down. Users who weren't ranked before aren't movers.
+func BiggestMovers(movements map[string]*RankMovement, limit int) []*RankMovement {
+	movers := []*RankMovement{}
+	for _, movement := range movements {
+		if !movement.New && movement.Change != 0 {
+			movers = append(movers, movement)
+		}
+	}
+
+	sort.Slice(movers, func(i, j int) bool {
+		if movers[i].Distance() != movers[j].Distance() {
+			return movers[i].Distance() > movers[j].Distance()
+		}
+		return movers[i].Username < movers[j].Username
+	})
+
+	if len(movers) > limit {
+		movers = movers[:limit]
+	}
+
+	return movers
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+// Period describes the days the digest covers, like "Jan 2 - Jan 9, 2006"
+func (d *Digest) Period() string {
+	return millisToTime(d.StartAt).Format("Jan 2") + " - " + millisToTime(d.EndAt).Format("Jan 2, 2006")
+}
+
+func (d *Digest) Subject() string {
+	return "Weekly digest for " + d.LeaderboardName + ", " + d.Period()
+}
+
+func (d *Digest) ToMarkdown() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "#### %v\n\n", d.Subject())
+
+	buf.WriteString("**Top contributors**\n\n")
+	if len(d.Top) == 0 {
+		buf.WriteString("Nobody scored this week.\n")
+	}
+	for i, entry := range d.Top {
+		fmt.Fprintf(&buf, "%v. %v, %v points\n", i+1, entry.Username, entry.Points)
+	}
+
+	buf.WriteString("\n**Newcomers**\n\n")
+	if len(d.Newcomers) == 0 {
+		buf.WriteString("No newcomers this week.\n")
+	}
+	for _, entry := range d.Newcomers {
+		fmt.Fprintf(&buf, "- %v, %v points\n", entry.Username, entry.Points)
+	}
+
+	buf.WriteString("\n**Biggest movers**\n\n")
+	if len(d.Movers) == 0 {
+		buf.WriteString("No rank changes this week.\n")
+	}
+	for _, movement := range d.Movers {
+		direction := "up"
+		if movement.IsDown() {
+			direction = "down"
+		}
+		fmt.Fprintf(&buf, "- %v moved %v %v to #%v\n", movement.Username, direction, movement.Distance(), movement.Rank)
+	}
+
+	buf.WriteString("\n**Badges earned**\n\n")
+	if len(d.Badges) == 0 {
+		buf.WriteString("No badges earned this week.\n")
+	}
+	for _, achievement := range d.Badges {
+		name := achievement.BadgeId
+		if achievement.Badge != nil {
+			name = achievement.Badge.Name
+		}
+		fmt.Fprintf(&buf, "- %v earned **%v**\n", achievement.Username, name)
+	}
+
+	return buf.String()
+}
+
+func (d *Digest) ToJson() string {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
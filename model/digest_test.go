@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBiggestMovers(t *testing.T) {
+	movements := map[string]*RankMovement{
+		"a": {Username: "a", Rank: 1, PreviousRank: 4, Change: 3},
+		"b": {Username: "b", Rank: 5, PreviousRank: 1, Change: -4},
+		"c": {Username: "c", Rank: 2, PreviousRank: 2},
+		"d": {Username: "d", Rank: 3, New: true},
+		"e": {Username: "e", Rank: 4, PreviousRank: 7, Change: 3},
+	}
+
+	movers := BiggestMovers(movements, 2)
+	if len(movers) != 2 || movers[0].Username != "b" || movers[1].Username != "a" {
+		t.Fatal("should return the biggest movers in either direction")
+	}
+
+	if len(BiggestMovers(movements, 10)) != 3 {
+		t.Fatal("should leave out new and unmoved users")
+	}
+}
+
+func TestDigestToMarkdown(t *testing.T) {
+	end := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+
+	d := &Digest{
+		LeaderboardName: "TestLeaderboard",
+		StartAt:         end.AddDate(0, 0, -7).UnixNano() / int64(time.Millisecond),
+		EndAt:           end.UnixNano() / int64(time.Millisecond),
+		Top:             []*LeaderboardEntry{{Username: "a", Points: 5}, {Username: "b", Points: 3}},
+		Movers:          []*RankMovement{{Username: "b", Rank: 2, PreviousRank: 5, Change: 3}},
+		Badges:          []*Achievement{{Username: "a", BadgeId: "first_review", Badge: &BadgeDefinition{Name: "Reviewer"}}},
+	}
+
+	md := d.ToMarkdown()
+
+	for _, expected := range []string{
+		"#### Weekly digest for TestLeaderboard, Jan 8 - Jan 15, 2024\n",
+		"1. a, 5 points\n2. b, 3 points\n",
+		"No newcomers this week.\n",
+		"- b moved up 3 to #2\n",
+		"- a earned **Reviewer**\n",
+	} {
+		if !strings.Contains(md, expected) {
+			t.Fatal("digest should contain " + expected + ", got " + md)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	DOMAIN_EVENT_POINTS_AWARDED = "points.awarded"
+	DOMAIN_EVENT_RANK_CHANGED   = "rank.changed"
+	DOMAIN_EVENT_BADGE_EARNED   = "badge.earned"
+	DOMAIN_EVENT_SEASON_CLOSED  = "season.closed"
+)
+
+// DomainEvent is something that happened on a leaderboard that other parts
+// of the server, or clients, may want to react to. Ids increase with each
+// event published since the server started.
+type DomainEvent struct {
+	Id            int64                  `json:"id"`
+	Type          string                 `json:"type"`
+	LeaderboardId string                 `json:"leaderboard_id"`
+	Data          map[string]interface{} `json:"data"`
+	CreateAt      int64                  `json:"create_at"`
+}
+
+func NewPointsAwardedEvent(entry *LedgerEntry) *DomainEvent {
+	return &DomainEvent{
+		Type:          DOMAIN_EVENT_POINTS_AWARDED,
+		LeaderboardId: entry.LeaderboardId,
+		Data: map[string]interface{}{
+			"username":   entry.Username,
+			"points":     entry.Points,
+			"type":       entry.Type,
+			"repository": entry.Repository,
+			"number":     entry.Number,
+			"title":      entry.Title,
+			"url":        entry.Url,
+			"reason":     entry.Reason,
+		},
+	}
+}
+
+func NewRankChangedEvent(leaderboardId string, movement *RankMovement, points int) *DomainEvent {
+	return &DomainEvent{
+		Type:          DOMAIN_EVENT_RANK_CHANGED,
+		LeaderboardId: leaderboardId,
+		Data: map[string]interface{}{
+			"username":      movement.Username,
+			"rank":          movement.Rank,
+			"previous_rank": movement.PreviousRank,
+			"points":        points,
+		},
+	}
+}
+
+func NewBadgeEarnedEvent(achievement *Achievement, badge *BadgeDefinition) *DomainEvent {
+	return &DomainEvent{
+		Type:          DOMAIN_EVENT_BADGE_EARNED,
+		LeaderboardId: achievement.LeaderboardId,
+		Data: map[string]interface{}{
+			"username":    achievement.Username,
+			"badge_id":    badge.Id,
+			"badge_name":  badge.Name,
+			"description": badge.Description,
+		},
+	}
+}
+
+func NewSeasonClosedEvent(season *Season, winners []*SeasonStanding) *DomainEvent {
+	return &DomainEvent{
+		Type:          DOMAIN_EVENT_SEASON_CLOSED,
+		LeaderboardId: season.LeaderboardId,
+		Data: map[string]interface{}{
+			"season_id": season.Id,
+			"name":      season.Name,
+			"winners":   winners,
+		},
+	}
+}
+
+// DataString returns the named data field if it's a string
+func (e *DomainEvent) DataString(name string) string {
+	if s, ok := e.Data[name].(string); ok {
+		return s
+	}
+
+	return ""
+}
+
+func (e *DomainEvent) ToJson() string {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DomainEventFromJson(data []byte) *DomainEvent {
+	var o DomainEvent
+	if err := json.Unmarshal(data, &o); err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// RankChanges returns the movements of the users whose rank in standings
+// differs from their rank in previous, including users who weren't ranked
+func RankChanges(previous map[string]int, standings []*SeasonStanding) []*RankMovement {
+	changes := []*RankMovement{}
+
+	for _, standing := range standings {
+		if rank := previous[standing.Username]; rank != standing.Rank {
+			changes = append(changes, &RankMovement{
+				Username:     standing.Username,
+				Rank:         standing.Rank,
+				PreviousRank: rank,
+				Change:       rank - standing.Rank,
+				New:          rank == 0,
+			})
+		}
+	}
+
+	return changes
+}
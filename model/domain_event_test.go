@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestRankChanges(t *testing.T) {
+	previous := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	standings := NewSeasonStandings("", []*LeaderboardEntry{
+		{Username: "b", Points: 10},
+		{Username: "a", Points: 9},
+		{Username: "c", Points: 5},
+		{Username: "d", Points: 1},
+	})
+
+	changes := RankChanges(previous, standings)
+	if len(changes) != 3 {
+		t.Fatal("should only include users whose rank changed")
+	}
+
+	if changes[0].Username != "b" || changes[0].Rank != 1 || changes[0].PreviousRank != 2 || !changes[0].IsUp() {
+		t.Fatal("b should have moved up")
+	}
+
+	if changes[1].Username != "a" || !changes[1].IsDown() {
+		t.Fatal("a should have moved down")
+	}
+
+	if changes[2].Username != "d" || !changes[2].New {
+		t.Fatal("d should be new")
+	}
+}
+
+func TestDomainEventJson(t *testing.T) {
+	e := NewPointsAwardedEvent(&LedgerEntry{LeaderboardId: "lb", Username: "jwilander", Points: 2, Type: LEDGER_TYPE_PULL_REQUEST_MERGED})
+	e.Id = 7
+
+	e2 := DomainEventFromJson([]byte(e.ToJson()))
+	if e2 == nil || e2.Id != 7 || e2.Type != DOMAIN_EVENT_POINTS_AWARDED || e2.DataString("username") != "jwilander" || e2.LeaderboardId != "lb" {
+		t.Fatal("event should round trip through json")
+	}
+
+	if e2.DataString("points") != "" {
+		t.Fatal("non string data should not be returned as a string")
+	}
+}
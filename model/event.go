@@ -3,21 +3,91 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"time"
+)
+
+const (
+	EVENT_TYPE_PULL_REQUEST        = "pull_request"
+	EVENT_TYPE_PULL_REQUEST_REVIEW = "pull_request_review"
+	EVENT_TYPE_ISSUES              = "issues"
+
+	// EVENT_TYPE_COMMIT isn't sent by GitHub, it's used to score commits
+	// read from a git repository's history
+	EVENT_TYPE_COMMIT = "commit"
+
+	AUTHOR_ASSOCIATION_FIRST_TIMER            = "FIRST_TIMER"
+	AUTHOR_ASSOCIATION_FIRST_TIME_CONTRIBUTOR = "FIRST_TIME_CONTRIBUTOR"
 )
 
 type Event struct {
 	Action      string           `json:"action"`
 	PullRequest EventPullRequest `json:"pull_request"`
+	Review      EventReview      `json:"review"`
+	Issue       EventIssue       `json:"issue"`
+	Commit      EventCommit      `json:"commit"`
+	Repository  EventRepository  `json:"repository"`
 }
 
 type EventPullRequest struct {
-	Merged bool      `json:"merged"`
-	User   EventUser `json:"user"`
+	Number            int          `json:"number"`
+	Title             string       `json:"title"`
+	Body              string       `json:"body"`
+	HtmlUrl           string       `json:"html_url"`
+	Merged            bool         `json:"merged"`
+	MergedAt          *time.Time   `json:"merged_at"`
+	User              EventUser    `json:"user"`
+	Labels            []EventLabel `json:"labels"`
+	AuthorAssociation string       `json:"author_association"`
+}
+
+type EventReview struct {
+	Id          int        `json:"id"`
+	State       string     `json:"state"`
+	HtmlUrl     string     `json:"html_url"`
+	User        EventUser  `json:"user"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
+// EventIssue is an issue, or a pull request when PullRequest is set since
+// GitHub lists pull requests along with issues
+type EventIssue struct {
+	Number      int                    `json:"number"`
+	Title       string                 `json:"title"`
+	HtmlUrl     string                 `json:"html_url"`
+	User        EventUser              `json:"user"`
+	CreatedAt   *time.Time             `json:"created_at"`
+	PullRequest *EventIssuePullRequest `json:"pull_request,omitempty"`
+}
+
+type EventIssuePullRequest struct {
+	Url string `json:"url"`
+}
+
+type EventCommit struct {
+	Sha     string    `json:"sha"`
+	Message string    `json:"message"`
+	HtmlUrl string    `json:"html_url"`
+	User    EventUser `json:"user"`
 }
 
 type EventUser struct {
 	Id    int    `json:"id"`
 	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+type EventLabel struct {
+	Name string `json:"name"`
+}
+
+type EventRepository struct {
+	FullName string `json:"full_name"`
+}
+
+// IsFirstTimeContributor reports whether GitHub considers the pull request
+// to be its author's first contribution to the repository
+func (pr *EventPullRequest) IsFirstTimeContributor() bool {
+	return pr.AuthorAssociation == AUTHOR_ASSOCIATION_FIRST_TIMER || pr.AuthorAssociation == AUTHOR_ASSOCIATION_FIRST_TIME_CONTRIBUTOR
 }
 
 func (l *Event) ToJson() string {
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+	USER_TYPE_BOT = "Bot"
+)
+
+// IsExcluded reports whether a user should be kept off the leaderboard. The
+// deny and allow lists hold logins or glob patterns as understood by path.Match,
+// compared case-insensitively. Since the user type is only known while handling
+// an event, logins ending in "[bot]" are also treated as bots so that existing
+// entries can be hidden retroactively.
+func (s *ExclusionSettings) IsExcluded(login string, userType string) bool {
+	if s.ExcludeBots != nil && *s.ExcludeBots {
+		if userType == USER_TYPE_BOT || strings.HasSuffix(strings.ToLower(login), "[bot]") {
+			return true
+		}
+	}
+
+	if s.Mode != nil && *s.Mode == EXCLUSION_MODE_ALLOW {
+		return !matchesLogin(s.AllowList, login)
+	}
+
+	return matchesLogin(s.DenyList, login)
+}
+
+// FilterRankings returns the entries belonging to users that are not excluded
+func (s *ExclusionSettings) FilterRankings(entries []*LeaderboardEntry) []*LeaderboardEntry {
+	filtered := make([]*LeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !s.IsExcluded(entry.Username, "") {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+func matchesLogin(patterns []string, login string) bool {
+	login = strings.ToLower(login)
+
+	for _, pattern := range patterns {
+		if matched, err := path.Match(strings.ToLower(pattern), login); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestExclusionSettings(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetDefaults()
+	s := &cfg.ExclusionSettings
+
+	if !s.IsExcluded("release-helper", USER_TYPE_BOT) {
+		t.Fatal("bots should be excluded")
+	}
+
+	if !s.IsExcluded("dependabot[bot]", "") {
+		t.Fatal("bot logins should be excluded without a user type")
+	}
+
+	if s.IsExcluded("jwilander", "User") {
+		t.Fatal("users should not be excluded")
+	}
+
+	s.DenyList = []string{"renovate*", "MattermostBuild"}
+	if !s.IsExcluded("renovate-bot", "User") {
+		t.Fatal("should match glob pattern")
+	}
+
+	if !s.IsExcluded("mattermostbuild", "User") {
+		t.Fatal("should match login case-insensitively")
+	}
+
+	*s.Mode = EXCLUSION_MODE_ALLOW
+	s.AllowList = []string{"jwilander", "core-*"}
+	if s.IsExcluded("core-dev", "User") || s.IsExcluded("jwilander", "User") {
+		t.Fatal("allowed users should not be excluded")
+	}
+
+	if !s.IsExcluded("someone", "User") {
+		t.Fatal("users not on the allow list should be excluded")
+	}
+
+	entries := []*LeaderboardEntry{{Username: "jwilander"}, {Username: "someone"}, {Username: "dependabot[bot]"}}
+	if filtered := s.FilterRankings(entries); len(filtered) != 1 || filtered[0].Username != "jwilander" {
+		t.Fatal("should have filtered rankings")
+	}
+}
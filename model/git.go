@@ -0,0 +1,225 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GIT_LOG_FORMAT is the git log --format that ParseGitLog reads: the
+	// hash, parent hashes, author name, author email, author time and
+	// subject separated by unit separators, one record per commit
+	GIT_LOG_FORMAT = "%H%x1f%P%x1f%an%x1f%ae%x1f%at%x1f%s%x1e"
+)
+
+var (
+	gitNoreplyEmail = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+	gitMergeSubject = regexp.MustCompile(`^Merge pull request #(\d+)`)
+)
+
+type GitCommit struct {
+	Sha         string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	AuthorAt    int64
+	Subject     string
+}
+
+func (c *GitCommit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// ParseGitLog parses the output of git log with GIT_LOG_FORMAT
+func ParseGitLog(data string) ([]*GitCommit, error) {
+	commits := []*GitCommit{}
+
+	for _, record := range strings.Split(data, "\x1e") {
+		record = strings.TrimSpace(record)
+		if len(record) == 0 {
+			continue
+		}
+
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 6 {
+			return nil, errors.New("Unable to parse git log record, record=" + record)
+		}
+
+		seconds, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, errors.New("Unable to parse git commit time, sha=" + fields[0])
+		}
+
+		commits = append(commits, &GitCommit{
+			Sha:         fields[0],
+			Parents:     strings.Fields(fields[1]),
+			AuthorName:  fields[2],
+			AuthorEmail: fields[3],
+			AuthorAt:    seconds * 1000,
+			Subject:     fields[5],
+		})
+	}
+
+	return commits, nil
+}
+
+// GitIdentities maps commit author emails to the usernames they're credited
+// to
+type GitIdentities map[string]string
+
+// NewGitIdentities credits the emails of each contributor to its GitHub
+// login, or its name if it has none, and then applies the explicit mapping
+// from email to username
+func NewGitIdentities(contributors []*Contributor, accounts []*ContributorAccount, mapping map[string]string) GitIdentities {
+	logins := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		if _, ok := logins[account.ContributorId]; !ok && account.Provider == PROVIDER_GITHUB {
+			logins[account.ContributorId] = account.Login
+		}
+	}
+
+	identities := GitIdentities{}
+
+	for _, contributor := range contributors {
+		username, ok := logins[contributor.Id]
+		if !ok {
+			username = contributor.Name
+		}
+
+		for _, email := range contributor.Emails {
+			identities[strings.ToLower(email)] = username
+		}
+	}
+
+	for email, username := range mapping {
+		identities[strings.ToLower(strings.TrimSpace(email))] = username
+	}
+
+	return identities
+}
+
+// Username returns who a commit by the author is credited to: the mapped
+// username, the login of a GitHub noreply email, or else the author's name
+func (m GitIdentities) Username(name string, email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if username, ok := m[email]; ok {
+		return username
+	}
+
+	if match := gitNoreplyEmail.FindStringSubmatch(email); match != nil {
+		return match[1]
+	}
+
+	return name
+}
+
+// GitHistory converts a git branch's commits into events. Commits link to
+// Url, the repository's web address, when it's set.
+type GitHistory struct {
+	Repository string
+	Url        string
+	MergesOnly bool
+	Identities GitIdentities
+}
+
+// Events converts the commits of the branch, read with git log in
+// topological order so the branch's tip comes first. Every commit that isn't
+// a merge is scored as a commit, unless MergesOnly is set. Then only the
+// merges into the branch itself are scored, as merged pull requests credited
+// to the author of the merged branch.
+func (h *GitHistory) Events(commits []*GitCommit) []*BackfillEvent {
+	events := []*BackfillEvent{}
+	if len(commits) == 0 {
+		return events
+	}
+
+	if !h.MergesOnly {
+		for _, commit := range commits {
+			if !commit.IsMerge() {
+				events = append(events, h.commitEvent(commit))
+			}
+		}
+
+		SortBackfillEvents(events)
+		return events
+	}
+
+	bySha := make(map[string]*GitCommit, len(commits))
+	for _, commit := range commits {
+		bySha[commit.Sha] = commit
+	}
+
+	for commit := commits[0]; commit != nil; {
+		if commit.IsMerge() {
+			if merged, ok := bySha[commit.Parents[1]]; ok {
+				events = append(events, h.mergeEvent(commit, merged))
+			}
+		}
+
+		if len(commit.Parents) == 0 {
+			break
+		}
+		commit = bySha[commit.Parents[0]]
+	}
+
+	SortBackfillEvents(events)
+	return events
+}
+
+func (h *GitHistory) commitUrl(commit *GitCommit) string {
+	if len(h.Url) == 0 {
+		return "git:" + commit.Sha
+	}
+
+	return strings.TrimRight(h.Url, "/") + "/commit/" + commit.Sha
+}
+
+func (h *GitHistory) commitEvent(commit *GitCommit) *BackfillEvent {
+	return &BackfillEvent{
+		Type: EVENT_TYPE_COMMIT,
+		Event: &Event{
+			Commit: EventCommit{
+				Sha:     commit.Sha,
+				Message: commit.Subject,
+				HtmlUrl: h.commitUrl(commit),
+				User:    EventUser{Login: h.Identities.Username(commit.AuthorName, commit.AuthorEmail)},
+			},
+			Repository: EventRepository{FullName: h.Repository},
+		},
+		CreateAt: commit.AuthorAt,
+	}
+}
+
+// mergeEvent converts a merge into a merged pull request. Merges of GitHub
+// pull requests keep their number, and link to the pull request when the
+// repository's url is set, so they match points awarded by GitHub's
+// webhook.
+func (h *GitHistory) mergeEvent(merge *GitCommit, merged *GitCommit) *BackfillEvent {
+	pr := EventPullRequest{
+		Title:   merge.Subject,
+		HtmlUrl: h.commitUrl(merge),
+		Merged:  true,
+		User:    EventUser{Login: h.Identities.Username(merged.AuthorName, merged.AuthorEmail)},
+	}
+
+	if match := gitMergeSubject.FindStringSubmatch(merge.Subject); match != nil {
+		pr.Number, _ = strconv.Atoi(match[1])
+	}
+
+	if pr.Number != 0 && len(h.Url) > 0 {
+		pr.HtmlUrl = strings.TrimRight(h.Url, "/") + "/pull/" + strconv.Itoa(pr.Number)
+	}
+
+	return &BackfillEvent{
+		Type: EVENT_TYPE_PULL_REQUEST,
+		Event: &Event{
+			Action:      "closed",
+			PullRequest: pr,
+			Repository:  EventRepository{FullName: h.Repository},
+		},
+		CreateAt: merge.AuthorAt,
+	}
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestParseGitLog(t *testing.T) {
+	log := "b2\x1fa1 c3\x1fMaintainer\x1fmaint@example.com\x1f1425211200\x1fMerge pull request #7 from author/feature\x1e\n" +
+		"a1\x1f\x1fAuthor\x1fauthor@example.com\x1f1425124800\x1fInitial commit\x1e\n"
+
+	commits, err := ParseGitLog(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commits) != 2 || !commits[0].IsMerge() || commits[1].IsMerge() {
+		t.Fatal("bad commits")
+	}
+
+	if commits[0].Parents[1] != "c3" || commits[0].AuthorAt != 1425211200000 || commits[1].Subject != "Initial commit" {
+		t.Fatal("bad commit fields")
+	}
+
+	if _, err := ParseGitLog("a1\x1fbroken\x1e"); err == nil {
+		t.Fatal("should fail to parse")
+	}
+}
+
+func TestGitIdentities(t *testing.T) {
+	contributors := []*Contributor{
+		{Id: "joram", Name: "Joram", Emails: StringArray{"joram@example.com"}},
+		{Id: "corey", Name: "Corey", Emails: StringArray{"corey@example.com"}},
+	}
+	accounts := []*ContributorAccount{
+		{ContributorId: "joram", Provider: PROVIDER_GITLAB, Login: "joram"},
+		{ContributorId: "joram", Provider: PROVIDER_GITHUB, Login: "jwilander"},
+	}
+
+	identities := NewGitIdentities(contributors, accounts, map[string]string{"Old@Example.com": "jwilander"})
+
+	if identities.Username("Joram W", "JORAM@example.com") != "jwilander" || identities.Username("J", "old@example.com") != "jwilander" {
+		t.Fatal("contributor emails should be credited to their login")
+	}
+
+	if identities.Username("Corey H", "corey@example.com") != "Corey" {
+		t.Fatal("contributors without a GitHub login should be credited by name")
+	}
+
+	if identities.Username("Someone", "12345+someone@users.noreply.github.com") != "someone" {
+		t.Fatal("noreply emails should be credited to their login")
+	}
+
+	if identities.Username("Jane Doe", "jane@example.com") != "Jane Doe" {
+		t.Fatal("unknown authors should be credited by name")
+	}
+}
+
+func TestGitHistoryEvents(t *testing.T) {
+	// m2 merges feature commit f1 into the branch, which started with a1
+	commits := []*GitCommit{
+		{Sha: "m2", Parents: []string{"a1", "f1"}, AuthorName: "Maintainer", AuthorEmail: "maint@example.com", AuthorAt: 3000, Subject: "Merge pull request #7 from author/feature"},
+		{Sha: "f1", Parents: []string{"a1"}, AuthorName: "Author", AuthorEmail: "author@example.com", AuthorAt: 2000, Subject: "Add feature"},
+		{Sha: "a1", AuthorName: "Maintainer", AuthorEmail: "maint@example.com", AuthorAt: 1000, Subject: "Initial commit"},
+	}
+
+	history := &GitHistory{Repository: "o/r", Identities: GitIdentities{}}
+
+	events := history.Events(commits)
+	if len(events) != 2 || events[0].Type != EVENT_TYPE_COMMIT || events[0].Event.Commit.Sha != "a1" || events[1].Event.Commit.User.Login != "Author" {
+		t.Fatal("should convert every commit but the merge, oldest first")
+	}
+
+	if events[0].Event.Commit.HtmlUrl != "git:a1" || events[0].CreateAt != 1000 {
+		t.Fatal("bad commit event")
+	}
+
+	history.MergesOnly = true
+	history.Url = "https://github.com/o/r/"
+
+	events = history.Events(commits)
+	if len(events) != 1 || events[0].Type != EVENT_TYPE_PULL_REQUEST || events[0].CreateAt != 3000 {
+		t.Fatal("should only convert the merge")
+	}
+
+	pr := events[0].Event.PullRequest
+	if !pr.Merged || pr.Number != 7 || pr.User.Login != "Author" || pr.HtmlUrl != "https://github.com/o/r/pull/7" {
+		t.Fatal("the merge should be credited to the merged branch's author")
+	}
+}
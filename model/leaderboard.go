@@ -4,15 +4,44 @@ import (
 	"encoding/json"
 )
 
+const (
+	LEADERBOARD_VISIBILITY_PUBLIC   = "public"
+	LEADERBOARD_VISIBILITY_UNLISTED = "unlisted"
+	LEADERBOARD_VISIBILITY_PRIVATE  = "private"
+)
+
+// Leaderboard is a set of rankings. Public leaderboards are listed and can
+// be viewed by anyone, unlisted ones can be viewed by anyone who knows their
+// name, and private ones only by their viewers or with a share link.
 type Leaderboard struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+}
+
+// LeaderboardViewer gives a user access to a private leaderboard
+type LeaderboardViewer struct {
+	LeaderboardId string `json:"leaderboard_id"`
+	Username      string `json:"username"`
+	CreateAt      int64  `json:"create_at"`
+}
+
+func IsValidVisibility(visibility string) bool {
+	return visibility == LEADERBOARD_VISIBILITY_PUBLIC || visibility == LEADERBOARD_VISIBILITY_UNLISTED || visibility == LEADERBOARD_VISIBILITY_PRIVATE
 }
 
 func (l *Leaderboard) PreSave() {
 	if l.Id == "" {
 		l.Id = NewId()
 	}
+
+	if l.Visibility == "" {
+		l.Visibility = LEADERBOARD_VISIBILITY_PUBLIC
+	}
+}
+
+func (l *Leaderboard) IsPrivate() bool {
+	return l.Visibility == LEADERBOARD_VISIBILITY_PRIVATE
 }
 
 func (l *Leaderboard) ToJson() string {
@@ -26,9 +55,27 @@ func (l *Leaderboard) ToJson() string {
 
 func LeaderboardFromJson(data string) (*Leaderboard, error) {
 	var leaderboard Leaderboard
-	if err := json.Unmarshal([]byte(data), leaderboard); err == nil {
+	if err := json.Unmarshal([]byte(data), &leaderboard); err == nil {
 		return &leaderboard, nil
 	} else {
 		return nil, err
 	}
 }
+
+func LeaderboardListToJson(l []*Leaderboard) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LeaderboardViewerListToJson(l []*LeaderboardViewer) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
@@ -2,16 +2,27 @@ package model
 
 import (
 	"encoding/json"
+	"math"
+	"sort"
 )
 
+// LeaderboardEntry holds a user's lifetime points along with their active
+// points, which decay with a half life. ActivePoints is the decayed value as
+// of ActiveAt, so the active score at any later time can be computed without
+// replaying the user's history.
 type LeaderboardEntry struct {
-	LeaderboardId string `json:"leaderboard_id"`
-	Username      string `json:"username"`
-	Points        int    `json:"points"`
+	LeaderboardId string  `json:"leaderboard_id"`
+	Username      string  `json:"username"`
+	Points        int     `json:"points"`
+	ActivePoints  float64 `json:"-"`
+	ActiveAt      int64   `json:"-"`
+	ActiveScore   float64 `json:"active_score" db:"-"`
 }
 
 func (l *LeaderboardEntry) PreSave() {
 	l.Points = 0
+	l.ActivePoints = 0
+	l.ActiveAt = 0
 }
 
 func (l *LeaderboardEntry) ToJson() string {
@@ -22,3 +33,45 @@ func (l *LeaderboardEntry) ToJson() string {
 		return string(b)
 	}
 }
+
+func LeaderboardEntryListToJson(l []*LeaderboardEntry) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// DecayPoints returns the value that points held at time at have decayed to
+// by time now. A half life of zero or less disables decay.
+func DecayPoints(points float64, at int64, now int64, halfLife int64) float64 {
+	if halfLife <= 0 || now <= at {
+		return points
+	}
+
+	return points * math.Pow(0.5, float64(now-at)/float64(halfLife))
+}
+
+// AddActivePoints adds points earned at the given time to the active points,
+// which may be earlier than the last time points were added.
+func (l *LeaderboardEntry) AddActivePoints(points int, at int64, halfLife int64) {
+	reference := l.ActiveAt
+	if at > reference {
+		reference = at
+	}
+
+	l.ActivePoints = DecayPoints(l.ActivePoints, l.ActiveAt, reference, halfLife) + DecayPoints(float64(points), at, reference, halfLife)
+	l.ActiveAt = reference
+}
+
+// SetActiveScore computes the entry's active score as of now
+func (l *LeaderboardEntry) SetActiveScore(now int64, halfLife int64) {
+	l.ActiveScore = DecayPoints(l.ActivePoints, l.ActiveAt, now, halfLife)
+}
+
+func SortByActiveScore(entries []*LeaderboardEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ActiveScore > entries[j].ActiveScore
+	})
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestActivePoints(t *testing.T) {
+	halfLife := int64(10 * MILLIS_PER_DAY)
+	start := GetMillis()
+
+	entry := &LeaderboardEntry{Username: "a"}
+	entry.PreSave()
+
+	entry.AddActivePoints(4, start, halfLife)
+	entry.AddActivePoints(2, start+halfLife, halfLife)
+
+	entry.SetActiveScore(start+halfLife, halfLife)
+	if math.Abs(entry.ActiveScore-4) > 0.0001 {
+		t.Fatal("first points should have halved")
+	}
+
+	entry.SetActiveScore(start+2*halfLife, halfLife)
+	if math.Abs(entry.ActiveScore-2) > 0.0001 {
+		t.Fatal("all points should have halved again")
+	}
+
+	// points earned in the past are decayed to the latest time
+	entry.AddActivePoints(8, start, halfLife)
+	entry.SetActiveScore(start+halfLife, halfLife)
+	if math.Abs(entry.ActiveScore-8) > 0.0001 {
+		t.Fatal("backdated points should have been decayed")
+	}
+
+	entry.SetActiveScore(start, 0)
+	if entry.ActiveScore != entry.ActivePoints {
+		t.Fatal("should not decay without a half life")
+	}
+
+	entries := []*LeaderboardEntry{{Username: "a", ActiveScore: 1}, {Username: "b", ActiveScore: 3}}
+	SortByActiveScore(entries)
+	if entries[0].Username != "b" {
+		t.Fatal("should sort by active score")
+	}
+}
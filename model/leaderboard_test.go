@@ -13,7 +13,10 @@ func TestLeaderboard(t *testing.T) {
 	}
 
 	str := l.ToJson()
-	l2 := LeaderboardFromJson(str)
+	l2, err := LeaderboardFromJson(str)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if l2.Id != l.Id {
 		t.Fatal("ids should match")
@@ -23,3 +26,16 @@ func TestLeaderboard(t *testing.T) {
 		t.Fatal("names should match")
 	}
 }
+
+func TestLeaderboardVisibility(t *testing.T) {
+	l := &Leaderboard{Name: "TestLeaderboard"}
+	l.PreSave()
+
+	if l.Visibility != LEADERBOARD_VISIBILITY_PUBLIC || l.IsPrivate() {
+		t.Fatal("leaderboards should be public by default")
+	}
+
+	if !IsValidVisibility(LEADERBOARD_VISIBILITY_PRIVATE) || IsValidVisibility("secret") {
+		t.Fatal("bad visibility validation")
+	}
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	LEDGER_TYPE_PULL_REQUEST_MERGED = "pull_request_merged"
+	LEDGER_TYPE_REVIEW              = "review"
+	LEDGER_TYPE_ISSUE_OPENED        = "issue_opened"
+	LEDGER_TYPE_COMMIT              = "commit"
+	LEDGER_TYPE_REVERT              = "revert"
+	LEDGER_TYPE_STREAK_BONUS        = "streak_bonus"
+	LEDGER_TYPE_NEWCOMER_BONUS      = "newcomer_bonus"
+	LEDGER_TYPE_ADJUSTMENT          = "adjustment"
+	LEDGER_TYPE_ADJUSTMENT_REVERSAL = "adjustment_reversal"
+
+	// LEDGER_TYPE_LEGACY holds points awarded before the ledger was kept
+	LEDGER_TYPE_LEGACY = "legacy"
+)
+
+// LedgerEntry records a single change to a user's points along with the
+// contribution that caused it. RelatedId links an entry to the one it undoes
+// or was awarded for, and Reason explains manual adjustments.
+type LedgerEntry struct {
+	Id            string `json:"id"`
+	LeaderboardId string `json:"leaderboard_id"`
+	Username      string `json:"username"`
+	Type          string `json:"type"`
+	Points        int    `json:"points"`
+	Repository    string `json:"repository"`
+	Number        int    `json:"number"`
+	Title         string `json:"title"`
+	Url           string `json:"url"`
+	RelatedId     string `json:"related_id"`
+	Reason        string `json:"reason"`
+	CreateAt      int64  `json:"create_at"`
+}
+
+func (l *LedgerEntry) PreSave() {
+	if l.Id == "" {
+		l.Id = NewId()
+	}
+
+	if l.CreateAt == 0 {
+		l.CreateAt = GetMillis()
+	}
+}
+
+// IsContribution reports whether the entry was earned by contributing, as
+// opposed to adjusting points earned earlier
+func (l *LedgerEntry) IsContribution() bool {
+	return l.Type == LEDGER_TYPE_PULL_REQUEST_MERGED || l.Type == LEDGER_TYPE_REVIEW || l.Type == LEDGER_TYPE_ISSUE_OPENED || l.Type == LEDGER_TYPE_COMMIT
+}
+
+func (l *LedgerEntry) ToJson() string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LedgerEntryListToJson(l []*LedgerEntry) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// CreateTime returns CreateAt as a time.Time, mostly for use in templates
+func (l *LedgerEntry) CreateTime() time.Time {
+	return time.Unix(0, l.CreateAt*int64(time.Millisecond))
+}
+
+// LedgerTotal is the sum of a user's ledger entries on a leaderboard, along
+// with when the first of them was created
+type LedgerTotal struct {
+	LeaderboardId string
+	Username      string
+	Points        int
+	FirstAt       int64
+}
+
+// LegacyLedgerEntries returns a legacy ledger entry for each leaderboard
+// entry holding more or fewer points than its ledger entries total, with the
+// difference as its points. They're dated at the user's first ledger entry,
+// or now if they have none.
+func LegacyLedgerEntries(entries []*LeaderboardEntry, totals []*LedgerTotal, now int64) []*LedgerEntry {
+	byUser := make(map[string]*LedgerTotal, len(totals))
+	for _, total := range totals {
+		byUser[total.LeaderboardId+":"+total.Username] = total
+	}
+
+	legacy := []*LedgerEntry{}
+	for _, entry := range entries {
+		points, at := entry.Points, now
+		if total, ok := byUser[entry.LeaderboardId+":"+entry.Username]; ok {
+			points -= total.Points
+			at = total.FirstAt
+		}
+
+		if points != 0 {
+			legacy = append(legacy, &LedgerEntry{
+				LeaderboardId: entry.LeaderboardId,
+				Username:      entry.Username,
+				Type:          LEDGER_TYPE_LEGACY,
+				Points:        points,
+				CreateAt:      at,
+			})
+		}
+	}
+
+	return legacy
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestLedgerEntryIsContribution(t *testing.T) {
+	for _, entryType := range []string{LEDGER_TYPE_PULL_REQUEST_MERGED, LEDGER_TYPE_REVIEW, LEDGER_TYPE_ISSUE_OPENED, LEDGER_TYPE_COMMIT} {
+		if !(&LedgerEntry{Type: entryType}).IsContribution() {
+			t.Fatal("should be a contribution", entryType)
+		}
+	}
+
+	for _, entryType := range []string{LEDGER_TYPE_REVERT, LEDGER_TYPE_ADJUSTMENT, LEDGER_TYPE_STREAK_BONUS, LEDGER_TYPE_NEWCOMER_BONUS, LEDGER_TYPE_LEGACY} {
+		if (&LedgerEntry{Type: entryType}).IsContribution() {
+			t.Fatal("shouldn't be a contribution", entryType)
+		}
+	}
+}
+
+func TestLegacyLedgerEntries(t *testing.T) {
+	entries := []*LeaderboardEntry{
+		{LeaderboardId: "lb", Username: "legacy", Points: 12},
+		{LeaderboardId: "lb", Username: "ledgered", Points: 5},
+		{LeaderboardId: "lb", Username: "partly", Points: 9},
+		{LeaderboardId: "other", Username: "partly", Points: 4},
+	}
+
+	totals := []*LedgerTotal{
+		{LeaderboardId: "lb", Username: "ledgered", Points: 5, FirstAt: 100},
+		{LeaderboardId: "lb", Username: "partly", Points: 6, FirstAt: 200},
+		{LeaderboardId: "other", Username: "partly", Points: 4, FirstAt: 300},
+	}
+
+	legacy := LegacyLedgerEntries(entries, totals, 1000)
+
+	if len(legacy) != 2 {
+		t.Fatal("only the points missing from the ledger should be recorded", legacy)
+	}
+
+	if legacy[0].Username != "legacy" || legacy[0].Points != 12 || legacy[0].CreateAt != 1000 || legacy[0].Type != LEDGER_TYPE_LEGACY {
+		t.Fatal("points without any ledger entries should all be legacy", legacy[0])
+	}
+
+	if legacy[1].Username != "partly" || legacy[1].LeaderboardId != "lb" || legacy[1].Points != 3 || legacy[1].CreateAt != 200 {
+		t.Fatal("the remainder should be dated at the first ledger entry", legacy[1])
+	}
+
+	ledger := append(legacy, &LedgerEntry{Username: "ledgered", Points: 5}, &LedgerEntry{Username: "partly", Points: 6})
+	for _, entry := range RebuildLeaderboardEntries("lb", ledger, 0) {
+		if expected := entries[0].Points; entry.Username == "legacy" && entry.Points != expected {
+			t.Fatal("rebuilding should keep the legacy points", entry.Points)
+		} else if entry.Username == "partly" && entry.Points != 9 {
+			t.Fatal("rebuilding should keep the legacy remainder", entry.Points)
+		}
+	}
+}
+
+func TestLegacyLedgerEntriesActivePoints(t *testing.T) {
+	day := int64(MILLIS_PER_DAY)
+	entry := &LeaderboardEntry{LeaderboardId: "lb", Username: "legacy", Points: 8}
+
+	active := &LeaderboardEntry{}
+	for _, ledgerEntry := range LegacyLedgerEntries([]*LeaderboardEntry{entry}, nil, 2*day) {
+		active.AddActivePoints(ledgerEntry.Points, ledgerEntry.CreateAt, day)
+	}
+
+	if active.ActivePoints != 8 || active.ActiveAt != 2*day {
+		t.Fatal("legacy points should count towards the active points", active.ActivePoints, active.ActiveAt)
+	}
+}
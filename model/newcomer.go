@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+)
+
+// FilterNewcomers keeps the rankings of users whose first contribution was
+// at or after since. firsts holds each login's first contribution time, and
+// logins linked to a contributor count from the contributor's earliest one.
+func FilterNewcomers(rankings []*LeaderboardEntry, firsts []*LedgerEntry, byLogin map[string]*Contributor, since int64) []*LeaderboardEntry {
+	firstByName := make(map[string]int64, len(firsts))
+	for _, first := range firsts {
+		name := strings.ToLower(first.Username)
+		if contributor, ok := byLogin[name]; ok {
+			name = strings.ToLower(contributor.Name)
+		}
+
+		if existing, ok := firstByName[name]; !ok || first.CreateAt < existing {
+			firstByName[name] = first.CreateAt
+		}
+	}
+
+	newcomers := []*LeaderboardEntry{}
+	for _, entry := range rankings {
+		if first, ok := firstByName[strings.ToLower(entry.Username)]; ok && first >= since {
+			newcomers = append(newcomers, entry)
+		}
+	}
+
+	return newcomers
+}
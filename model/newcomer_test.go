@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestFilterNewcomers(t *testing.T) {
+	contributor := &Contributor{Id: NewId(), Name: "jwilander"}
+	byLogin := map[string]*Contributor{"jwilander": contributor, "joramwork": contributor}
+
+	firsts := []*LedgerEntry{
+		{Username: "jwilander", CreateAt: 500},
+		{Username: "JoramWork", CreateAt: 50},
+		{Username: "newbie", CreateAt: 200},
+		{Username: "veteran", CreateAt: 10},
+	}
+
+	rankings := []*LeaderboardEntry{
+		{Username: "jwilander", Points: 10},
+		{Username: "veteran", Points: 5},
+		{Username: "newbie", Points: 1},
+		{Username: "unknown", Points: 1},
+	}
+
+	newcomers := FilterNewcomers(rankings, firsts, byLogin, 100)
+
+	if len(newcomers) != 1 || newcomers[0].Username != "newbie" {
+		t.Fatal("only newbie should be a newcomer")
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	NOTIFICATION_TOP_ENTERED = "top.entered"
+)
+
+// DEFAULT_NOTIFICATION_TEMPLATES are the text/template messages posted for
+// each kind of notification, unless overridden in NotificationSettings.
+// Templates are executed with the event's data plus the leaderboard's name
+// and, for top.entered, the size of the top.
+var DEFAULT_NOTIFICATION_TEMPLATES = map[string]string{
+	DOMAIN_EVENT_POINTS_AWARDED: `{{.username}} got {{printf "%+d" .points}} points on {{.leaderboard}}{{if .url}} for [{{.repository}}#{{.number}}]({{.url}}) {{.title}}{{else if .reason}}: {{.reason}}{{end}}`,
+	NOTIFICATION_TOP_ENTERED:    `{{.username}} entered the top {{.top}} of {{.leaderboard}} at #{{.rank}} with {{.points}} points`,
+	DOMAIN_EVENT_BADGE_EARNED:   `{{.username}} earned the **{{.badge_name}}** badge on {{.leaderboard}}: {{.description}}`,
+}
+
+// NotificationRoute sends the notifications of one leaderboard, or of every
+// leaderboard if Leaderboard is empty, to a chat incoming webhook. Events
+// limits the kinds of notifications sent, all of them if it's empty.
+type NotificationRoute struct {
+	Leaderboard string   `json:"leaderboard"`
+	Url         string   `json:"url"`
+	Channel     string   `json:"channel"`
+	Username    string   `json:"username"`
+	Events      []string `json:"events"`
+}
+
+func (o *NotificationRoute) Matches(leaderboardName string, kind string) bool {
+	if len(o.Leaderboard) > 0 && o.Leaderboard != leaderboardName {
+		return false
+	}
+
+	if len(o.Events) == 0 {
+		return true
+	}
+
+	for _, event := range o.Events {
+		if event == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookPayload is the body posted to Mattermost and Slack compatible
+// incoming webhooks
+type WebhookPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func (o *WebhookPayload) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// EnteredTop reports whether a rank.changed event moved the user into the
+// top n from below it, or from being unranked
+func EnteredTop(event *DomainEvent, n int) bool {
+	if event.Type != DOMAIN_EVENT_RANK_CHANGED {
+		return false
+	}
+
+	rank, _ := event.Data["rank"].(int)
+	previous, _ := event.Data["previous_rank"].(int)
+
+	return rank > 0 && rank <= n && (previous == 0 || previous > n)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNotificationRoute(t *testing.T) {
+	route := &NotificationRoute{Leaderboard: "lb", Events: []string{DOMAIN_EVENT_BADGE_EARNED}}
+
+	if !route.Matches("lb", DOMAIN_EVENT_BADGE_EARNED) {
+		t.Fatal("should match")
+	}
+
+	if route.Matches("other", DOMAIN_EVENT_BADGE_EARNED) || route.Matches("lb", DOMAIN_EVENT_POINTS_AWARDED) {
+		t.Fatal("should only match the leaderboard and events listed")
+	}
+
+	if !(&NotificationRoute{}).Matches("other", NOTIFICATION_TOP_ENTERED) {
+		t.Fatal("empty route should match everything")
+	}
+}
+
+func TestEnteredTop(t *testing.T) {
+	event := func(rank int, previous int) *DomainEvent {
+		return NewRankChangedEvent("lb", &RankMovement{Username: "a", Rank: rank, PreviousRank: previous}, 10)
+	}
+
+	if !EnteredTop(event(3, 4), 3) || !EnteredTop(event(1, 0), 3) {
+		t.Fatal("should have entered the top")
+	}
+
+	if EnteredTop(event(2, 3), 3) || EnteredTop(event(4, 5), 3) || EnteredTop(event(5, 2), 3) {
+		t.Fatal("should not have entered the top")
+	}
+}
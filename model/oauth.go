@@ -0,0 +1,42 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AuthorizeUrl returns where to send a browser to log in with the provider
+func (s *OAuthSettings) AuthorizeUrl(redirectUri string, state string) string {
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", *s.ClientId)
+	values.Set("redirect_uri", redirectUri)
+	values.Set("state", state)
+	if len(*s.Scope) > 0 {
+		values.Set("scope", *s.Scope)
+	}
+
+	separator := "?"
+	if strings.Contains(*s.AuthUrl, "?") {
+		separator = "&"
+	}
+
+	return *s.AuthUrl + separator + values.Encode()
+}
+
+// RoleFor returns the role a user logging in through the provider gets
+func (s *OAuthSettings) RoleFor(username string) string {
+	for _, admin := range s.Admins {
+		if strings.EqualFold(admin, username) {
+			return ROLE_ADMIN
+		}
+	}
+
+	for _, moderator := range s.Moderators {
+		if strings.EqualFold(moderator, username) {
+			return ROLE_MODERATOR
+		}
+	}
+
+	return ROLE_VIEWER
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestOAuthSettings(serverUrl string) *OAuthSettings {
+	cfg := &Config{}
+	cfg.SetDefaults()
+
+	s := &cfg.OAuthSettings
+	*s.Enable = true
+	*s.ClientId = "client"
+	*s.ClientSecret = "secret"
+	*s.AuthUrl = serverUrl + "/login/oauth/authorize"
+	*s.TokenUrl = serverUrl + "/login/oauth/access_token"
+	*s.UserApiUrl = serverUrl + "/user"
+	s.Admins = []string{"jwilander"}
+	s.Moderators = []string{"crspeller"}
+
+	return s
+}
+
+func TestOAuthAuthorizeUrl(t *testing.T) {
+	s := newTestOAuthSettings("http://localhost")
+
+	authorize, err := url.Parse(s.AuthorizeUrl("http://localhost:8075/oauth/callback", "state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorize.Query().Get("state") != "state" || authorize.Query().Get("client_id") != "client" || !strings.HasPrefix(authorize.String(), "http://localhost/login/oauth/authorize?") {
+		t.Fatal("bad authorize url " + authorize.String())
+	}
+}
+
+func TestOAuthRoleFor(t *testing.T) {
+	s := newTestOAuthSettings("http://localhost")
+
+	if s.RoleFor("JWilander") != ROLE_ADMIN {
+		t.Fatal("should be admin")
+	}
+
+	if s.RoleFor("crspeller") != ROLE_MODERATOR {
+		t.Fatal("should be moderator")
+	}
+
+	if s.RoleFor("someone") != ROLE_VIEWER {
+		t.Fatal("should be viewer")
+	}
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+const (
+	PROFILE_DEFAULT_PER_PAGE = 20
+	PROFILE_MAX_PER_PAGE     = 200
+)
+
+// PROFILE_WINDOW_DAYS are the windows a profile sums recent points over
+var PROFILE_WINDOW_DAYS = []int{7, 30, 90}
+
+type ProfileWindow struct {
+	Days   int `json:"days"`
+	Points int `json:"points"`
+}
+
+// ProfileRank is where the user finished in a season
+type ProfileRank struct {
+	SeasonId   string `json:"season_id"`
+	SeasonName string `json:"season_name"`
+	StartAt    int64  `json:"start_at"`
+	EndAt      int64  `json:"end_at"`
+	Rank       int    `json:"rank"`
+	Points     int    `json:"points"`
+}
+
+// Profile summarizes a user's standing on a leaderboard along with a page
+// of the ledger entries that earned their points. Rank is 0 for users that
+// aren't ranked.
+type Profile struct {
+	Username           string           `json:"username"`
+	Points             int              `json:"points"`
+	Rank               int              `json:"rank"`
+	Windows            []*ProfileWindow `json:"windows"`
+	RankHistory        []*ProfileRank   `json:"rank_history"`
+	Badges             []*Achievement   `json:"badges"`
+	Streak             *Streak          `json:"streak"`
+	Contributions      []*LedgerEntry   `json:"contributions"`
+	TotalContributions int              `json:"total_contributions"`
+	Page               int              `json:"page"`
+	PerPage            int              `json:"per_page"`
+}
+
+// NewProfile sums the user's points from their history, in total and over
+// each of the profile windows ending at now
+func NewProfile(username string, history []*LedgerEntry, now int64) *Profile {
+	p := &Profile{
+		Username:      username,
+		Windows:       make([]*ProfileWindow, len(PROFILE_WINDOW_DAYS)),
+		RankHistory:   []*ProfileRank{},
+		Badges:        []*Achievement{},
+		Contributions: []*LedgerEntry{},
+	}
+
+	for i, days := range PROFILE_WINDOW_DAYS {
+		p.Windows[i] = &ProfileWindow{Days: days}
+	}
+
+	for _, entry := range history {
+		p.Points += entry.Points
+
+		for _, window := range p.Windows {
+			if entry.CreateAt >= now-int64(window.Days)*MILLIS_PER_DAY {
+				window.Points += entry.Points
+			}
+		}
+	}
+
+	return p
+}
+
+// SetPage fills in the given page of the user's history, newest first.
+// Pages start at 0.
+func (p *Profile) SetPage(history []*LedgerEntry, page int, perPage int) {
+	if perPage <= 0 {
+		perPage = PROFILE_DEFAULT_PER_PAGE
+	} else if perPage > PROFILE_MAX_PER_PAGE {
+		perPage = PROFILE_MAX_PER_PAGE
+	}
+
+	if page < 0 {
+		page = 0
+	}
+
+	sorted := make([]*LedgerEntry, len(history))
+	copy(sorted, history)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreateAt > sorted[j].CreateAt
+	})
+
+	p.Page = page
+	p.PerPage = perPage
+	p.TotalContributions = len(sorted)
+	p.Contributions = []*LedgerEntry{}
+
+	if start := page * perPage; start < len(sorted) {
+		end := start + perPage
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		p.Contributions = sorted[start:end]
+	}
+}
+
+func (p *Profile) HasPrevPage() bool {
+	return p.Page > 0
+}
+
+func (p *Profile) HasNextPage() bool {
+	return (p.Page+1)*p.PerPage < p.TotalContributions
+}
+
+func (p *Profile) PrevPage() int {
+	return p.Page - 1
+}
+
+func (p *Profile) NextPage() int {
+	return p.Page + 1
+}
+
+func (p *Profile) ToJson() string {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewProfile(t *testing.T) {
+	now := int64(1000) * MILLIS_PER_DAY
+
+	history := []*LedgerEntry{
+		{Points: 1, CreateAt: now - 1},
+		{Points: 2, CreateAt: now - 10*MILLIS_PER_DAY},
+		{Points: 4, CreateAt: now - 60*MILLIS_PER_DAY},
+		{Points: 8, CreateAt: now - 365*MILLIS_PER_DAY},
+		{Points: -1, CreateAt: now - 2*MILLIS_PER_DAY},
+	}
+
+	p := NewProfile("jwilander", history, now)
+
+	if p.Points != 14 {
+		t.Fatal("wrong total points")
+	}
+
+	expected := map[int]int{7: 0, 30: 2, 90: 6}
+	for _, window := range p.Windows {
+		if window.Points != expected[window.Days] {
+			t.Fatalf("wrong points for %v day window, got %v", window.Days, window.Points)
+		}
+	}
+}
+
+func TestProfileSetPage(t *testing.T) {
+	history := []*LedgerEntry{}
+	for i := int64(0); i < 5; i++ {
+		history = append(history, &LedgerEntry{Points: 1, CreateAt: i})
+	}
+
+	p := NewProfile("jwilander", history, 10)
+
+	p.SetPage(history, 0, 2)
+	if len(p.Contributions) != 2 || p.Contributions[0].CreateAt != 4 || p.HasPrevPage() || !p.HasNextPage() {
+		t.Fatal("bad first page")
+	}
+
+	p.SetPage(history, 2, 2)
+	if len(p.Contributions) != 1 || p.Contributions[0].CreateAt != 0 || !p.HasPrevPage() || p.HasNextPage() {
+		t.Fatal("bad last page")
+	}
+
+	p.SetPage(history, 5, 2)
+	if len(p.Contributions) != 0 {
+		t.Fatal("should be empty past the last page")
+	}
+
+	p.SetPage(history, -1, 0)
+	if p.Page != 0 || p.PerPage != PROFILE_DEFAULT_PER_PAGE || len(p.Contributions) != 5 {
+		t.Fatal("should fall back to defaults")
+	}
+}
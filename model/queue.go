@@ -0,0 +1,126 @@
+package model
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+)
+
+const (
+	QUEUE_STATUS_PENDING    = "pending"
+	QUEUE_STATUS_PROCESSING = "processing"
+	QUEUE_STATUS_FAILED     = "failed"
+)
+
+// QueuedEvent is an incoming GitHub delivery waiting to be processed. Events
+// with the same partition key, the login they concern, are processed in the
+// order they were received. An event that keeps failing is marked failed so
+// it stops holding up the events after it.
+type QueuedEvent struct {
+	Id            string `json:"id"`
+	EventType     string `json:"event_type"`
+	DeliveryId    string `json:"delivery_id"`
+	PartitionKey  string `json:"partition_key"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error"`
+	NextAttemptAt int64  `json:"next_attempt_at"`
+	CreateAt      int64  `json:"create_at"`
+	UpdateAt      int64  `json:"update_at"`
+}
+
+func (o *QueuedEvent) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Status == "" {
+		o.Status = QUEUE_STATUS_PENDING
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+// Fail records a failed attempt, scheduling a retry after backoff doubled
+// for each earlier attempt, or marking the event failed once it has been
+// tried maxAttempts times
+func (o *QueuedEvent) Fail(err error, maxAttempts int, backoff int64, now int64) {
+	o.Attempts++
+	o.LastError = err.Error()
+	if len(o.LastError) > 512 {
+		o.LastError = o.LastError[:512]
+	}
+	o.UpdateAt = now
+
+	if o.Attempts >= maxAttempts {
+		o.Status = QUEUE_STATUS_FAILED
+		return
+	}
+
+	o.Status = QUEUE_STATUS_PENDING
+	o.NextAttemptAt = now + backoff<<uint(o.Attempts-1)
+}
+
+func (o *QueuedEvent) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func QueuedEventListToJson(l []*QueuedEvent) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// PartitionKey returns the login whose points the event of the given type
+// affects, so that each user's events can be processed in order
+func (e *Event) PartitionKey(eventType string) string {
+	switch eventType {
+	case EVENT_TYPE_PULL_REQUEST_REVIEW:
+		return strings.ToLower(e.Review.User.Login)
+	case EVENT_TYPE_ISSUES:
+		return strings.ToLower(e.Issue.User.Login)
+	case EVENT_TYPE_COMMIT:
+		return strings.ToLower(e.Commit.User.Login)
+	}
+
+	return strings.ToLower(e.PullRequest.User.Login)
+}
+
+// Partition maps a partition key to one of n partitions
+func Partition(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// SelectDispatchable picks the events that can be processed now from the
+// unfinished events, oldest first. An event is held back while an earlier
+// event with the same partition key is being processed or waiting to retry.
+// Events may be passed a page at a time, blocked remembering the keys seen
+// on earlier pages.
+func SelectDispatchable(events []*QueuedEvent, blocked map[string]bool, now int64) []*QueuedEvent {
+	dispatchable := []*QueuedEvent{}
+
+	for _, event := range events {
+		if blocked[event.PartitionKey] {
+			continue
+		}
+		blocked[event.PartitionKey] = true
+
+		if event.Status == QUEUE_STATUS_PENDING && event.NextAttemptAt <= now {
+			dispatchable = append(dispatchable, event)
+		}
+	}
+
+	return dispatchable
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueuedEventFail(t *testing.T) {
+	o := &QueuedEvent{}
+	o.PreSave()
+
+	if o.Status != QUEUE_STATUS_PENDING {
+		t.Fatal("should start pending")
+	}
+
+	o.Fail(errors.New("database unavailable"), 3, 1000, 5000)
+	if o.Status != QUEUE_STATUS_PENDING || o.Attempts != 1 || o.NextAttemptAt != 6000 || o.LastError != "database unavailable" {
+		t.Fatal("should retry after the backoff")
+	}
+
+	o.Fail(errors.New("database unavailable"), 3, 1000, 7000)
+	if o.NextAttemptAt != 9000 {
+		t.Fatal("backoff should double")
+	}
+
+	o.Fail(errors.New("database unavailable"), 3, 1000, 9000)
+	if o.Status != QUEUE_STATUS_FAILED || o.Attempts != 3 {
+		t.Fatal("should be marked failed after the last attempt")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	if Partition("jwilander", 4) != Partition("jwilander", 4) {
+		t.Fatal("partition should be stable")
+	}
+
+	seen := make(map[int]bool)
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		p := Partition(key, 4)
+		if p < 0 || p >= 4 {
+			t.Fatal("partition out of range")
+		}
+		seen[p] = true
+	}
+
+	if len(seen) < 2 {
+		t.Fatal("keys should spread over partitions")
+	}
+
+	event := &Event{Review: EventReview{User: EventUser{Login: "Reviewer"}}, PullRequest: EventPullRequest{User: EventUser{Login: "author"}}}
+	if event.PartitionKey(EVENT_TYPE_PULL_REQUEST_REVIEW) != "reviewer" || event.PartitionKey(EVENT_TYPE_PULL_REQUEST) != "author" {
+		t.Fatal("should partition by the user being scored")
+	}
+}
+
+func TestSelectDispatchable(t *testing.T) {
+	events := []*QueuedEvent{
+		{Id: "1", PartitionKey: "a", Status: QUEUE_STATUS_PROCESSING},
+		{Id: "2", PartitionKey: "b", Status: QUEUE_STATUS_PENDING, NextAttemptAt: 2000},
+		{Id: "3", PartitionKey: "c", Status: QUEUE_STATUS_PENDING},
+		{Id: "4", PartitionKey: "a", Status: QUEUE_STATUS_PENDING},
+		{Id: "5", PartitionKey: "b", Status: QUEUE_STATUS_PENDING},
+		{Id: "6", PartitionKey: "c", Status: QUEUE_STATUS_PENDING},
+		{Id: "7", PartitionKey: "d", Status: QUEUE_STATUS_PENDING, NextAttemptAt: 500},
+	}
+
+	dispatchable := SelectDispatchable(events, make(map[string]bool), 1000)
+	if len(dispatchable) != 2 || dispatchable[0].Id != "3" || dispatchable[1].Id != "7" {
+		t.Fatal("should only dispatch the first ready event of each key")
+	}
+
+	blocked := make(map[string]bool)
+	if dispatchable := SelectDispatchable(events[:4], blocked, 1000); len(dispatchable) != 1 || dispatchable[0].Id != "3" {
+		t.Fatal("should dispatch the first ready event of the first page")
+	}
+
+	if dispatchable := SelectDispatchable(events[4:], blocked, 1000); len(dispatchable) != 1 || dispatchable[0].Id != "7" {
+		t.Fatal("keys seen on an earlier page should stay blocked")
+	}
+}
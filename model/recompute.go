@@ -0,0 +1,169 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// RecomputeRequest asks to rebuild a leaderboard's scores from its ledger.
+// Without Commit the recompute is a dry run.
+type RecomputeRequest struct {
+	Commit bool `json:"commit"`
+}
+
+// ScoreChange is how a user's points and rank change when a leaderboard is
+// recomputed. Rank is zero for users left without any points.
+type ScoreChange struct {
+	Username       string `json:"username"`
+	Points         int    `json:"points"`
+	PreviousPoints int    `json:"previous_points"`
+	Rank           int    `json:"rank"`
+	PreviousRank   int    `json:"previous_rank"`
+}
+
+type RecomputeReport struct {
+	Commit        bool           `json:"commit"`
+	LedgerEntries int            `json:"ledger_entries"`
+	Rescored      int            `json:"rescored"`
+	Changes       []*ScoreChange `json:"changes"`
+}
+
+func RecomputeRequestFromJson(data io.Reader) *RecomputeRequest {
+	decoder := json.NewDecoder(data)
+	var o RecomputeRequest
+	err := decoder.Decode(&o)
+	if err == nil || err == io.EOF {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *RecomputeReport) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// RescoreLedger sets the points of each ledger entry to what it earns under
+// the current settings and returns the entries whose points changed. Manual
+// adjustments keep their points, reverts take back whatever the reverted
+// entry now earns, and the bonuses of a disabled bonus feature earn nothing.
+// Only existing entries are rescored: bonuses the current settings would
+// award at other points in the history aren't added, and the entries of
+// users excluded since are kept.
+func RescoreLedger(ledger []*LedgerEntry, cfg *Config) []*LedgerEntry {
+	byId := make(map[string]*LedgerEntry, len(ledger))
+	for _, entry := range ledger {
+		byId[entry.Id] = entry
+	}
+
+	streakBonus := 0
+	if *cfg.StreakSettings.BonusPoints > 0 && *cfg.StreakSettings.BonusInterval > 0 {
+		streakBonus = *cfg.StreakSettings.BonusPoints
+	}
+
+	newcomerBonus := 0
+	if *cfg.NewcomerSettings.BonusPoints > 0 {
+		newcomerBonus = *cfg.NewcomerSettings.BonusPoints
+	}
+
+	rescored := []*LedgerEntry{}
+	rescore := func(entry *LedgerEntry, points int) {
+		if entry.Points != points {
+			entry.Points = points
+			rescored = append(rescored, entry)
+		}
+	}
+
+	for _, entry := range ledger {
+		switch entry.Type {
+		case LEDGER_TYPE_PULL_REQUEST_MERGED:
+			rescore(entry, *cfg.ScoringSettings.PullRequestMergedPoints)
+		case LEDGER_TYPE_REVIEW:
+			rescore(entry, *cfg.ScoringSettings.ReviewSubmittedPoints)
+		case LEDGER_TYPE_ISSUE_OPENED:
+			rescore(entry, *cfg.ScoringSettings.IssueOpenedPoints)
+		case LEDGER_TYPE_COMMIT:
+			rescore(entry, *cfg.ScoringSettings.CommitPoints)
+		case LEDGER_TYPE_STREAK_BONUS:
+			rescore(entry, streakBonus)
+		case LEDGER_TYPE_NEWCOMER_BONUS:
+			rescore(entry, newcomerBonus)
+		}
+	}
+
+	for _, entry := range ledger {
+		if entry.Type != LEDGER_TYPE_REVERT {
+			continue
+		}
+
+		if original, ok := byId[entry.RelatedId]; ok {
+			rescore(entry, -original.Points)
+		}
+	}
+
+	return rescored
+}
+
+// RebuildLeaderboardEntries totals the ledger into one leaderboard entry per
+// user, with active points decayed with the given half life
+func RebuildLeaderboardEntries(leaderboardId string, ledger []*LedgerEntry, halfLife int64) []*LeaderboardEntry {
+	entries := []*LeaderboardEntry{}
+	byUsername := make(map[string]*LeaderboardEntry)
+
+	for _, ledgerEntry := range ledger {
+		entry, ok := byUsername[ledgerEntry.Username]
+		if !ok {
+			entry = &LeaderboardEntry{LeaderboardId: leaderboardId, Username: ledgerEntry.Username}
+			byUsername[ledgerEntry.Username] = entry
+			entries = append(entries, entry)
+		}
+
+		entry.Points += ledgerEntry.Points
+		entry.AddActivePoints(ledgerEntry.Points, ledgerEntry.CreateAt, halfLife)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Points > entries[j].Points
+	})
+
+	return entries
+}
+
+// DiffRankings compares rankings sorted by points before and after a
+// recompute, returning the users whose points or rank changed in the order
+// of the new rankings
+func DiffRankings(before []*LeaderboardEntry, after []*LeaderboardEntry) []*ScoreChange {
+	previous := make(map[string]*SeasonStanding, len(before))
+	for _, standing := range NewSeasonStandings("", before) {
+		previous[standing.Username] = standing
+	}
+
+	changes := []*ScoreChange{}
+	for _, standing := range NewSeasonStandings("", after) {
+		change := &ScoreChange{Username: standing.Username, Points: standing.Points, Rank: standing.Rank}
+
+		if old, ok := previous[standing.Username]; ok {
+			change.PreviousPoints = old.Points
+			change.PreviousRank = old.Rank
+			delete(previous, standing.Username)
+		}
+
+		if change.Points != change.PreviousPoints || change.Rank != change.PreviousRank {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, standing := range NewSeasonStandings("", before) {
+		if old, ok := previous[standing.Username]; ok && old.Points != 0 {
+			changes = append(changes, &ScoreChange{Username: old.Username, PreviousPoints: old.Points, PreviousRank: old.Rank})
+		}
+	}
+
+	return changes
+}
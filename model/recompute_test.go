@@ -0,0 +1,112 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRescoreLedger(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetDefaults()
+	*cfg.ScoringSettings.PullRequestMergedPoints = 10
+	*cfg.ScoringSettings.ReviewSubmittedPoints = 3
+
+	merged := &LedgerEntry{Id: NewId(), Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, Points: 5}
+	review := &LedgerEntry{Id: NewId(), Username: "b", Type: LEDGER_TYPE_REVIEW, Points: 3}
+	revert := &LedgerEntry{Id: NewId(), Username: "a", Type: LEDGER_TYPE_REVERT, Points: -5, RelatedId: merged.Id}
+	adjustment := &LedgerEntry{Id: NewId(), Username: "b", Type: LEDGER_TYPE_ADJUSTMENT, Points: 7}
+
+	rescored := RescoreLedger([]*LedgerEntry{merged, review, revert, adjustment}, cfg)
+
+	if len(rescored) != 2 || rescored[0] != merged || rescored[1] != revert {
+		t.Fatal("only the merge and its revert should be rescored")
+	}
+
+	if merged.Points != 10 || revert.Points != -10 || review.Points != 3 || adjustment.Points != 7 {
+		t.Fatal("bad points", merged.Points, revert.Points, review.Points, adjustment.Points)
+	}
+}
+
+func TestRescoreLedgerDisabledBonuses(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetDefaults()
+	*cfg.StreakSettings.BonusPoints = 5
+	*cfg.StreakSettings.BonusInterval = 0
+	*cfg.NewcomerSettings.BonusPoints = -1
+
+	streak := &LedgerEntry{Id: NewId(), Username: "a", Type: LEDGER_TYPE_STREAK_BONUS, Points: 5}
+	newcomer := &LedgerEntry{Id: NewId(), Username: "a", Type: LEDGER_TYPE_NEWCOMER_BONUS, Points: 10}
+
+	if rescored := RescoreLedger([]*LedgerEntry{streak, newcomer}, cfg); len(rescored) != 2 {
+		t.Fatal("both bonuses should be rescored")
+	}
+
+	if streak.Points != 0 || newcomer.Points != 0 {
+		t.Fatal("disabled bonuses should earn nothing", streak.Points, newcomer.Points)
+	}
+
+	*cfg.StreakSettings.BonusInterval = 4
+	*cfg.NewcomerSettings.BonusPoints = 8
+
+	RescoreLedger([]*LedgerEntry{streak, newcomer}, cfg)
+
+	if streak.Points != 5 || newcomer.Points != 8 {
+		t.Fatal("enabled bonuses should earn the configured points", streak.Points, newcomer.Points)
+	}
+}
+
+func TestRebuildLeaderboardEntries(t *testing.T) {
+	day := int64(MILLIS_PER_DAY)
+	ledger := []*LedgerEntry{
+		{Username: "a", Points: 2, CreateAt: day},
+		{Username: "b", Points: 5, CreateAt: day},
+		{Username: "a", Points: 4, CreateAt: 2 * day},
+	}
+
+	entries := RebuildLeaderboardEntries("lb", ledger, day)
+
+	if len(entries) != 2 || entries[0].Username != "a" || entries[0].Points != 6 || entries[1].Points != 5 {
+		t.Fatal("bad entries")
+	}
+
+	if entries[0].LeaderboardId != "lb" || entries[0].ActiveAt != 2*day || entries[0].ActivePoints != 5 {
+		t.Fatal("bad active points", entries[0].ActiveAt, entries[0].ActivePoints)
+	}
+}
+
+func TestDiffRankings(t *testing.T) {
+	before := []*LeaderboardEntry{{Username: "a", Points: 10}, {Username: "b", Points: 8}, {Username: "c", Points: 1}, {Username: "d", Points: 1}}
+	after := []*LeaderboardEntry{{Username: "b", Points: 12}, {Username: "a", Points: 10}, {Username: "c", Points: 1}, {Username: "e", Points: 1}}
+
+	changes := DiffRankings(before, after)
+
+	if len(changes) != 4 {
+		t.Fatal("wrong number of changes", len(changes))
+	}
+
+	if changes[0].Username != "b" || changes[0].Rank != 1 || changes[0].PreviousRank != 2 || changes[0].PreviousPoints != 8 {
+		t.Fatal("b should move up")
+	}
+
+	if changes[1].Username != "a" || changes[1].Rank != 2 || changes[1].PreviousRank != 1 {
+		t.Fatal("a should move down")
+	}
+
+	if changes[2].Username != "e" || changes[2].PreviousRank != 0 || changes[2].Rank != 3 {
+		t.Fatal("e should be new")
+	}
+
+	if changes[3].Username != "d" || changes[3].Rank != 0 || changes[3].PreviousRank != 3 {
+		t.Fatal("d should be dropped")
+	}
+}
+
+func TestRecomputeRequestFromJson(t *testing.T) {
+	if request := RecomputeRequestFromJson(strings.NewReader("")); request == nil || request.Commit {
+		t.Fatal("an empty request should be a dry run")
+	}
+
+	if request := RecomputeRequestFromJson(strings.NewReader(`{"commit": true}`)); request == nil || !request.Commit {
+		t.Fatal("should commit")
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	REVERT_LABEL = "revert"
+)
+
+// Matches the title GitHub generates for the "Revert" button, e.g. `Revert "Fix the thing"`
+var revertTitleRegex = regexp.MustCompile(`^Revert "(.+)"$`)
+
+// Matches references like "Reverts owner/repo#123", "reverts #123" or a link to the pull request
+var revertBodyRegex = regexp.MustCompile(`(?i)\brevert\w*\s+(?:[\w.-]+/[\w.-]+#|#|https?://\S+/pull/)(\d+)`)
+
+type RevertInfo struct {
+	OriginalNumber int
+	OriginalTitle  string
+}
+
+// RevertInfo returns what is known about the pull request reverted by pr, or nil
+// if pr does not look like a revert. A pull request only carrying the revert
+// label returns an empty RevertInfo since there is nothing to find the original by.
+func (pr *EventPullRequest) RevertInfo() *RevertInfo {
+	info := &RevertInfo{}
+	isRevert := false
+
+	if match := revertTitleRegex.FindStringSubmatch(strings.TrimSpace(pr.Title)); match != nil {
+		info.OriginalTitle = match[1]
+		isRevert = true
+	}
+
+	if match := revertBodyRegex.FindStringSubmatch(pr.Body); match != nil {
+		if number, err := strconv.Atoi(match[1]); err == nil && number != pr.Number {
+			info.OriginalNumber = number
+			isRevert = true
+		}
+	}
+
+	for _, label := range pr.Labels {
+		if strings.EqualFold(label.Name, REVERT_LABEL) {
+			isRevert = true
+		}
+	}
+
+	if !isRevert {
+		return nil
+	}
+
+	return info
+}
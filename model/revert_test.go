@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestRevertInfo(t *testing.T) {
+	pr := &EventPullRequest{Number: 12, Title: "Fix the login page"}
+	if pr.RevertInfo() != nil {
+		t.Fatal("should not be a revert")
+	}
+
+	pr = &EventPullRequest{Number: 12, Title: `Revert "Fix the login page"`, Body: "Reverts mattermost/platform#10"}
+	if info := pr.RevertInfo(); info == nil {
+		t.Fatal("should be a revert")
+	} else {
+		if info.OriginalTitle != "Fix the login page" {
+			t.Fatal("wrong original title " + info.OriginalTitle)
+		}
+		if info.OriginalNumber != 10 {
+			t.Fatal("wrong original number")
+		}
+	}
+
+	pr = &EventPullRequest{Number: 12, Title: "Undo login change", Body: "This reverts #11 since it broke the build"}
+	if info := pr.RevertInfo(); info == nil || info.OriginalNumber != 11 {
+		t.Fatal("should have found original number in body")
+	}
+
+	pr = &EventPullRequest{Number: 12, Title: "Undo login change", Body: "Reverting https://github.com/mattermost/platform/pull/9"}
+	if info := pr.RevertInfo(); info == nil || info.OriginalNumber != 9 {
+		t.Fatal("should have found original number in link")
+	}
+
+	pr = &EventPullRequest{Number: 12, Title: "Undo login change", Labels: []EventLabel{{Name: "Revert"}}}
+	if info := pr.RevertInfo(); info == nil || info.OriginalNumber != 0 || info.OriginalTitle != "" {
+		t.Fatal("should be a revert with nothing known about the original")
+	}
+
+	pr = &EventPullRequest{Number: 12, Title: "Fix #10", Body: "Fixes the issue from #10"}
+	if pr.RevertInfo() != nil {
+		t.Fatal("mentioning a pull request should not be a revert")
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	SEASON_WINNERS = 3
+)
+
+// Season is a stretch of time over which a leaderboard's points are ranked
+// separately from the all-time rankings. Once closed its final standings are
+// archived and no longer change.
+type Season struct {
+	Id            string            `json:"id"`
+	LeaderboardId string            `json:"leaderboard_id"`
+	Name          string            `json:"name"`
+	StartAt       int64             `json:"start_at"`
+	EndAt         int64             `json:"end_at"`
+	ClosedAt      int64             `json:"closed_at"`
+	CreateAt      int64             `json:"create_at"`
+	Winners       []*SeasonStanding `json:"winners,omitempty" db:"-"`
+}
+
+type SeasonStanding struct {
+	SeasonId string `json:"season_id"`
+	Rank     int    `json:"rank"`
+	Username string `json:"username"`
+	Points   int    `json:"points"`
+}
+
+func (s *Season) PreSave() {
+	if s.Id == "" {
+		s.Id = NewId()
+	}
+
+	s.CreateAt = GetMillis()
+}
+
+func (s *Season) IsClosed() bool {
+	return s.ClosedAt != 0
+}
+
+func (s *Season) StartTime() time.Time {
+	return time.Unix(0, s.StartAt*int64(time.Millisecond)).UTC()
+}
+
+func (s *Season) EndTime() time.Time {
+	return time.Unix(0, s.EndAt*int64(time.Millisecond)).UTC()
+}
+
+func (s *Season) ToJson() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SeasonListToJson(l []*Season) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SeasonStandingListToJson(l []*SeasonStanding) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// IsValidSeasonLength reports whether seasons of lengthMonths months divide
+// the year evenly. Other lengths would overlap when aligned to each year.
+func IsValidSeasonLength(lengthMonths int) bool {
+	return lengthMonths > 0 && lengthMonths <= 12 && 12%lengthMonths == 0
+}
+
+// NewSeason returns the season of lengthMonths months that contains t. Seasons
+// are aligned to the start of the year in UTC, so three month seasons are the
+// calendar quarters.
+func NewSeason(leaderboardId string, t time.Time, lengthMonths int) *Season {
+	if !IsValidSeasonLength(lengthMonths) {
+		lengthMonths = 3
+	}
+
+	t = t.UTC()
+	month := (int(t.Month())-1)/lengthMonths*lengthMonths + 1
+	start := time.Date(t.Year(), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, lengthMonths, 0)
+
+	var name string
+	switch lengthMonths {
+	case 1:
+		name = start.Format("January 2006")
+	case 3:
+		name = fmt.Sprintf("%v Q%v", start.Year(), (month-1)/3+1)
+	case 12:
+		name = start.Format("2006")
+	default:
+		name = start.Format("Jan 2006") + " - " + end.AddDate(0, -1, 0).Format("Jan 2006")
+	}
+
+	return &Season{
+		LeaderboardId: leaderboardId,
+		Name:          name,
+		StartAt:       start.UnixNano() / int64(time.Millisecond),
+		EndAt:         end.UnixNano() / int64(time.Millisecond),
+	}
+}
+
+// NewSeasonStandings ranks entries that are already sorted by points, giving
+// tied users the same rank.
+func NewSeasonStandings(seasonId string, entries []*LeaderboardEntry) []*SeasonStanding {
+	standings := make([]*SeasonStanding, 0, len(entries))
+
+	for i, entry := range entries {
+		rank := i + 1
+		if i > 0 && entry.Points == entries[i-1].Points {
+			rank = standings[i-1].Rank
+		}
+
+		standings = append(standings, &SeasonStanding{
+			SeasonId: seasonId,
+			Rank:     rank,
+			Username: entry.Username,
+			Points:   entry.Points,
+		})
+	}
+
+	return standings
+}
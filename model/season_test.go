@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSeason(t *testing.T) {
+	season := NewSeason("leaderboard", time.Date(2016, time.November, 15, 12, 0, 0, 0, time.UTC), 3)
+
+	if season.Name != "2016 Q4" {
+		t.Fatal("wrong season name " + season.Name)
+	}
+
+	if !season.StartTime().Equal(time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("season should start at the beginning of the quarter")
+	}
+
+	if !season.EndTime().Equal(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("season should end at the beginning of the next quarter")
+	}
+
+	season = NewSeason("leaderboard", time.Date(2016, time.March, 31, 0, 0, 0, 0, time.UTC), 1)
+	if season.Name != "March 2016" {
+		t.Fatal("wrong monthly season name " + season.Name)
+	}
+}
+
+func TestNewSeasonStandings(t *testing.T) {
+	entries := []*LeaderboardEntry{
+		{Username: "a", Points: 5},
+		{Username: "b", Points: 3},
+		{Username: "c", Points: 3},
+		{Username: "d", Points: 1},
+	}
+
+	standings := NewSeasonStandings("season", entries)
+
+	if standings[0].Rank != 1 || standings[1].Rank != 2 || standings[2].Rank != 2 || standings[3].Rank != 4 {
+		t.Fatal("ties should share a rank")
+	}
+}
+
+func TestNewSeasonLength(t *testing.T) {
+	for _, lengthMonths := range []int{1, 2, 3, 4, 6, 12} {
+		if !IsValidSeasonLength(lengthMonths) {
+			t.Fatal("should accept seasons that divide the year", lengthMonths)
+		}
+	}
+
+	for _, lengthMonths := range []int{0, 5, 7, 8, 13, -1} {
+		if IsValidSeasonLength(lengthMonths) {
+			t.Fatal("shouldn't accept seasons that don't divide the year", lengthMonths)
+		}
+
+		if season := NewSeason("leaderboard", time.Date(2016, time.November, 15, 0, 0, 0, 0, time.UTC), lengthMonths); season.Name != "2016 Q4" {
+			t.Fatal("invalid lengths should fall back to quarters", season.Name)
+		}
+	}
+
+	cfg := Config{}
+	cfg.SetDefaults()
+	if err := cfg.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	*cfg.SeasonSettings.LengthMonths = 5
+	if err := cfg.IsValid(); err == nil {
+		t.Fatal("five month seasons should be rejected")
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// NewShareToken returns a token granting access to the leaderboard until
+// expireAt. The token is the expiry time and an HMAC of it and the
+// leaderboard id, so it can't be altered or used for another leaderboard.
+func NewShareToken(secret string, leaderboardId string, expireAt int64) string {
+	expiry := strconv.FormatInt(expireAt, 10)
+	return expiry + "." + shareSignature(secret, leaderboardId, expiry)
+}
+
+// IsValidShareToken returns true if token was made with secret for the
+// leaderboard and hasn't expired by now
+func IsValidShareToken(secret string, token string, leaderboardId string, now int64) bool {
+	if len(secret) == 0 {
+		return false
+	}
+
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expireAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || now > expireAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(parts[1]), []byte(shareSignature(secret, leaderboardId, parts[0])))
+}
+
+func shareSignature(secret string, leaderboardId string, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(leaderboardId + ":" + expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestShareToken(t *testing.T) {
+	now := GetMillis()
+	token := NewShareToken("secret", "lb", now+1000)
+
+	if !IsValidShareToken("secret", token, "lb", now) {
+		t.Fatal("token should be valid")
+	}
+
+	if IsValidShareToken("secret", token, "lb", now+1001) {
+		t.Fatal("token should have expired")
+	}
+
+	if IsValidShareToken("secret", token, "other", now) {
+		t.Fatal("token should only be valid for its leaderboard")
+	}
+
+	if IsValidShareToken("other", token, "lb", now) {
+		t.Fatal("token should only be valid with its secret")
+	}
+
+	if IsValidShareToken("", NewShareToken("", "lb", now+1000), "lb", now) {
+		t.Fatal("tokens should not be valid without a secret")
+	}
+
+	tampered := NewShareToken("secret", "lb", now+1000000)
+	tampered = "9999999999999" + tampered[len(tampered)-65:]
+	if IsValidShareToken("secret", tampered, "lb", now) {
+		t.Fatal("token with a changed expiry should be invalid")
+	}
+
+	if IsValidShareToken("secret", "garbage", "lb", now) {
+		t.Fatal("garbage should be invalid")
+	}
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MOVEMENT_DAY  = "day"
+	MOVEMENT_WEEK = "week"
+)
+
+// RankSnapshot records a user's rank and points on a leaderboard at the end
+// of a day. Day is the start of the UTC day in milliseconds.
+type RankSnapshot struct {
+	LeaderboardId string `json:"leaderboard_id"`
+	Day           int64  `json:"day"`
+	Username      string `json:"username"`
+	Rank          int    `json:"rank"`
+	Points        int    `json:"points"`
+}
+
+// RankMovement is how far a user moved since an earlier snapshot. Change is
+// positive for users that climbed, and New is set for users who weren't
+// ranked in the snapshot.
+type RankMovement struct {
+	Username     string `json:"username"`
+	Rank         int    `json:"rank"`
+	PreviousRank int    `json:"previous_rank"`
+	Change       int    `json:"change"`
+	New          bool   `json:"new"`
+}
+
+// SnapshotDay returns the start of the UTC day containing millis
+func SnapshotDay(millis int64) int64 {
+	return (millis / MILLIS_PER_DAY) * MILLIS_PER_DAY
+}
+
+// MovementSince returns the day to compare today's rankings with for the
+// given movement period
+func MovementSince(movement string, now int64) int64 {
+	if movement == MOVEMENT_WEEK {
+		return SnapshotDay(now) - 7*MILLIS_PER_DAY
+	}
+
+	return SnapshotDay(now) - MILLIS_PER_DAY
+}
+
+func (s *RankSnapshot) DayTime() time.Time {
+	return time.Unix(0, s.Day*int64(time.Millisecond)).UTC()
+}
+
+// NewRankSnapshots snapshots rankings that are already sorted by points,
+// giving tied users the same rank like season standings
+func NewRankSnapshots(leaderboardId string, day int64, rankings []*LeaderboardEntry) []*RankSnapshot {
+	snapshots := make([]*RankSnapshot, 0, len(rankings))
+
+	for _, standing := range NewSeasonStandings("", rankings) {
+		snapshots = append(snapshots, &RankSnapshot{
+			LeaderboardId: leaderboardId,
+			Day:           day,
+			Username:      standing.Username,
+			Rank:          standing.Rank,
+			Points:        standing.Points,
+		})
+	}
+
+	return snapshots
+}
+
+// ComputeMovements compares the current rankings, sorted by points, with an
+// earlier snapshot, keyed by username
+func ComputeMovements(rankings []*LeaderboardEntry, previous []*RankSnapshot) map[string]*RankMovement {
+	previousRanks := make(map[string]int, len(previous))
+	for _, snapshot := range previous {
+		previousRanks[snapshot.Username] = snapshot.Rank
+	}
+
+	movements := make(map[string]*RankMovement, len(rankings))
+	for _, standing := range NewSeasonStandings("", rankings) {
+		movement := &RankMovement{Username: standing.Username, Rank: standing.Rank}
+
+		if rank, ok := previousRanks[standing.Username]; ok {
+			movement.PreviousRank = rank
+			movement.Change = rank - standing.Rank
+		} else {
+			movement.New = len(previous) > 0
+		}
+
+		movements[standing.Username] = movement
+	}
+
+	return movements
+}
+
+func (m *RankMovement) IsUp() bool {
+	return m.Change > 0
+}
+
+func (m *RankMovement) IsDown() bool {
+	return m.Change < 0
+}
+
+// Distance is the number of places moved, regardless of direction
+func (m *RankMovement) Distance() int {
+	if m.Change < 0 {
+		return -m.Change
+	}
+
+	return m.Change
+}
+
+func RankSnapshotListToJson(l []*RankSnapshot) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RankMovementMapToJson(m map[string]*RankMovement) string {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestSnapshotDay(t *testing.T) {
+	day := int64(20000) * MILLIS_PER_DAY
+
+	if SnapshotDay(day+12345) != day || SnapshotDay(day) != day {
+		t.Fatal("should truncate to the start of the day")
+	}
+
+	if MovementSince(MOVEMENT_DAY, day+5) != day-MILLIS_PER_DAY {
+		t.Fatal("day movement should compare with yesterday")
+	}
+
+	if MovementSince(MOVEMENT_WEEK, day+5) != day-7*MILLIS_PER_DAY {
+		t.Fatal("week movement should compare with a week ago")
+	}
+}
+
+func TestComputeMovements(t *testing.T) {
+	previous := NewRankSnapshots("lb", 0, []*LeaderboardEntry{
+		{Username: "a", Points: 10},
+		{Username: "b", Points: 8},
+		{Username: "c", Points: 5},
+	})
+
+	if previous[2].Rank != 3 || previous[0].Day != 0 || previous[0].LeaderboardId != "lb" {
+		t.Fatal("bad snapshots")
+	}
+
+	movements := ComputeMovements([]*LeaderboardEntry{
+		{Username: "c", Points: 12},
+		{Username: "a", Points: 11},
+		{Username: "d", Points: 9},
+		{Username: "b", Points: 8},
+	}, previous)
+
+	if m := movements["c"]; m.Change != 2 || !m.IsUp() || m.Distance() != 2 || m.PreviousRank != 3 {
+		t.Fatal("c should have climbed two places")
+	}
+
+	if m := movements["a"]; m.Change != -1 || !m.IsDown() || m.Distance() != 1 {
+		t.Fatal("a should have dropped a place")
+	}
+
+	if m := movements["b"]; m.Change != -2 {
+		t.Fatal("b should have dropped two places")
+	}
+
+	if m := movements["d"]; !m.New || m.Change != 0 {
+		t.Fatal("d should be new")
+	}
+
+	if ComputeMovements([]*LeaderboardEntry{{Username: "a", Points: 1}}, nil)["a"].New {
+		t.Fatal("nobody should be new without an earlier snapshot")
+	}
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+const (
+	STREAK_PERIOD_DAY  = "day"
+	STREAK_PERIOD_WEEK = "week"
+
+	MILLIS_PER_DAY = 24 * 60 * 60 * 1000
+)
+
+// PeriodIndex numbers the UTC day, or the week starting on Monday, that
+// contains the given time in milliseconds.
+func PeriodIndex(millis int64, period string) int64 {
+	day := millis / MILLIS_PER_DAY
+
+	if period == STREAK_PERIOD_DAY {
+		return day
+	}
+
+	// the epoch fell on a Thursday
+	return (day + 3) / 7
+}
+
+// streakCounter follows runs of consecutive periods with a contribution as
+// contribution times are added in chronological order
+type streakCounter struct {
+	period  string
+	last    int64
+	current int
+	longest int
+}
+
+func newStreakCounter(period string) *streakCounter {
+	return &streakCounter{period: period}
+}
+
+func (c *streakCounter) Add(millis int64) {
+	index := PeriodIndex(millis, c.period)
+
+	if c.current > 0 && index == c.last {
+		return
+	} else if c.current > 0 && index == c.last+1 {
+		c.current++
+	} else {
+		c.current = 1
+	}
+
+	c.last = index
+	if c.current > c.longest {
+		c.longest = c.current
+	}
+}
+
+// Streak counts the consecutive days or weeks in which a user contributed.
+// The current streak is still alive if the user contributed in the current
+// or the previous period.
+type Streak struct {
+	Username string `json:"username"`
+	Period   string `json:"period"`
+	Current  int    `json:"current"`
+	Longest  int    `json:"longest"`
+}
+
+func (s *Streak) ToJson() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func StreakMapToJson(m map[string]*Streak) string {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// ComputeStreaks returns the streak of every user with a contribution in
+// entries, as of the time now.
+func ComputeStreaks(entries []*LedgerEntry, period string, now int64) map[string]*Streak {
+	sorted := make([]*LedgerEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreateAt < sorted[j].CreateAt
+	})
+
+	counters := make(map[string]*streakCounter)
+	for _, entry := range sorted {
+		if !entry.IsContribution() {
+			continue
+		}
+
+		counter, ok := counters[entry.Username]
+		if !ok {
+			counter = newStreakCounter(period)
+			counters[entry.Username] = counter
+		}
+
+		counter.Add(entry.CreateAt)
+	}
+
+	current := PeriodIndex(now, period)
+
+	streaks := make(map[string]*Streak, len(counters))
+	for username, counter := range counters {
+		streak := &Streak{Username: username, Period: period, Longest: counter.longest}
+		if counter.last >= current-1 {
+			streak.Current = counter.current
+		}
+
+		streaks[username] = streak
+	}
+
+	return streaks
+}
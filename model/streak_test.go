@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestComputeStreaks(t *testing.T) {
+	week := int64(7 * MILLIS_PER_DAY)
+	start := int64(1000) * week
+
+	entries := []*LedgerEntry{
+		{Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start},
+		{Username: "a", Type: LEDGER_TYPE_REVIEW, CreateAt: start + week},
+		{Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start + week + 1},
+		{Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start + 2*week},
+		{Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start + 5*week},
+		{Username: "a", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start + 6*week},
+		{Username: "b", Type: LEDGER_TYPE_PULL_REQUEST_MERGED, CreateAt: start},
+		{Username: "b", Type: LEDGER_TYPE_REVERT, CreateAt: start + 6*week},
+	}
+
+	streaks := ComputeStreaks(entries, STREAK_PERIOD_WEEK, start+7*week)
+
+	if streaks["a"].Longest != 3 || streaks["a"].Current != 2 {
+		t.Fatal("wrong streak for a")
+	}
+
+	if streaks["b"].Longest != 1 || streaks["b"].Current != 0 {
+		t.Fatal("wrong streak for b, reverts should not count")
+	}
+
+	streaks = ComputeStreaks(entries, STREAK_PERIOD_WEEK, start+8*week)
+	if streaks["a"].Current != 0 {
+		t.Fatal("streak should have ended")
+	}
+
+	day := int64(MILLIS_PER_DAY)
+	streaks = ComputeStreaks(entries[:3], STREAK_PERIOD_DAY, start+week+day)
+	if streaks["a"].Longest != 1 || streaks["a"].Current != 1 {
+		t.Fatal("wrong daily streak")
+	}
+}
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+type Team struct {
+	Id          string        `json:"id"`
+	Name        string        `json:"name"`
+	DisplayName string        `json:"display_name"`
+	CreateAt    int64         `json:"create_at"`
+	UpdateAt    int64         `json:"update_at"`
+	Members     []*TeamMember `json:"members,omitempty" db:"-"`
+}
+
+// TeamMember puts a user on a team. Users may be on several teams and only
+// contribute Weight times their points to each one.
+type TeamMember struct {
+	TeamId   string  `json:"team_id"`
+	Username string  `json:"username"`
+	Weight   float64 `json:"weight"`
+	CreateAt int64   `json:"create_at"`
+}
+
+type TeamRanking struct {
+	Team    *Team   `json:"team"`
+	Points  float64 `json:"points"`
+	Members int     `json:"members"`
+}
+
+func (t *Team) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	if t.DisplayName == "" {
+		t.DisplayName = t.Name
+	}
+
+	t.CreateAt = GetMillis()
+	t.UpdateAt = t.CreateAt
+}
+
+func (t *Team) IsValid() error {
+	if len(t.Name) == 0 || len(t.Name) > 64 {
+		return errors.New("Invalid team name")
+	}
+
+	if len(t.DisplayName) > 128 {
+		return errors.New("Invalid team display name")
+	}
+
+	return nil
+}
+
+func (t *Team) ToJson() string {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamFromJson(data io.Reader) *Team {
+	decoder := json.NewDecoder(data)
+	var o Team
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func TeamListToJson(l []*Team) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// TeamListFromJson decodes a list of teams with their members, the format
+// used by the team membership file
+func TeamListFromJson(data io.Reader) []*Team {
+	decoder := json.NewDecoder(data)
+	var o []*Team
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (m *TeamMember) PreSave() {
+	m.CreateAt = GetMillis()
+}
+
+// UnmarshalJSON gives members without a weight a weight of 1, while still
+// allowing an explicit weight of 0
+func (m *TeamMember) UnmarshalJSON(data []byte) error {
+	type teamMember TeamMember
+	o := teamMember{Weight: 1}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+
+	*m = TeamMember(o)
+	return nil
+}
+
+func (m *TeamMember) IsValid() error {
+	if len(m.TeamId) != 26 {
+		return errors.New("Invalid team id")
+	}
+
+	if len(m.Username) == 0 || len(m.Username) > 128 {
+		return errors.New("Invalid team member username")
+	}
+
+	if m.Weight < 0 {
+		return errors.New("Invalid team member weight")
+	}
+
+	return nil
+}
+
+func (m *TeamMember) ToJson() string {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamMemberFromJson(data io.Reader) *TeamMember {
+	decoder := json.NewDecoder(data)
+	var o TeamMember
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func TeamRankingListToJson(l []*TeamRanking) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// ComputeTeamRankings rolls the points of each team's members up into team
+// totals. Member usernames are resolved through byLogin the same way the
+// rankings were aggregated, so a contributor listed under more than one of
+// their accounts only counts once per team, at the highest weight given.
+func ComputeTeamRankings(teams []*Team, members []*TeamMember, rankings []*LeaderboardEntry, byLogin map[string]*Contributor) []*TeamRanking {
+	points := make(map[string]int, len(rankings))
+	for _, entry := range rankings {
+		points[strings.ToLower(entry.Username)] = entry.Points
+	}
+
+	weights := make(map[string]map[string]float64, len(teams))
+	for _, member := range members {
+		username := strings.ToLower(member.Username)
+		if contributor, ok := byLogin[username]; ok {
+			username = strings.ToLower(contributor.Name)
+		}
+
+		if weights[member.TeamId] == nil {
+			weights[member.TeamId] = make(map[string]float64)
+		}
+
+		if weight, ok := weights[member.TeamId][username]; !ok || member.Weight > weight {
+			weights[member.TeamId][username] = member.Weight
+		}
+	}
+
+	teamRankings := make([]*TeamRanking, 0, len(teams))
+	for _, team := range teams {
+		ranking := &TeamRanking{Team: team}
+
+		for username, weight := range weights[team.Id] {
+			ranking.Points += float64(points[username]) * weight
+			ranking.Members++
+		}
+
+		teamRankings = append(teamRankings, ranking)
+	}
+
+	sort.SliceStable(teamRankings, func(i, j int) bool {
+		return teamRankings[i].Points > teamRankings[j].Points
+	})
+
+	return teamRankings
+}
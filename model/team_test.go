@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeTeamRankings(t *testing.T) {
+	core := &Team{Name: "core"}
+	core.PreSave()
+	community := &Team{Name: "community"}
+	community.PreSave()
+
+	contributor := &Contributor{Id: NewId(), Name: "jwilander"}
+	byLogin := map[string]*Contributor{"jwilander": contributor, "joramwork": contributor}
+
+	members := []*TeamMember{
+		{TeamId: core.Id, Username: "jwilander", Weight: 1},
+		{TeamId: core.Id, Username: "JoramWork", Weight: 0.5},
+		{TeamId: core.Id, Username: "someone", Weight: 1},
+		{TeamId: community.Id, Username: "someone", Weight: 0.5},
+		{TeamId: community.Id, Username: "newbie", Weight: 1},
+	}
+
+	rankings := []*LeaderboardEntry{
+		{Username: "jwilander", Points: 10},
+		{Username: "someone", Points: 4},
+		{Username: "newbie", Points: 1},
+	}
+
+	teamRankings := ComputeTeamRankings([]*Team{community, core}, members, rankings, byLogin)
+
+	if len(teamRankings) != 2 {
+		t.Fatal("should have ranked both teams")
+	}
+
+	if teamRankings[0].Team.Id != core.Id || teamRankings[0].Points != 14 || teamRankings[0].Members != 2 {
+		t.Fatal("core team should be first, counting each contributor once")
+	}
+
+	if teamRankings[1].Points != 3 {
+		t.Fatal("should have weighted shared members")
+	}
+}
+
+func TestTeamMemberFromJsonWeight(t *testing.T) {
+	if member := TeamMemberFromJson(strings.NewReader(`{"username": "jwilander"}`)); member == nil || member.Weight != 1 {
+		t.Fatal("members without a weight should count fully", member)
+	}
+
+	if member := TeamMemberFromJson(strings.NewReader(`{"username": "jwilander", "weight": 0}`)); member == nil || member.Weight != 0 {
+		t.Fatal("a weight of 0 should be kept", member)
+	}
+
+	teams := TeamListFromJson(strings.NewReader(`[{"name": "core", "members": [{"username": "a"}, {"username": "b", "weight": 0.5}]}]`))
+	if len(teams) != 1 || teams[0].Members[0].Weight != 1 || teams[0].Members[1].Weight != 0.5 {
+		t.Fatal("wrong weights from the membership file")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base32"
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/pborman/uuid"
 )
@@ -27,6 +28,11 @@ func NewId() string {
 	return b.String()
 }
 
+// GetMillis is a convenience method to get milliseconds since epoch.
+func GetMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
 // MapToJson converts a map to a json string
 func MapToJson(objmap map[string]string) string {
 	if b, err := json.Marshal(objmap); err != nil {
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+)
+
+var WEBHOOK_EVENTS = []string{
+	DOMAIN_EVENT_POINTS_AWARDED,
+	DOMAIN_EVENT_RANK_CHANGED,
+	DOMAIN_EVENT_BADGE_EARNED,
+	DOMAIN_EVENT_SEASON_CLOSED,
+}
+
+// WebhookSubscription receives a leaderboard's domain events as signed JSON
+// POSTs to its Url. Events limits the events sent, all of them if it's empty.
+type WebhookSubscription struct {
+	Id            string      `json:"id"`
+	LeaderboardId string      `json:"leaderboard_id"`
+	Url           string      `json:"url"`
+	Secret        string      `json:"secret,omitempty"`
+	Events        StringArray `json:"events"`
+	CreateAt      int64       `json:"create_at"`
+}
+
+func (o *WebhookSubscription) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Secret == "" {
+		o.Secret = NewSecret()
+	}
+
+	if o.Events == nil {
+		o.Events = StringArray{}
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *WebhookSubscription) IsValid() error {
+	if len(o.LeaderboardId) == 0 {
+		return errors.New("Invalid webhook leaderboard")
+	}
+
+	if u, err := url.Parse(o.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 || len(o.Url) > 1024 {
+		return errors.New("Invalid webhook url, url=" + o.Url)
+	}
+
+	for _, event := range o.Events {
+		if !IsValidWebhookEvent(event) {
+			return errors.New("Invalid webhook event, event=" + event)
+		}
+	}
+
+	return nil
+}
+
+func IsValidWebhookEvent(event string) bool {
+	for _, valid := range WEBHOOK_EVENTS {
+		if event == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *WebhookSubscription) Matches(event *DomainEvent) bool {
+	if event.LeaderboardId != o.LeaderboardId {
+		return false
+	}
+
+	if len(o.Events) == 0 {
+		return true
+	}
+
+	for _, eventType := range o.Events {
+		if eventType == event.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sanitize hides the secret, which is only returned when the subscription
+// is created
+func (o *WebhookSubscription) Sanitize() {
+	o.Secret = ""
+}
+
+func (o *WebhookSubscription) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebhookSubscriptionFromJson(data io.Reader) *WebhookSubscription {
+	decoder := json.NewDecoder(data)
+	var o WebhookSubscription
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func WebhookSubscriptionListToJson(l []*WebhookSubscription) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// WebhookDelivery logs an attempt to deliver an event to a subscription,
+// including its retries
+type WebhookDelivery struct {
+	Id             string `json:"id"`
+	SubscriptionId string `json:"subscription_id"`
+	EventType      string `json:"event_type"`
+	Payload        string `json:"payload"`
+	Attempts       int    `json:"attempts"`
+	StatusCode     int    `json:"status_code"`
+	Error          string `json:"error"`
+	Success        bool   `json:"success"`
+	RedeliveryOf   string `json:"redelivery_of,omitempty"`
+	CreateAt       int64  `json:"create_at"`
+	UpdateAt       int64  `json:"update_at"`
+}
+
+func NewWebhookDelivery(subscription *WebhookSubscription, event *DomainEvent) *WebhookDelivery {
+	return &WebhookDelivery{
+		SubscriptionId: subscription.Id,
+		EventType:      event.Type,
+		Payload:        event.ToJson(),
+	}
+}
+
+// Redeliver creates a new delivery of the same payload
+func (o *WebhookDelivery) Redeliver() *WebhookDelivery {
+	return &WebhookDelivery{
+		SubscriptionId: o.SubscriptionId,
+		EventType:      o.EventType,
+		Payload:        o.Payload,
+		RedeliveryOf:   o.Id,
+	}
+}
+
+func (o *WebhookDelivery) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *WebhookDelivery) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebhookDeliveryListToJson(l []*WebhookDelivery) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
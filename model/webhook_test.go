@@ -0,0 +1,60 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWebhookSubscription(t *testing.T) {
+	o := &WebhookSubscription{LeaderboardId: NewId(), Url: "https://example.com/hook"}
+	o.PreSave()
+
+	if len(o.Id) == 0 || len(o.Secret) == 0 || o.Events == nil {
+		t.Fatal("should have an id, secret and events")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o2 := WebhookSubscriptionFromJson(strings.NewReader(o.ToJson()))
+	if o2 == nil || o2.Secret != o.Secret {
+		t.Fatal("secret should be returned until sanitized")
+	}
+
+	o2.Sanitize()
+	if strings.Contains(o2.ToJson(), "secret") {
+		t.Fatal("secret should be hidden")
+	}
+
+	for _, url := range []string{"", "ftp://example.com", "example.com/hook", "https://"} {
+		o.Url = url
+		if o.IsValid() == nil {
+			t.Fatal("should be invalid", url)
+		}
+	}
+
+	o.Url = "http://localhost:8080/hook"
+	o.Events = StringArray{DOMAIN_EVENT_BADGE_EARNED, "push"}
+	if o.IsValid() == nil {
+		t.Fatal("unknown events should be invalid")
+	}
+}
+
+func TestWebhookSubscriptionMatches(t *testing.T) {
+	o := &WebhookSubscription{LeaderboardId: "lb"}
+
+	event := &DomainEvent{Type: DOMAIN_EVENT_RANK_CHANGED, LeaderboardId: "lb"}
+	if !o.Matches(event) {
+		t.Fatal("should match every event without a filter")
+	}
+
+	o.Events = StringArray{DOMAIN_EVENT_BADGE_EARNED}
+	if o.Matches(event) || !o.Matches(&DomainEvent{Type: DOMAIN_EVENT_BADGE_EARNED, LeaderboardId: "lb"}) {
+		t.Fatal("should only match the listed events")
+	}
+
+	if o.Matches(&DomainEvent{Type: DOMAIN_EVENT_BADGE_EARNED, LeaderboardId: "other"}) {
+		t.Fatal("should only match its leaderboard")
+	}
+}
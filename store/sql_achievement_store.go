@@ -0,0 +1,90 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlAchievementStore struct {
+	*SqlStore
+}
+
+func NewSqlAchievementStore(sqlStore *SqlStore) AchievementStore {
+	as := &SqlAchievementStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Achievement{}, "Achievements").SetKeys(false, "LeaderboardId", "Username", "BadgeId")
+	table.ColMap("LeaderboardId").SetMaxSize(26)
+	table.ColMap("Username").SetMaxSize(128)
+	table.ColMap("BadgeId").SetMaxSize(64)
+
+	return as
+}
+
+func (as SqlAchievementStore) CreateIndexesIfNotExists() {
+}
+
+func (as SqlAchievementStore) Save(achievement *model.Achievement) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if err := as.GetMaster().Insert(achievement); err != nil {
+			result.Err = errors.New("Error saving achievement, username=" + achievement.Username + ", badge_id=" + achievement.BadgeId + ", " + err.Error())
+		} else {
+			result.Data = achievement
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (as SqlAchievementStore) GetForUser(leaderboardId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		achievements := []*model.Achievement{}
+
+		if _, err := as.GetMaster().Select(&achievements, "SELECT * FROM Achievements WHERE LeaderboardId = :Id AND Username = :Username ORDER BY EarnedAt", map[string]interface{}{"Id": leaderboardId, "Username": username}); err != nil {
+			result.Err = errors.New("Error getting achievements, username=" + username + ", " + err.Error())
+		} else {
+			result.Data = achievements
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (as SqlAchievementStore) GetForLeaderboard(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		achievements := []*model.Achievement{}
+
+		if _, err := as.GetMaster().Select(&achievements, "SELECT * FROM Achievements WHERE LeaderboardId = :Id ORDER BY EarnedAt", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting achievements, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = achievements
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlApiTokenStore struct {
+	*SqlStore
+}
+
+func NewSqlApiTokenStore(sqlStore *SqlStore) ApiTokenStore {
+	ts := &SqlApiTokenStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.ApiToken{}, "ApiTokens").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("Name").SetMaxSize(64)
+	table.ColMap("Role").SetMaxSize(32)
+	table.ColMap("Hash").SetMaxSize(64).SetUnique(true)
+
+	return ts
+}
+
+func (ts SqlApiTokenStore) CreateIndexesIfNotExists() {
+}
+
+func (ts SqlApiTokenStore) Save(token *model.ApiToken) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if err := token.IsValid(); err != nil {
+			result.Err = err
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		token.PreSave()
+
+		if err := ts.GetMaster().Insert(token); err != nil {
+			result.Err = errors.New("Error saving api token, name=" + token.Name + ", " + err.Error())
+		} else {
+			result.Data = token
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlApiTokenStore) GetByHash(hash string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		token := model.ApiToken{}
+
+		if err := ts.GetMaster().SelectOne(&token, "SELECT * FROM ApiTokens WHERE Hash = :Hash", map[string]interface{}{"Hash": hash}); err != nil {
+			result.Err = errors.New("Error getting api token, " + err.Error())
+		} else {
+			result.Data = &token
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlApiTokenStore) GetAll() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		tokens := []*model.ApiToken{}
+
+		if _, err := ts.GetMaster().Select(&tokens, "SELECT * FROM ApiTokens ORDER BY CreateAt"); err != nil {
+			result.Err = errors.New("Error getting api tokens, " + err.Error())
+		} else {
+			result.Data = tokens
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlApiTokenStore) UpdateLastUsed(id string, at int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ts.GetMaster().Exec("UPDATE ApiTokens SET LastUsedAt = :LastUsedAt WHERE Id = :Id", map[string]interface{}{"LastUsedAt": at, "Id": id}); err != nil {
+			result.Err = errors.New("Error updating api token, id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlApiTokenStore) Delete(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ts.GetMaster().Exec("DELETE FROM ApiTokens WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error deleting api token, id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlArchiveStore struct {
+	*SqlStore
+}
+
+func NewSqlArchiveStore(sqlStore *SqlStore) ArchiveStore {
+	as := &SqlArchiveStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.ArchivedDelivery{}, "ArchivedDeliveries").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("DeliveryId").SetMaxSize(64)
+	table.ColMap("EventType").SetMaxSize(64)
+	table.ColMap("Action").SetMaxSize(64)
+	table.ColMap("Username").SetMaxSize(128)
+	table.ColMap("Repository").SetMaxSize(256)
+
+	return as
+}
+
+func (as SqlArchiveStore) CreateIndexesIfNotExists() {
+	as.CreateIndexIfNotExists("idx_archiveddeliveries_event_type", "ArchivedDeliveries", "EventType")
+	as.CreateIndexIfNotExists("idx_archiveddeliveries_username", "ArchivedDeliveries", "Username")
+	as.CreateIndexIfNotExists("idx_archiveddeliveries_create_at", "ArchivedDeliveries", "CreateAt")
+}
+
+func (as SqlArchiveStore) Save(delivery *model.ArchivedDelivery) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery.PreSave()
+
+		if err := as.GetMaster().Insert(delivery); err != nil {
+			result.Err = errors.New("Error archiving delivery, delivery_id=" + delivery.DeliveryId + ", " + err.Error())
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (as SqlArchiveStore) Get(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery := model.ArchivedDelivery{}
+
+		if err := as.GetMaster().SelectOne(&delivery, "SELECT * FROM ArchivedDeliveries WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error getting archived delivery, id=" + id + ", " + err.Error())
+		} else {
+			result.Data = &delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetByIds returns the archived deliveries with the ids, oldest first
+func (as SqlArchiveStore) GetByIds(ids []string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		deliveries := []*model.ArchivedDelivery{}
+
+		if len(ids) > 0 {
+			params := make(map[string]interface{}, len(ids))
+			keys := make([]string, len(ids))
+			for i, id := range ids {
+				key := "Id" + strconv.Itoa(i)
+				keys[i] = ":" + key
+				params[key] = id
+			}
+
+			if _, err := as.GetMaster().Select(&deliveries, "SELECT * FROM ArchivedDeliveries WHERE Id IN ("+strings.Join(keys, ", ")+") ORDER BY CreateAt, Id", params); err != nil {
+				result.Err = errors.New("Error getting archived deliveries, " + err.Error())
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Search returns a page of the archived deliveries matching the search,
+// newest first
+func (as SqlArchiveStore) Search(search *model.DeliverySearch) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		deliveries := []*model.ArchivedDelivery{}
+
+		conditions := []string{"1 = 1"}
+		if len(search.EventType) > 0 {
+			conditions = append(conditions, "EventType = :EventType")
+		}
+		if len(search.Username) > 0 {
+			conditions = append(conditions, "Username = :Username")
+		}
+		if search.Since > 0 {
+			conditions = append(conditions, "CreateAt >= :Since")
+		}
+		if search.Until > 0 {
+			conditions = append(conditions, "CreateAt <= :Until")
+		}
+
+		query := "SELECT * FROM ArchivedDeliveries WHERE " + strings.Join(conditions, " AND ") + " ORDER BY CreateAt DESC, Id DESC LIMIT :Limit OFFSET :Offset"
+
+		if _, err := as.GetMaster().Select(&deliveries, query, map[string]interface{}{
+			"EventType": search.EventType,
+			"Username":  strings.ToLower(search.Username),
+			"Since":     search.Since,
+			"Until":     search.Until,
+			"Limit":     search.PerPage,
+			"Offset":    search.Page * search.PerPage,
+		}); err != nil {
+			result.Err = errors.New("Error searching archived deliveries, " + err.Error())
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
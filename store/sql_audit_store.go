@@ -0,0 +1,82 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlAuditStore struct {
+	*SqlStore
+}
+
+func NewSqlAuditStore(sqlStore *SqlStore) AuditStore {
+	as := &SqlAuditStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Audit{}, "Audits").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("Actor").SetMaxSize(128)
+	table.ColMap("Action").SetMaxSize(64)
+	table.ColMap("LeaderboardId").SetMaxSize(26)
+	table.ColMap("Username").SetMaxSize(128)
+	table.ColMap("Reason").SetMaxSize(512)
+	table.ColMap("RelatedId").SetMaxSize(26)
+
+	return as
+}
+
+func (as SqlAuditStore) CreateIndexesIfNotExists() {
+	as.CreateIndexIfNotExists("idx_audits_username", "Audits", "Username")
+	as.CreateIndexIfNotExists("idx_audits_create_at", "Audits", "CreateAt")
+}
+
+func (as SqlAuditStore) Save(audit *model.Audit) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		audit.PreSave()
+
+		if err := as.GetMaster().Insert(audit); err != nil {
+			result.Err = errors.New("Error saving audit, action=" + audit.Action + ", " + err.Error())
+		} else {
+			result.Data = audit
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Get returns the most recent audits, only the ones concerning username if it isn't empty
+func (as SqlAuditStore) Get(username string, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		audits := []*model.Audit{}
+
+		query := "SELECT * FROM Audits ORDER BY CreateAt DESC LIMIT :Limit"
+		if len(username) > 0 {
+			query = "SELECT * FROM Audits WHERE Username = :Username ORDER BY CreateAt DESC LIMIT :Limit"
+		}
+
+		if _, err := as.GetMaster().Select(&audits, query, map[string]interface{}{"Username": username, "Limit": limit}); err != nil {
+			result.Err = errors.New("Error getting audits, " + err.Error())
+		} else {
+			result.Data = audits
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
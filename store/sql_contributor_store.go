@@ -0,0 +1,238 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlContributorStore struct {
+	*SqlStore
+}
+
+func NewSqlContributorStore(sqlStore *SqlStore) ContributorStore {
+	cs := &SqlContributorStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Contributor{}, "Contributors").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("Name").SetMaxSize(128).SetUnique(true)
+	table.ColMap("Emails").SetMaxSize(1024)
+
+	accounts := db.AddTableWithName(model.ContributorAccount{}, "ContributorAccounts").SetKeys(false, "Provider", "Login")
+	accounts.ColMap("ContributorId").SetMaxSize(26)
+	accounts.ColMap("Provider").SetMaxSize(32)
+	accounts.ColMap("Login").SetMaxSize(128)
+
+	return cs
+}
+
+func (cs SqlContributorStore) CreateIndexesIfNotExists() {
+	cs.CreateIndexIfNotExists("idx_contributoraccounts_contributor_id", "ContributorAccounts", "ContributorId")
+}
+
+func (cs SqlContributorStore) Save(contributor *model.Contributor) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(contributor.Id) > 0 {
+			result.Err = errors.New("Cannot save existing contributor, contributor_id=" + contributor.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		contributor.PreSave()
+		if err := contributor.IsValid(); err != nil {
+			result.Err = err
+		} else if err := cs.GetMaster().Insert(contributor); err != nil {
+			result.Err = errors.New("Error saving contributor, name=" + contributor.Name + ", " + err.Error())
+		} else {
+			result.Data = contributor
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (cs SqlContributorStore) Get(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if obj, err := cs.GetMaster().Get(model.Contributor{}, id); err != nil {
+			result.Err = errors.New("Error getting contributor, contributor_id=" + id + ", " + err.Error())
+		} else if obj == nil {
+			result.Err = errors.New("Missing contributor, contributor_id=" + id)
+		} else {
+			contributor := obj.(*model.Contributor)
+
+			if _, err := cs.GetMaster().Select(&contributor.Accounts, "SELECT * FROM ContributorAccounts WHERE ContributorId = :Id", map[string]interface{}{"Id": id}); err != nil {
+				result.Err = errors.New("Error getting contributor accounts, contributor_id=" + id + ", " + err.Error())
+			} else {
+				result.Data = contributor
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns every contributor without their accounts, see GetAllAccounts
+func (cs SqlContributorStore) GetAll() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		contributors := []*model.Contributor{}
+
+		if _, err := cs.GetMaster().Select(&contributors, "SELECT * FROM Contributors ORDER BY Name"); err != nil {
+			result.Err = errors.New("Error getting contributors, " + err.Error())
+		} else {
+			result.Data = contributors
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (cs SqlContributorStore) GetAllAccounts() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		accounts := []*model.ContributorAccount{}
+
+		if _, err := cs.GetMaster().Select(&accounts, "SELECT * FROM ContributorAccounts"); err != nil {
+			result.Err = errors.New("Error getting contributor accounts, " + err.Error())
+		} else {
+			result.Data = accounts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (cs SqlContributorStore) LinkAccount(account *model.ContributorAccount) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		account.PreSave()
+		if err := account.IsValid(); err != nil {
+			result.Err = err
+		} else if err := cs.GetMaster().Insert(account); err != nil {
+			result.Err = errors.New("Error linking account, login=" + account.Login + ", " + err.Error())
+		} else {
+			result.Data = account
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (cs SqlContributorStore) UnlinkAccount(contributorId string, provider string, login string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := cs.GetMaster().Exec("DELETE FROM ContributorAccounts WHERE ContributorId = :Id AND Provider = :Provider AND Login = :Login",
+			map[string]interface{}{"Id": contributorId, "Provider": provider, "Login": login}); err != nil {
+			result.Err = errors.New("Error unlinking account, login=" + login + ", " + err.Error())
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = errors.New("Account is not linked to contributor, login=" + login)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Merge moves the accounts and emails of the contributor with sourceId onto
+// the contributor with targetId and deletes the source. Ledger history is kept
+// per account, so nothing is lost by merging.
+func (cs SqlContributorStore) Merge(targetId string, sourceId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		target := model.Contributor{}
+		source := model.Contributor{}
+
+		if targetId == sourceId {
+			result.Err = errors.New("Cannot merge a contributor into itself, contributor_id=" + targetId)
+		} else if err := cs.GetMaster().SelectOne(&target, "SELECT * FROM Contributors WHERE Id = :Id", map[string]interface{}{"Id": targetId}); err != nil {
+			result.Err = errors.New("Error getting contributor, contributor_id=" + targetId + ", " + err.Error())
+		} else if err := cs.GetMaster().SelectOne(&source, "SELECT * FROM Contributors WHERE Id = :Id", map[string]interface{}{"Id": sourceId}); err != nil {
+			result.Err = errors.New("Error getting contributor, contributor_id=" + sourceId + ", " + err.Error())
+		} else if transaction, err := cs.GetMaster().Begin(); err != nil {
+			result.Err = errors.New("Error merging contributors, " + err.Error())
+		} else {
+			for _, email := range source.Emails {
+				found := false
+				for _, existing := range target.Emails {
+					if existing == email {
+						found = true
+					}
+				}
+
+				if !found {
+					target.Emails = append(target.Emails, email)
+				}
+			}
+			target.PreUpdate()
+
+			if _, err := transaction.Exec("UPDATE ContributorAccounts SET ContributorId = :TargetId WHERE ContributorId = :SourceId", map[string]interface{}{"TargetId": targetId, "SourceId": sourceId}); err != nil {
+				transaction.Rollback()
+				result.Err = errors.New("Error moving contributor accounts, " + err.Error())
+			} else if _, err := transaction.Update(&target); err != nil {
+				transaction.Rollback()
+				result.Err = errors.New("Error updating contributor, " + err.Error())
+			} else if _, err := transaction.Exec("DELETE FROM Contributors WHERE Id = :Id", map[string]interface{}{"Id": sourceId}); err != nil {
+				transaction.Rollback()
+				result.Err = errors.New("Error deleting merged contributor, " + err.Error())
+			} else if err := transaction.Commit(); err != nil {
+				result.Err = errors.New("Error merging contributors, " + err.Error())
+			} else {
+				result.Data = &target
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -59,20 +59,203 @@ func (ls SqlLeaderboardEntryStore) Save(entry *model.LeaderboardEntry) StoreChan
 	return storeChannel
 }
 
-func (ls SqlLeaderboardEntryStore) IncrementPoints(username string, leaderboardId string) StoreChannel {
+// Award saves the ledger entry and adds its points, earned when it was
+// created, to the user's lifetime and active points in one transaction,
+// decaying the active points with the given half life
+func (ls SqlLeaderboardEntryStore) Award(ledgerEntry *model.LedgerEntry, halfLife int64) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
-		if _, err := ls.GetMaster().Exec("UPDATE LeaderboardEntry SET Points = Points + 1 WHERE Username = :Username AND LeaderboardId = :Id", map[string]interface{}{"Username": username, "Id": leaderboardId}); err != nil {
-			result.Err = errors.New("Error incrementing points, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		if len(ledgerEntry.Id) > 0 {
+			result.Err = errors.New("Cannot award existing ledger entry, id=" + ledgerEntry.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		transaction, err := ls.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error awarding points, leaderboard_id=" + ledgerEntry.LeaderboardId + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		ledgerEntry.PreSave()
+
+		entry := model.LeaderboardEntry{}
+		params := map[string]interface{}{"Username": ledgerEntry.Username, "Id": ledgerEntry.LeaderboardId}
+
+		if err := transaction.SelectOne(&entry, "SELECT * FROM LeaderboardEntry WHERE Username = :Username AND LeaderboardId = :Id FOR UPDATE", params); err != nil {
+			result.Err = errors.New("Error awarding points, leaderboard_id=" + ledgerEntry.LeaderboardId + ", " + err.Error())
+		} else if err := transaction.Insert(ledgerEntry); err != nil {
+			result.Err = errors.New("Error awarding points, username=" + ledgerEntry.Username + ", " + err.Error())
+		} else {
+			entry.AddActivePoints(ledgerEntry.Points, ledgerEntry.CreateAt, halfLife)
+
+			params["Points"] = ledgerEntry.Points
+			params["ActivePoints"] = entry.ActivePoints
+			params["ActiveAt"] = entry.ActiveAt
+
+			if _, err := transaction.Exec("UPDATE LeaderboardEntry SET Points = Points + :Points, ActivePoints = :ActivePoints, ActiveAt = :ActiveAt WHERE Username = :Username AND LeaderboardId = :Id", params); err != nil {
+				result.Err = errors.New("Error awarding points, leaderboard_id=" + ledgerEntry.LeaderboardId + ", " + err.Error())
+			}
+		}
+
+		if result.Err != nil {
+			transaction.Rollback()
+			ledgerEntry.Id = ""
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = errors.New("Error awarding points, leaderboard_id=" + ledgerEntry.LeaderboardId + ", " + err.Error())
+			ledgerEntry.Id = ""
+		} else {
+			result.Data = ledgerEntry
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+
+	}()
+
+	return storeChannel
+}
+
+// BackfillActivePoints computes the active points of entries that have
+// lifetime points but no active points yet, such as ones created before
+// active points were tracked, from their ledger history. Points awarded
+// before the ledger was kept count through their legacy ledger entry.
+func (ls SqlLeaderboardEntryStore) BackfillActivePoints(leaderboardId string, halfLife int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LeaderboardEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT * FROM LeaderboardEntry WHERE LeaderboardId = :Id AND ActiveAt = 0 AND Points != 0", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error backfilling active points, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		}
+
+		for _, entry := range entries {
+			if result.Err != nil {
+				break
+			}
+
+			history := []*model.LedgerEntry{}
+			if _, err := ls.GetMaster().Select(&history, "SELECT * FROM LedgerEntries WHERE LeaderboardId = :Id AND Username = :Username", map[string]interface{}{"Id": leaderboardId, "Username": entry.Username}); err != nil {
+				result.Err = errors.New("Error backfilling active points, username=" + entry.Username + ", " + err.Error())
+				break
+			}
+
+			for _, ledgerEntry := range history {
+				entry.AddActivePoints(ledgerEntry.Points, ledgerEntry.CreateAt, halfLife)
+			}
+
+			if _, err := ls.GetMaster().Exec("UPDATE LeaderboardEntry SET ActivePoints = :ActivePoints, ActiveAt = :ActiveAt WHERE Username = :Username AND LeaderboardId = :Id",
+				map[string]interface{}{"ActivePoints": entry.ActivePoints, "ActiveAt": entry.ActiveAt, "Username": entry.Username, "Id": leaderboardId}); err != nil {
+				result.Err = errors.New("Error backfilling active points, username=" + entry.Username + ", " + err.Error())
+			}
 		}
 
 		storeChannel <- result
 		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Rebuild replaces the leaderboard's entries with ones rebuilt from its
+// ledger and saves the rescored ledger entries in one transaction. Users
+// missing from entries are left with no points. Nothing changes if the
+// ledger no longer holds ledgerCount entries, since that means points were
+// awarded while the entries were being rebuilt. The ledger is locked against
+// new entries after the leaderboard entries, the order Award locks them in,
+// until the rebuild is done.
+func (ls SqlLeaderboardEntryStore) Rebuild(leaderboardId string, entries []*model.LeaderboardEntry, rescored []*model.LedgerEntry, ledgerCount int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := ls.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error rebuilding leaderboard entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		existing := []*model.LeaderboardEntry{}
+
+		if _, err := transaction.Select(&existing, "SELECT * FROM LeaderboardEntry WHERE LeaderboardId = :Id FOR UPDATE", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error rebuilding leaderboard entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else if _, err := transaction.Exec("LOCK TABLE LedgerEntries IN SHARE MODE"); err != nil {
+			result.Err = errors.New("Error rebuilding leaderboard entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else if count, err := transaction.SelectInt("SELECT COUNT(*) FROM LedgerEntries WHERE LeaderboardId = :Id", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error rebuilding leaderboard entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else if count != ledgerCount {
+			result.Err = errors.New("Error rebuilding leaderboard entries, points were awarded during the rebuild, leaderboard_id=" + leaderboardId)
+		}
+
+		for _, ledgerEntry := range rescored {
+			if result.Err != nil {
+				break
+			}
 
+			if _, err := transaction.Exec("UPDATE LedgerEntries SET Points = :Points WHERE Id = :Id", map[string]interface{}{"Points": ledgerEntry.Points, "Id": ledgerEntry.Id}); err != nil {
+				result.Err = errors.New("Error rescoring ledger entry, id=" + ledgerEntry.Id + ", " + err.Error())
+			}
+		}
+
+		rebuilt := make(map[string]bool, len(entries))
+		for _, entry := range existing {
+			rebuilt[entry.Username] = false
+		}
+
+		for _, entry := range entries {
+			if result.Err != nil {
+				break
+			}
+
+			if _, ok := rebuilt[entry.Username]; ok {
+				if _, err := transaction.Exec("UPDATE LeaderboardEntry SET Points = :Points, ActivePoints = :ActivePoints, ActiveAt = :ActiveAt WHERE Username = :Username AND LeaderboardId = :Id",
+					map[string]interface{}{"Points": entry.Points, "ActivePoints": entry.ActivePoints, "ActiveAt": entry.ActiveAt, "Username": entry.Username, "Id": leaderboardId}); err != nil {
+					result.Err = errors.New("Error rebuilding leaderboard entry, username=" + entry.Username + ", " + err.Error())
+				}
+			} else if err := transaction.Insert(entry); err != nil {
+				result.Err = errors.New("Error rebuilding leaderboard entry, username=" + entry.Username + ", " + err.Error())
+			}
+
+			rebuilt[entry.Username] = true
+		}
+
+		for username, ok := range rebuilt {
+			if result.Err != nil {
+				break
+			}
+
+			if ok {
+				continue
+			}
+
+			if _, err := transaction.Exec("UPDATE LeaderboardEntry SET Points = 0, ActivePoints = 0, ActiveAt = 0 WHERE Username = :Username AND LeaderboardId = :Id", map[string]interface{}{"Username": username, "Id": leaderboardId}); err != nil {
+				result.Err = errors.New("Error rebuilding leaderboard entry, username=" + username + ", " + err.Error())
+			}
+		}
+
+		if result.Err != nil {
+			transaction.Rollback()
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = errors.New("Error rebuilding leaderboard entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
 	}()
 
 	return storeChannel
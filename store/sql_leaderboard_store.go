@@ -17,6 +17,11 @@ func NewSqlLeaderboardStore(sqlStore *SqlStore) LeaderboardStore {
 	table := db.AddTableWithName(model.Leaderboard{}, "Leaderboards").SetKeys(false, "Id")
 	table.ColMap("Id").SetMaxSize(26)
 	table.ColMap("Name").SetMaxSize(64).SetUnique(true)
+	table.ColMap("Visibility").SetMaxSize(16)
+
+	viewers := db.AddTableWithName(model.LeaderboardViewer{}, "LeaderboardViewers").SetKeys(false, "LeaderboardId", "Username")
+	viewers.ColMap("LeaderboardId").SetMaxSize(26)
+	viewers.ColMap("Username").SetMaxSize(128)
 
 	return ls
 }
@@ -91,11 +96,147 @@ func (ls SqlLeaderboardStore) GetByName(name string) StoreChannel {
 
 		leaderboard := model.Leaderboard{}
 
-		if err := ls.GetMaster().SelectOne(&leaderboard, "SELECT * FROM Leaderboards WHERE Leaderboardname = :Name", map[string]interface{}{"Name": name}); err != nil {
+		if err := ls.GetMaster().SelectOne(&leaderboard, "SELECT * FROM Leaderboards WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
 			result.Err = errors.New("Error getting leaderboard by name, name=" + name + ", " + err.Error())
+		} else {
+			result.Data = &leaderboard
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLeaderboardStore) GetAll() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		leaderboards := []*model.Leaderboard{}
+
+		if _, err := ls.GetMaster().Select(&leaderboards, "SELECT * FROM Leaderboards ORDER BY Name"); err != nil {
+			result.Err = errors.New("Error getting leaderboards, " + err.Error())
+		} else {
+			result.Data = leaderboards
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLeaderboardStore) UpdateVisibility(id string, visibility string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if !model.IsValidVisibility(visibility) {
+			result.Err = errors.New("Invalid leaderboard visibility, visibility=" + visibility)
+		} else if _, err := ls.GetMaster().Exec("UPDATE Leaderboards SET Visibility = :Visibility WHERE Id = :Id", map[string]interface{}{"Visibility": visibility, "Id": id}); err != nil {
+			result.Err = errors.New("Error updating leaderboard visibility, leaderboard_id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLeaderboardStore) SaveViewer(viewer *model.LeaderboardViewer) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(viewer.Username) == 0 {
+			result.Err = errors.New("Invalid leaderboard viewer username")
+			storeChannel <- result
+			close(storeChannel)
+			return
 		}
 
-		result.Data = &leaderboard
+		viewer.CreateAt = model.GetMillis()
+
+		if count, err := ls.GetMaster().SelectInt("SELECT COUNT(*) FROM LeaderboardViewers WHERE LeaderboardId = :LeaderboardId AND Username = :Username", map[string]interface{}{"LeaderboardId": viewer.LeaderboardId, "Username": viewer.Username}); err != nil {
+			result.Err = errors.New("Error saving leaderboard viewer, username=" + viewer.Username + ", " + err.Error())
+		} else if count > 0 {
+			result.Data = viewer
+		} else if err := ls.GetMaster().Insert(viewer); err != nil {
+			result.Err = errors.New("Error saving leaderboard viewer, username=" + viewer.Username + ", " + err.Error())
+		} else {
+			result.Data = viewer
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLeaderboardStore) RemoveViewer(leaderboardId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ls.GetMaster().Exec("DELETE FROM LeaderboardViewers WHERE LeaderboardId = :LeaderboardId AND Username = :Username", map[string]interface{}{"LeaderboardId": leaderboardId, "Username": username}); err != nil {
+			result.Err = errors.New("Error removing leaderboard viewer, username=" + username + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLeaderboardStore) GetViewers(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		viewers := []*model.LeaderboardViewer{}
+
+		if _, err := ls.GetMaster().Select(&viewers, "SELECT * FROM LeaderboardViewers WHERE LeaderboardId = :LeaderboardId ORDER BY Username", map[string]interface{}{"LeaderboardId": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting leaderboard viewers, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = viewers
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IsViewer returns whether the user has been given access to the leaderboard
+func (ls SqlLeaderboardStore) IsViewer(leaderboardId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := ls.GetMaster().SelectInt("SELECT COUNT(*) FROM LeaderboardViewers WHERE LeaderboardId = :LeaderboardId AND Username = :Username", map[string]interface{}{"LeaderboardId": leaderboardId, "Username": username}); err != nil {
+			result.Err = errors.New("Error checking leaderboard viewer, username=" + username + ", " + err.Error())
+		} else {
+			result.Data = count > 0
+		}
 
 		storeChannel <- result
 		close(storeChannel)
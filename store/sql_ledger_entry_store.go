@@ -0,0 +1,339 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlLedgerEntryStore struct {
+	*SqlStore
+}
+
+func NewSqlLedgerEntryStore(sqlStore *SqlStore) LedgerEntryStore {
+	ls := &SqlLedgerEntryStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.LedgerEntry{}, "LedgerEntries").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("LeaderboardId").SetMaxSize(26)
+	table.ColMap("Username").SetMaxSize(128)
+	table.ColMap("Type").SetMaxSize(32)
+	table.ColMap("Repository").SetMaxSize(256)
+	table.ColMap("Title").SetMaxSize(512)
+	table.ColMap("Url").SetMaxSize(512)
+	table.ColMap("RelatedId").SetMaxSize(26)
+	table.ColMap("Reason").SetMaxSize(512)
+
+	return ls
+}
+
+func (ls SqlLedgerEntryStore) CreateIndexesIfNotExists() {
+	ls.CreateIndexIfNotExists("idx_ledgerentries_leaderboard_id", "LedgerEntries", "LeaderboardId")
+	ls.CreateIndexIfNotExists("idx_ledgerentries_username", "LedgerEntries", "Username")
+	ls.CreateIndexIfNotExists("idx_ledgerentries_number", "LedgerEntries", "Number")
+	ls.CreateIndexIfNotExists("idx_ledgerentries_related_id", "LedgerEntries", "RelatedId")
+}
+
+func (ls SqlLedgerEntryStore) Save(entry *model.LedgerEntry) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(entry.Id) > 0 {
+			result.Err = errors.New("Cannot save existing ledger entry, id=" + entry.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		entry.PreSave()
+
+		if err := ls.GetMaster().Insert(entry); err != nil {
+			result.Err = errors.New("Error saving ledger entry, username=" + entry.Username + ", " + err.Error())
+		} else {
+			result.Data = entry
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) GetByNumber(leaderboardId string, repository string, number int, entryType string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entry := model.LedgerEntry{}
+
+		if err := ls.GetMaster().SelectOne(&entry,
+			`SELECT * FROM LedgerEntries
+			WHERE LeaderboardId = :Id AND Repository = :Repository AND Number = :Number AND Type = :Type
+			ORDER BY CreateAt DESC LIMIT 1`,
+			map[string]interface{}{"Id": leaderboardId, "Repository": repository, "Number": number, "Type": entryType}); err != nil {
+			result.Err = errors.New("Error getting ledger entry by number, number=" + strconv.Itoa(number) + ", " + err.Error())
+		} else {
+			result.Data = &entry
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) GetByTitle(leaderboardId string, repository string, title string, entryType string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entry := model.LedgerEntry{}
+
+		if err := ls.GetMaster().SelectOne(&entry,
+			`SELECT * FROM LedgerEntries
+			WHERE LeaderboardId = :Id AND Repository = :Repository AND Title = :Title AND Type = :Type
+			ORDER BY CreateAt DESC LIMIT 1`,
+			map[string]interface{}{"Id": leaderboardId, "Repository": repository, "Title": title, "Type": entryType}); err != nil {
+			result.Err = errors.New("Error getting ledger entry by title, title=" + title + ", " + err.Error())
+		} else {
+			result.Data = &entry
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetByUrl returns the leaderboard's entries of the type for the url, which
+// identifies the pull request, review or issue they were awarded for
+func (ls SqlLedgerEntryStore) GetByUrl(leaderboardId string, url string, entryType string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries,
+			`SELECT * FROM LedgerEntries
+			WHERE LeaderboardId = :Id AND Url = :Url AND Type = :Type
+			ORDER BY CreateAt`,
+			map[string]interface{}{"Id": leaderboardId, "Url": url, "Type": entryType}); err != nil {
+			result.Err = errors.New("Error getting ledger entries by url, url=" + url + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) GetByRelatedId(relatedId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT * FROM LedgerEntries WHERE RelatedId = :RelatedId ORDER BY CreateAt", map[string]interface{}{"RelatedId": relatedId}); err != nil {
+			result.Err = errors.New("Error getting related ledger entries, related_id=" + relatedId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) GetForUser(leaderboardId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT * FROM LedgerEntries WHERE LeaderboardId = :Id AND Username = :Username ORDER BY CreateAt DESC", map[string]interface{}{"Id": leaderboardId, "Username": username}); err != nil {
+			result.Err = errors.New("Error getting ledger entries for user, username=" + username + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) GetRecent(leaderboardId string, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT * FROM LedgerEntries WHERE LeaderboardId = :Id ORDER BY CreateAt DESC LIMIT :Limit", map[string]interface{}{"Id": leaderboardId, "Limit": limit}); err != nil {
+			result.Err = errors.New("Error getting recent ledger entries, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetTotals sums the points each user earned between since and until,
+// returning them as leaderboard entries sorted by points
+func (ls SqlLedgerEntryStore) GetTotals(leaderboardId string, since int64, until int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LeaderboardEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries,
+			`SELECT LeaderboardId, Username, SUM(Points) AS Points FROM LedgerEntries
+			WHERE LeaderboardId = :Id AND CreateAt >= :Since AND CreateAt < :Until
+			GROUP BY LeaderboardId, Username
+			ORDER BY Points DESC`,
+			map[string]interface{}{"Id": leaderboardId, "Since": since, "Until": until}); err != nil {
+			result.Err = errors.New("Error getting point totals, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetTimeline returns every ledger entry of the leaderboard in chronological
+// order, with only the username, type, points and time filled in
+func (ls SqlLedgerEntryStore) GetTimeline(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT Username, Type, Points, CreateAt FROM LedgerEntries WHERE LeaderboardId = :Id ORDER BY CreateAt", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting ledger timeline, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetHistory returns every ledger entry of the leaderboard in chronological
+// order
+func (ls SqlLedgerEntryStore) GetHistory(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries, "SELECT * FROM LedgerEntries WHERE LeaderboardId = :Id ORDER BY CreateAt", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting ledger history, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetFirstContributions returns one entry per user on the leaderboard, with
+// only the username and the time of their first contribution filled in
+func (ls SqlLedgerEntryStore) GetFirstContributions(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		entries := []*model.LedgerEntry{}
+
+		if _, err := ls.GetMaster().Select(&entries,
+			`SELECT Username, MIN(CreateAt) AS CreateAt FROM LedgerEntries
+			WHERE LeaderboardId = :Id AND Type IN (:PullRequestMerged, :Review, :IssueOpened, :Commit)
+			GROUP BY Username`,
+			map[string]interface{}{"Id": leaderboardId, "PullRequestMerged": model.LEDGER_TYPE_PULL_REQUEST_MERGED, "Review": model.LEDGER_TYPE_REVIEW, "IssueOpened": model.LEDGER_TYPE_ISSUE_OPENED, "Commit": model.LEDGER_TYPE_COMMIT}); err != nil {
+			result.Err = errors.New("Error getting first contributions, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = entries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ls SqlLedgerEntryStore) Get(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if obj, err := ls.GetMaster().Get(model.LedgerEntry{}, id); err != nil {
+			result.Err = errors.New("Error getting ledger entry, id=" + id + ", " + err.Error())
+		} else if obj == nil {
+			result.Err = errors.New("Missing ledger entry, id=" + id)
+		} else {
+			result.Data = obj.(*model.LedgerEntry)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlQueueStore struct {
+	*SqlStore
+}
+
+func NewSqlQueueStore(sqlStore *SqlStore) QueueStore {
+	qs := &SqlQueueStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.QueuedEvent{}, "QueuedEvents").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("EventType").SetMaxSize(64)
+	table.ColMap("DeliveryId").SetMaxSize(64)
+	table.ColMap("PartitionKey").SetMaxSize(128)
+	table.ColMap("Status").SetMaxSize(16)
+	table.ColMap("LastError").SetMaxSize(512)
+
+	return qs
+}
+
+func (qs SqlQueueStore) CreateIndexesIfNotExists() {
+	qs.CreateIndexIfNotExists("idx_queuedevents_status", "QueuedEvents", "Status")
+	qs.CreateIndexIfNotExists("idx_queuedevents_create_at", "QueuedEvents", "CreateAt")
+}
+
+func (qs SqlQueueStore) Save(event *model.QueuedEvent) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		event.PreSave()
+
+		if err := qs.GetMaster().Insert(event); err != nil {
+			result.Err = errors.New("Error queueing event, delivery_id=" + event.DeliveryId + ", " + err.Error())
+		} else {
+			result.Data = event
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (qs SqlQueueStore) Update(event *model.QueuedEvent) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := qs.GetMaster().Update(event); err != nil {
+			result.Err = errors.New("Error updating queued event, id=" + event.Id + ", " + err.Error())
+		} else {
+			result.Data = event
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (qs SqlQueueStore) Get(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		event := model.QueuedEvent{}
+
+		if err := qs.GetMaster().SelectOne(&event, "SELECT * FROM QueuedEvents WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error getting queued event, id=" + id + ", " + err.Error())
+		} else {
+			result.Data = &event
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetUnfinished returns a page of the events that are pending or being
+// processed, oldest first
+func (qs SqlQueueStore) GetUnfinished(offset int, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		events := []*model.QueuedEvent{}
+
+		if _, err := qs.GetMaster().Select(&events,
+			`SELECT * FROM QueuedEvents
+			WHERE Status = :Pending OR Status = :Processing
+			ORDER BY CreateAt, Id
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Pending": model.QUEUE_STATUS_PENDING, "Processing": model.QUEUE_STATUS_PROCESSING, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = errors.New("Error getting queued events, " + err.Error())
+		} else {
+			result.Data = events
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetByStatus returns the most recent events with the status, newest first
+func (qs SqlQueueStore) GetByStatus(status string, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		events := []*model.QueuedEvent{}
+
+		if _, err := qs.GetMaster().Select(&events, "SELECT * FROM QueuedEvents WHERE Status = :Status ORDER BY CreateAt DESC LIMIT :Limit", map[string]interface{}{"Status": status, "Limit": limit}); err != nil {
+			result.Err = errors.New("Error getting queued events, status=" + status + ", " + err.Error())
+		} else {
+			result.Data = events
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ResetProcessing returns events left processing, by a server that stopped
+// while processing them, to pending
+func (qs SqlQueueStore) ResetProcessing() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := qs.GetMaster().Exec("UPDATE QueuedEvents SET Status = :Pending WHERE Status = :Processing", map[string]interface{}{"Pending": model.QUEUE_STATUS_PENDING, "Processing": model.QUEUE_STATUS_PROCESSING}); err != nil {
+			result.Err = errors.New("Error resetting queued events, " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (qs SqlQueueStore) Delete(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := qs.GetMaster().Exec("DELETE FROM QueuedEvents WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error deleting queued event, id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,225 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlSeasonStore struct {
+	*SqlStore
+}
+
+func NewSqlSeasonStore(sqlStore *SqlStore) SeasonStore {
+	ss := &SqlSeasonStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Season{}, "Seasons").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("LeaderboardId").SetMaxSize(26)
+	table.ColMap("Name").SetMaxSize(64)
+
+	standings := db.AddTableWithName(model.SeasonStanding{}, "SeasonStandings").SetKeys(false, "SeasonId", "Username")
+	standings.ColMap("SeasonId").SetMaxSize(26)
+	standings.ColMap("Username").SetMaxSize(128)
+
+	return ss
+}
+
+func (ss SqlSeasonStore) CreateIndexesIfNotExists() {
+	ss.CreateIndexIfNotExists("idx_seasons_leaderboard_id", "Seasons", "LeaderboardId")
+}
+
+func (ss SqlSeasonStore) Save(season *model.Season) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(season.Id) > 0 {
+			result.Err = errors.New("Cannot save existing season, season_id=" + season.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		season.PreSave()
+
+		if err := ss.GetMaster().Insert(season); err != nil {
+			result.Err = errors.New("Error saving season, name=" + season.Name + ", " + err.Error())
+		} else {
+			result.Data = season
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSeasonStore) Get(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if obj, err := ss.GetMaster().Get(model.Season{}, id); err != nil {
+			result.Err = errors.New("Error getting season, season_id=" + id + ", " + err.Error())
+		} else if obj == nil {
+			result.Err = errors.New("Missing season, season_id=" + id)
+		} else {
+			result.Data = obj.(*model.Season)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetCurrent returns the leaderboard's open season, or no data and no error
+// if there is none
+func (ss SqlSeasonStore) GetCurrent(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		season := model.Season{}
+
+		if err := ss.GetMaster().SelectOne(&season, "SELECT * FROM Seasons WHERE LeaderboardId = :Id AND ClosedAt = 0 ORDER BY StartAt DESC LIMIT 1", map[string]interface{}{"Id": leaderboardId}); err == nil {
+			result.Data = &season
+		} else if err != sql.ErrNoRows {
+			result.Err = errors.New("Error getting current season, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSeasonStore) GetAll(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		seasons := []*model.Season{}
+
+		if _, err := ss.GetMaster().Select(&seasons, "SELECT * FROM Seasons WHERE LeaderboardId = :Id ORDER BY StartAt DESC", map[string]interface{}{"Id": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting seasons, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = seasons
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Close archives the season's final standings and marks it closed in one transaction
+func (ss SqlSeasonStore) Close(season *model.Season, standings []*model.SeasonStanding) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := ss.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error closing season, season_id=" + season.Id + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		for _, standing := range standings {
+			if err := transaction.Insert(standing); err != nil {
+				result.Err = errors.New("Error saving season standing, season_id=" + season.Id + ", " + err.Error())
+				break
+			}
+		}
+
+		if result.Err == nil {
+			season.ClosedAt = model.GetMillis()
+			if _, err := transaction.Update(season); err != nil {
+				result.Err = errors.New("Error closing season, season_id=" + season.Id + ", " + err.Error())
+			}
+		}
+
+		if result.Err != nil {
+			season.ClosedAt = 0
+			transaction.Rollback()
+		} else if err := transaction.Commit(); err != nil {
+			season.ClosedAt = 0
+			result.Err = errors.New("Error closing season, season_id=" + season.Id + ", " + err.Error())
+		} else {
+			result.Data = season
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSeasonStore) GetStandings(seasonId string, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		standings := []*model.SeasonStanding{}
+
+		if _, err := ss.GetMaster().Select(&standings, "SELECT * FROM SeasonStandings WHERE SeasonId = :Id ORDER BY Rank, Username LIMIT :Limit", map[string]interface{}{"Id": seasonId, "Limit": limit}); err != nil {
+			result.Err = errors.New("Error getting season standings, season_id=" + seasonId + ", " + err.Error())
+		} else {
+			result.Data = standings
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetStandingsForUser returns the user's final standing in each closed season of the leaderboard
+func (ss SqlSeasonStore) GetStandingsForUser(leaderboardId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		standings := []*model.SeasonStanding{}
+
+		if _, err := ss.GetMaster().Select(&standings,
+			`SELECT SeasonStandings.* FROM SeasonStandings, Seasons
+			WHERE SeasonStandings.SeasonId = Seasons.Id AND Seasons.LeaderboardId = :Id AND SeasonStandings.Username = :Username
+			ORDER BY Seasons.StartAt`,
+			map[string]interface{}{"Id": leaderboardId, "Username": username}); err != nil {
+			result.Err = errors.New("Error getting season standings for user, username=" + username + ", " + err.Error())
+		} else {
+			result.Data = standings
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlSessionStore struct {
+	*SqlStore
+}
+
+func NewSqlSessionStore(sqlStore *SqlStore) SessionStore {
+	ss := &SqlSessionStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Session{}, "Sessions").SetKeys(false, "Hash")
+	table.ColMap("Hash").SetMaxSize(64)
+	table.ColMap("Username").SetMaxSize(128)
+	table.ColMap("Role").SetMaxSize(32)
+
+	return ss
+}
+
+func (ss SqlSessionStore) CreateIndexesIfNotExists() {
+	ss.CreateIndexIfNotExists("idx_sessions_expire_at", "Sessions", "ExpireAt")
+}
+
+func (ss SqlSessionStore) Save(session *model.Session) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		session.PreSave()
+
+		if err := ss.GetMaster().Insert(session); err != nil {
+			result.Err = errors.New("Error saving session, username=" + session.Username + ", " + err.Error())
+		} else {
+			result.Data = session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSessionStore) GetByHash(hash string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		session := model.Session{}
+
+		if err := ss.GetMaster().SelectOne(&session, "SELECT * FROM Sessions WHERE Hash = :Hash", map[string]interface{}{"Hash": hash}); err != nil {
+			result.Err = errors.New("Error getting session, " + err.Error())
+		} else {
+			result.Data = &session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSessionStore) Delete(hash string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ss.GetMaster().Exec("DELETE FROM Sessions WHERE Hash = :Hash", map[string]interface{}{"Hash": hash}); err != nil {
+			result.Err = errors.New("Error deleting session, " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// RemoveExpired deletes the sessions that expired before the given time
+func (ss SqlSessionStore) RemoveExpired(before int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ss.GetMaster().Exec("DELETE FROM Sessions WHERE ExpireAt < :Before", map[string]interface{}{"Before": before}); err != nil {
+			result.Err = errors.New("Error removing expired sessions, " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
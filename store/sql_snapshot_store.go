@@ -0,0 +1,141 @@
+package store
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlSnapshotStore struct {
+	*SqlStore
+}
+
+func NewSqlSnapshotStore(sqlStore *SqlStore) SnapshotStore {
+	ss := &SqlSnapshotStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.RankSnapshot{}, "RankSnapshots").SetKeys(false, "LeaderboardId", "Day", "Username")
+	table.ColMap("LeaderboardId").SetMaxSize(26)
+	table.ColMap("Username").SetMaxSize(128)
+
+	return ss
+}
+
+func (ss SqlSnapshotStore) CreateIndexesIfNotExists() {
+	ss.CreateIndexIfNotExists("idx_ranksnapshots_username", "RankSnapshots", "Username")
+}
+
+// Save replaces the leaderboard's snapshots for the day in one transaction
+func (ss SqlSnapshotStore) Save(leaderboardId string, day int64, snapshots []*model.RankSnapshot) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := ss.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error saving rank snapshots, leaderboard_id=" + leaderboardId + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Exec("DELETE FROM RankSnapshots WHERE LeaderboardId = :LeaderboardId AND Day = :Day", map[string]interface{}{"LeaderboardId": leaderboardId, "Day": day}); err != nil {
+			result.Err = errors.New("Error replacing rank snapshots, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		}
+
+		for _, snapshot := range snapshots {
+			if result.Err != nil {
+				break
+			}
+
+			if err := transaction.Insert(snapshot); err != nil {
+				result.Err = errors.New("Error saving rank snapshot, username=" + snapshot.Username + ", " + err.Error())
+			}
+		}
+
+		if result.Err != nil {
+			transaction.Rollback()
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = errors.New("Error saving rank snapshots, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = snapshots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetLatestDay returns the most recent day on or before the given one with
+// snapshots of the leaderboard, or 0 if there are none
+func (ss SqlSnapshotStore) GetLatestDay(leaderboardId string, onOrBefore int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if day, err := ss.GetMaster().SelectNullInt("SELECT MAX(Day) FROM RankSnapshots WHERE LeaderboardId = :LeaderboardId AND Day <= :Day", map[string]interface{}{"LeaderboardId": leaderboardId, "Day": onOrBefore}); err != nil {
+			result.Err = errors.New("Error getting latest snapshot day, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else if day.Valid {
+			result.Data = day.Int64
+		} else {
+			result.Data = int64(0)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ss SqlSnapshotStore) GetForDay(leaderboardId string, day int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		snapshots := []*model.RankSnapshot{}
+
+		if _, err := ss.GetMaster().Select(&snapshots, "SELECT * FROM RankSnapshots WHERE LeaderboardId = :LeaderboardId AND Day = :Day ORDER BY Rank, Username", map[string]interface{}{"LeaderboardId": leaderboardId, "Day": day}); err != nil {
+			result.Err = errors.New("Error getting rank snapshots, day=" + strconv.FormatInt(day, 10) + ", " + err.Error())
+		} else {
+			result.Data = snapshots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForUser returns the user's snapshots since the given day, oldest first
+func (ss SqlSnapshotStore) GetForUser(leaderboardId string, username string, since int64) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		snapshots := []*model.RankSnapshot{}
+
+		if _, err := ss.GetMaster().Select(&snapshots, "SELECT * FROM RankSnapshots WHERE LeaderboardId = :LeaderboardId AND Username = :Username AND Day >= :Since ORDER BY Day", map[string]interface{}{"LeaderboardId": leaderboardId, "Username": username, "Since": since}); err != nil {
+			result.Err = errors.New("Error getting rank snapshots for user, username=" + username + ", " + err.Error())
+		} else {
+			result.Data = snapshots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
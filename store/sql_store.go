@@ -46,12 +46,25 @@ const (
 	EXIT_REMOVE_INDEX_POSTGRES       = 121
 	EXIT_REMOVE_INDEX_MYSQL          = 122
 	EXIT_REMOVE_INDEX_MISSING        = 123
+	EXIT_LEGACY_LEDGER               = 124
 )
 
 type SqlStore struct {
 	master           *gorp.DbMap
 	leaderboard      LeaderboardStore
 	leaderboardEntry LeaderboardEntryStore
+	ledgerEntry      LedgerEntryStore
+	contributor      ContributorStore
+	team             TeamStore
+	season           SeasonStore
+	achievement      AchievementStore
+	audit            AuditStore
+	apiToken         ApiTokenStore
+	session          SessionStore
+	snapshot         SnapshotStore
+	webhook          WebhookStore
+	queue            QueueStore
+	archive          ArchiveStore
 }
 
 func initConnection(connUrl string) *SqlStore {
@@ -68,6 +81,18 @@ func NewSqlStore(connUrl string) Store {
 
 	sqlStore.leaderboard = NewSqlLeaderboardStore(sqlStore)
 	sqlStore.leaderboardEntry = NewSqlLeaderboardEntryStore(sqlStore)
+	sqlStore.ledgerEntry = NewSqlLedgerEntryStore(sqlStore)
+	sqlStore.contributor = NewSqlContributorStore(sqlStore)
+	sqlStore.team = NewSqlTeamStore(sqlStore)
+	sqlStore.season = NewSqlSeasonStore(sqlStore)
+	sqlStore.achievement = NewSqlAchievementStore(sqlStore)
+	sqlStore.audit = NewSqlAuditStore(sqlStore)
+	sqlStore.apiToken = NewSqlApiTokenStore(sqlStore)
+	sqlStore.session = NewSqlSessionStore(sqlStore)
+	sqlStore.snapshot = NewSqlSnapshotStore(sqlStore)
+	sqlStore.webhook = NewSqlWebhookStore(sqlStore)
+	sqlStore.queue = NewSqlQueueStore(sqlStore)
+	sqlStore.archive = NewSqlArchiveStore(sqlStore)
 
 	err := sqlStore.master.CreateTablesIfNotExists()
 	if err != nil {
@@ -76,8 +101,22 @@ func NewSqlStore(connUrl string) Store {
 		os.Exit(EXIT_CREATE_TABLE)
 	}
 
+	UpgradeDatabase(sqlStore)
+
 	sqlStore.leaderboard.(*SqlLeaderboardStore).CreateIndexesIfNotExists()
 	sqlStore.leaderboardEntry.(*SqlLeaderboardEntryStore).CreateIndexesIfNotExists()
+	sqlStore.ledgerEntry.(*SqlLedgerEntryStore).CreateIndexesIfNotExists()
+	sqlStore.contributor.(*SqlContributorStore).CreateIndexesIfNotExists()
+	sqlStore.team.(*SqlTeamStore).CreateIndexesIfNotExists()
+	sqlStore.season.(*SqlSeasonStore).CreateIndexesIfNotExists()
+	sqlStore.achievement.(*SqlAchievementStore).CreateIndexesIfNotExists()
+	sqlStore.audit.(*SqlAuditStore).CreateIndexesIfNotExists()
+	sqlStore.apiToken.(*SqlApiTokenStore).CreateIndexesIfNotExists()
+	sqlStore.session.(*SqlSessionStore).CreateIndexesIfNotExists()
+	sqlStore.snapshot.(*SqlSnapshotStore).CreateIndexesIfNotExists()
+	sqlStore.webhook.(*SqlWebhookStore).CreateIndexesIfNotExists()
+	sqlStore.queue.(*SqlQueueStore).CreateIndexesIfNotExists()
+	sqlStore.archive.(*SqlArchiveStore).CreateIndexesIfNotExists()
 
 	return sqlStore
 }
@@ -247,6 +286,54 @@ func (ss *SqlStore) LeaderboardEntry() LeaderboardEntryStore {
 	return ss.leaderboardEntry
 }
 
+func (ss *SqlStore) LedgerEntry() LedgerEntryStore {
+	return ss.ledgerEntry
+}
+
+func (ss *SqlStore) Contributor() ContributorStore {
+	return ss.contributor
+}
+
+func (ss *SqlStore) Team() TeamStore {
+	return ss.team
+}
+
+func (ss *SqlStore) Season() SeasonStore {
+	return ss.season
+}
+
+func (ss *SqlStore) Achievement() AchievementStore {
+	return ss.achievement
+}
+
+func (ss *SqlStore) Audit() AuditStore {
+	return ss.audit
+}
+
+func (ss *SqlStore) ApiToken() ApiTokenStore {
+	return ss.apiToken
+}
+
+func (ss *SqlStore) Session() SessionStore {
+	return ss.session
+}
+
+func (ss *SqlStore) Snapshot() SnapshotStore {
+	return ss.snapshot
+}
+
+func (ss *SqlStore) Webhook() WebhookStore {
+	return ss.webhook
+}
+
+func (ss *SqlStore) Queue() QueueStore {
+	return ss.queue
+}
+
+func (ss *SqlStore) Archive() ArchiveStore {
+	return ss.archive
+}
+
 func (ss *SqlStore) DropAllTables() {
 	ss.master.TruncateTables()
 }
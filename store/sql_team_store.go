@@ -0,0 +1,243 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlTeamStore struct {
+	*SqlStore
+}
+
+func NewSqlTeamStore(sqlStore *SqlStore) TeamStore {
+	ts := &SqlTeamStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	table := db.AddTableWithName(model.Team{}, "Teams").SetKeys(false, "Id")
+	table.ColMap("Id").SetMaxSize(26)
+	table.ColMap("Name").SetMaxSize(64).SetUnique(true)
+	table.ColMap("DisplayName").SetMaxSize(128)
+
+	members := db.AddTableWithName(model.TeamMember{}, "TeamMembers").SetKeys(false, "TeamId", "Username")
+	members.ColMap("TeamId").SetMaxSize(26)
+	members.ColMap("Username").SetMaxSize(128)
+
+	return ts
+}
+
+func (ts SqlTeamStore) CreateIndexesIfNotExists() {
+	ts.CreateIndexIfNotExists("idx_teammembers_username", "TeamMembers", "Username")
+}
+
+func (ts SqlTeamStore) Save(team *model.Team) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(team.Id) > 0 {
+			result.Err = errors.New("Cannot save existing team, team_id=" + team.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		team.PreSave()
+		if err := team.IsValid(); err != nil {
+			result.Err = err
+		} else if err := ts.GetMaster().Insert(team); err != nil {
+			result.Err = errors.New("Error saving team, name=" + team.Name + ", " + err.Error())
+		} else {
+			result.Data = team
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlTeamStore) GetByName(name string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		team := model.Team{}
+
+		if err := ts.GetMaster().SelectOne(&team, "SELECT * FROM Teams WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
+			result.Err = errors.New("Error getting team by name, name=" + name + ", " + err.Error())
+		} else {
+			result.Data = &team
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlTeamStore) GetAll() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		teams := []*model.Team{}
+
+		if _, err := ts.GetMaster().Select(&teams, "SELECT * FROM Teams ORDER BY Name"); err != nil {
+			result.Err = errors.New("Error getting teams, " + err.Error())
+		} else {
+			result.Data = teams
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlTeamStore) Delete(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ts.GetMaster().Exec("DELETE FROM TeamMembers WHERE TeamId = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error deleting team members, team_id=" + id + ", " + err.Error())
+		} else if _, err := ts.GetMaster().Exec("DELETE FROM Teams WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error deleting team, team_id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveMember adds the member to their team, or updates their weight if they
+// are already on it
+func (ts SqlTeamStore) SaveMember(member *model.TeamMember) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		member.PreSave()
+		if err := member.IsValid(); err != nil {
+			result.Err = err
+		} else if count, err := ts.GetMaster().Update(member); err != nil {
+			result.Err = errors.New("Error updating team member, username=" + member.Username + ", " + err.Error())
+		} else if count == 0 {
+			if err := ts.GetMaster().Insert(member); err != nil {
+				result.Err = errors.New("Error saving team member, username=" + member.Username + ", " + err.Error())
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = member
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlTeamStore) RemoveMember(teamId string, username string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ts.GetMaster().Exec("DELETE FROM TeamMembers WHERE TeamId = :TeamId AND Username = :Username", map[string]interface{}{"TeamId": teamId, "Username": username}); err != nil {
+			result.Err = errors.New("Error removing team member, username=" + username + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ReplaceMembers sets the team's members to exactly the ones given
+func (ts SqlTeamStore) ReplaceMembers(teamId string, members []*model.TeamMember) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := ts.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error replacing team members, team_id=" + teamId + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Exec("DELETE FROM TeamMembers WHERE TeamId = :Id", map[string]interface{}{"Id": teamId}); err != nil {
+			result.Err = errors.New("Error removing team members, team_id=" + teamId + ", " + err.Error())
+		}
+
+		for _, member := range members {
+			if result.Err != nil {
+				break
+			}
+
+			member.TeamId = teamId
+			member.PreSave()
+			if err := member.IsValid(); err != nil {
+				result.Err = err
+			} else if err := transaction.Insert(member); err != nil {
+				result.Err = errors.New("Error saving team member, username=" + member.Username + ", " + err.Error())
+			}
+		}
+
+		if result.Err != nil {
+			transaction.Rollback()
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = errors.New("Error replacing team members, team_id=" + teamId + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ts SqlTeamStore) GetAllMembers() StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		members := []*model.TeamMember{}
+
+		if _, err := ts.GetMaster().Select(&members, "SELECT * FROM TeamMembers ORDER BY Username"); err != nil {
+			result.Err = errors.New("Error getting team members, " + err.Error())
+		} else {
+			result.Data = members
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
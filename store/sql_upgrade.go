@@ -0,0 +1,75 @@
+package store
+
+import (
+	"os"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// UpgradeDatabase adds the columns that tables created by earlier versions are
+// missing. It runs after the tables are created, so new tables already have them.
+func UpgradeDatabase(sqlStore *SqlStore) {
+	sqlStore.CreateColumnIfNotExists("LeaderboardEntry", "ActivePoints", "", "double precision", "0")
+	sqlStore.CreateColumnIfNotExists("LeaderboardEntry", "ActiveAt", "", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("LedgerEntries", "Reason", "", "varchar(512)", "")
+	sqlStore.CreateColumnIfNotExists("Leaderboards", "Visibility", "", "varchar(16)", model.LEADERBOARD_VISIBILITY_PUBLIC)
+
+	createLegacyLedgerEntries(sqlStore)
+}
+
+// createLegacyLedgerEntries records the points that were awarded before the
+// ledger was kept as legacy ledger entries, so that scores rebuilt from the
+// ledger keep them. The active points of those users are reset to be
+// backfilled again with the legacy points. Once every point is in the ledger
+// it does nothing.
+func createLegacyLedgerEntries(sqlStore *SqlStore) {
+	transaction, err := sqlStore.GetMaster().Begin()
+	if err != nil {
+		exitLegacyLedger(err)
+	}
+
+	entries := []*model.LeaderboardEntry{}
+	totals := []*model.LedgerTotal{}
+
+	if _, err := transaction.Select(&entries, "SELECT * FROM LeaderboardEntry FOR UPDATE"); err != nil {
+		transaction.Rollback()
+		exitLegacyLedger(err)
+	}
+
+	if _, err := transaction.Select(&totals, "SELECT LeaderboardId, Username, SUM(Points) AS Points, MIN(CreateAt) AS FirstAt FROM LedgerEntries GROUP BY LeaderboardId, Username"); err != nil {
+		transaction.Rollback()
+		exitLegacyLedger(err)
+	}
+
+	legacy := model.LegacyLedgerEntries(entries, totals, model.GetMillis())
+	for _, ledgerEntry := range legacy {
+		ledgerEntry.PreSave()
+
+		if err := transaction.Insert(ledgerEntry); err != nil {
+			transaction.Rollback()
+			exitLegacyLedger(err)
+		}
+
+		if _, err := transaction.Exec("UPDATE LeaderboardEntry SET ActivePoints = 0, ActiveAt = 0 WHERE Username = :Username AND LeaderboardId = :Id",
+			map[string]interface{}{"Username": ledgerEntry.Username, "Id": ledgerEntry.LeaderboardId}); err != nil {
+			transaction.Rollback()
+			exitLegacyLedger(err)
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		exitLegacyLedger(err)
+	}
+
+	if len(legacy) > 0 {
+		l4g.Info("Recorded the points of %v users awarded before the ledger was kept", len(legacy))
+	}
+}
+
+func exitLegacyLedger(err error) {
+	l4g.Critical("Errored recording legacy points in the ledger", err)
+	time.Sleep(time.Second)
+	os.Exit(EXIT_LEGACY_LEDGER)
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type SqlWebhookStore struct {
+	*SqlStore
+}
+
+func NewSqlWebhookStore(sqlStore *SqlStore) WebhookStore {
+	ws := &SqlWebhookStore{sqlStore}
+
+	db := sqlStore.GetMaster()
+	subscriptions := db.AddTableWithName(model.WebhookSubscription{}, "WebhookSubscriptions").SetKeys(false, "Id")
+	subscriptions.ColMap("Id").SetMaxSize(26)
+	subscriptions.ColMap("LeaderboardId").SetMaxSize(26)
+	subscriptions.ColMap("Url").SetMaxSize(1024)
+	subscriptions.ColMap("Secret").SetMaxSize(64)
+	subscriptions.ColMap("Events").SetMaxSize(256)
+
+	deliveries := db.AddTableWithName(model.WebhookDelivery{}, "WebhookDeliveries").SetKeys(false, "Id")
+	deliveries.ColMap("Id").SetMaxSize(26)
+	deliveries.ColMap("SubscriptionId").SetMaxSize(26)
+	deliveries.ColMap("EventType").SetMaxSize(64)
+	deliveries.ColMap("Payload").SetMaxSize(16384)
+	deliveries.ColMap("Error").SetMaxSize(512)
+	deliveries.ColMap("RedeliveryOf").SetMaxSize(26)
+
+	return ws
+}
+
+func (ws SqlWebhookStore) CreateIndexesIfNotExists() {
+	ws.CreateIndexIfNotExists("idx_webhooksubscriptions_leaderboard_id", "WebhookSubscriptions", "LeaderboardId")
+	ws.CreateIndexIfNotExists("idx_webhookdeliveries_subscription_id", "WebhookDeliveries", "SubscriptionId")
+}
+
+func (ws SqlWebhookStore) SaveSubscription(subscription *model.WebhookSubscription) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if err := subscription.IsValid(); err != nil {
+			result.Err = err
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		subscription.PreSave()
+
+		if err := ws.GetMaster().Insert(subscription); err != nil {
+			result.Err = errors.New("Error saving webhook subscription, url=" + subscription.Url + ", " + err.Error())
+		} else {
+			result.Data = subscription
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ws SqlWebhookStore) GetSubscription(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		subscription := model.WebhookSubscription{}
+
+		if err := ws.GetMaster().SelectOne(&subscription, "SELECT * FROM WebhookSubscriptions WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error getting webhook subscription, id=" + id + ", " + err.Error())
+		} else {
+			result.Data = &subscription
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ws SqlWebhookStore) GetSubscriptions(leaderboardId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		subscriptions := []*model.WebhookSubscription{}
+
+		if _, err := ws.GetMaster().Select(&subscriptions, "SELECT * FROM WebhookSubscriptions WHERE LeaderboardId = :LeaderboardId ORDER BY CreateAt", map[string]interface{}{"LeaderboardId": leaderboardId}); err != nil {
+			result.Err = errors.New("Error getting webhook subscriptions, leaderboard_id=" + leaderboardId + ", " + err.Error())
+		} else {
+			result.Data = subscriptions
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// DeleteSubscription deletes the subscription along with its delivery log
+func (ws SqlWebhookStore) DeleteSubscription(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := ws.GetMaster().Begin()
+		if err != nil {
+			result.Err = errors.New("Error deleting webhook subscription, id=" + id + ", " + err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Exec("DELETE FROM WebhookDeliveries WHERE SubscriptionId = :Id", map[string]interface{}{"Id": id}); err != nil {
+			transaction.Rollback()
+			result.Err = errors.New("Error deleting webhook deliveries, id=" + id + ", " + err.Error())
+		} else if _, err := transaction.Exec("DELETE FROM WebhookSubscriptions WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			transaction.Rollback()
+			result.Err = errors.New("Error deleting webhook subscription, id=" + id + ", " + err.Error())
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = errors.New("Error deleting webhook subscription, id=" + id + ", " + err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ws SqlWebhookStore) SaveDelivery(delivery *model.WebhookDelivery) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery.PreSave()
+
+		if err := ws.GetMaster().Insert(delivery); err != nil {
+			result.Err = errors.New("Error saving webhook delivery, subscription_id=" + delivery.SubscriptionId + ", " + err.Error())
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ws SqlWebhookStore) UpdateDelivery(delivery *model.WebhookDelivery) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := ws.GetMaster().Update(delivery); err != nil {
+			result.Err = errors.New("Error updating webhook delivery, id=" + delivery.Id + ", " + err.Error())
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (ws SqlWebhookStore) GetDelivery(id string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery := model.WebhookDelivery{}
+
+		if err := ws.GetMaster().SelectOne(&delivery, "SELECT * FROM WebhookDeliveries WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = errors.New("Error getting webhook delivery, id=" + id + ", " + err.Error())
+		} else {
+			result.Data = &delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDeliveries returns the subscription's most recent deliveries, newest first
+func (ws SqlWebhookStore) GetDeliveries(subscriptionId string, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		deliveries := []*model.WebhookDelivery{}
+
+		if _, err := ws.GetMaster().Select(&deliveries, "SELECT * FROM WebhookDeliveries WHERE SubscriptionId = :SubscriptionId ORDER BY CreateAt DESC LIMIT :Limit", map[string]interface{}{"SubscriptionId": subscriptionId, "Limit": limit}); err != nil {
+			result.Err = errors.New("Error getting webhook deliveries, subscription_id=" + subscriptionId + ", " + err.Error())
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -28,6 +28,18 @@ func Must(sc StoreChannel) interface{} {
 type Store interface {
 	Leaderboard() LeaderboardStore
 	LeaderboardEntry() LeaderboardEntryStore
+	LedgerEntry() LedgerEntryStore
+	Contributor() ContributorStore
+	Team() TeamStore
+	Season() SeasonStore
+	Achievement() AchievementStore
+	Audit() AuditStore
+	ApiToken() ApiTokenStore
+	Session() SessionStore
+	Snapshot() SnapshotStore
+	Webhook() WebhookStore
+	Queue() QueueStore
+	Archive() ArchiveStore
 	Close()
 	DropAllTables()
 }
@@ -36,10 +48,125 @@ type LeaderboardStore interface {
 	Save(leaderboard *model.Leaderboard) StoreChannel
 	Get(id string) StoreChannel
 	GetByName(name string) StoreChannel
+	GetAll() StoreChannel
+	UpdateVisibility(id string, visibility string) StoreChannel
+	SaveViewer(viewer *model.LeaderboardViewer) StoreChannel
+	RemoveViewer(leaderboardId string, username string) StoreChannel
+	GetViewers(leaderboardId string) StoreChannel
+	IsViewer(leaderboardId string, username string) StoreChannel
 }
 
 type LeaderboardEntryStore interface {
 	Save(entry *model.LeaderboardEntry) StoreChannel
-	IncrementPoints(username string, leaderboardId string) StoreChannel
+	Award(ledgerEntry *model.LedgerEntry, halfLife int64) StoreChannel
+	BackfillActivePoints(leaderboardId string, halfLife int64) StoreChannel
 	GetRankings(leaderboardId string) StoreChannel
+	Rebuild(leaderboardId string, entries []*model.LeaderboardEntry, rescored []*model.LedgerEntry, ledgerCount int64) StoreChannel
+}
+
+type LedgerEntryStore interface {
+	Save(entry *model.LedgerEntry) StoreChannel
+	Get(id string) StoreChannel
+	GetByNumber(leaderboardId string, repository string, number int, entryType string) StoreChannel
+	GetByTitle(leaderboardId string, repository string, title string, entryType string) StoreChannel
+	GetByUrl(leaderboardId string, url string, entryType string) StoreChannel
+	GetByRelatedId(relatedId string) StoreChannel
+	GetForUser(leaderboardId string, username string) StoreChannel
+	GetRecent(leaderboardId string, limit int) StoreChannel
+	GetTotals(leaderboardId string, since int64, until int64) StoreChannel
+	GetTimeline(leaderboardId string) StoreChannel
+	GetHistory(leaderboardId string) StoreChannel
+	GetFirstContributions(leaderboardId string) StoreChannel
+}
+
+type ContributorStore interface {
+	Save(contributor *model.Contributor) StoreChannel
+	Get(id string) StoreChannel
+	GetAll() StoreChannel
+	GetAllAccounts() StoreChannel
+	LinkAccount(account *model.ContributorAccount) StoreChannel
+	UnlinkAccount(contributorId string, provider string, login string) StoreChannel
+	Merge(targetId string, sourceId string) StoreChannel
+}
+
+type TeamStore interface {
+	Save(team *model.Team) StoreChannel
+	GetByName(name string) StoreChannel
+	GetAll() StoreChannel
+	Delete(id string) StoreChannel
+	SaveMember(member *model.TeamMember) StoreChannel
+	RemoveMember(teamId string, username string) StoreChannel
+	ReplaceMembers(teamId string, members []*model.TeamMember) StoreChannel
+	GetAllMembers() StoreChannel
+}
+
+type SeasonStore interface {
+	Save(season *model.Season) StoreChannel
+	Get(id string) StoreChannel
+	GetCurrent(leaderboardId string) StoreChannel
+	GetAll(leaderboardId string) StoreChannel
+	Close(season *model.Season, standings []*model.SeasonStanding) StoreChannel
+	GetStandings(seasonId string, limit int) StoreChannel
+	GetStandingsForUser(leaderboardId string, username string) StoreChannel
+}
+
+type AchievementStore interface {
+	Save(achievement *model.Achievement) StoreChannel
+	GetForUser(leaderboardId string, username string) StoreChannel
+	GetForLeaderboard(leaderboardId string) StoreChannel
+}
+
+type AuditStore interface {
+	Save(audit *model.Audit) StoreChannel
+	Get(username string, limit int) StoreChannel
+}
+
+type ApiTokenStore interface {
+	Save(token *model.ApiToken) StoreChannel
+	GetByHash(hash string) StoreChannel
+	GetAll() StoreChannel
+	UpdateLastUsed(id string, at int64) StoreChannel
+	Delete(id string) StoreChannel
+}
+
+type SessionStore interface {
+	Save(session *model.Session) StoreChannel
+	GetByHash(hash string) StoreChannel
+	Delete(hash string) StoreChannel
+	RemoveExpired(before int64) StoreChannel
+}
+
+type SnapshotStore interface {
+	Save(leaderboardId string, day int64, snapshots []*model.RankSnapshot) StoreChannel
+	GetLatestDay(leaderboardId string, onOrBefore int64) StoreChannel
+	GetForDay(leaderboardId string, day int64) StoreChannel
+	GetForUser(leaderboardId string, username string, since int64) StoreChannel
+}
+
+type WebhookStore interface {
+	SaveSubscription(subscription *model.WebhookSubscription) StoreChannel
+	GetSubscription(id string) StoreChannel
+	GetSubscriptions(leaderboardId string) StoreChannel
+	DeleteSubscription(id string) StoreChannel
+	SaveDelivery(delivery *model.WebhookDelivery) StoreChannel
+	UpdateDelivery(delivery *model.WebhookDelivery) StoreChannel
+	GetDelivery(id string) StoreChannel
+	GetDeliveries(subscriptionId string, limit int) StoreChannel
+}
+
+type QueueStore interface {
+	Save(event *model.QueuedEvent) StoreChannel
+	Update(event *model.QueuedEvent) StoreChannel
+	Get(id string) StoreChannel
+	GetUnfinished(offset int, limit int) StoreChannel
+	GetByStatus(status string, limit int) StoreChannel
+	ResetProcessing() StoreChannel
+	Delete(id string) StoreChannel
+}
+
+type ArchiveStore interface {
+	Save(delivery *model.ArchivedDelivery) StoreChannel
+	Get(id string) StoreChannel
+	GetByIds(ids []string) StoreChannel
+	Search(search *model.DeliverySearch) StoreChannel
 }
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+)
+
+const (
+	BADGE_HEIGHT     = 20
+	BADGE_PADDING    = 6
+	BADGE_CHAR_WIDTH = 7
+)
+
+// BADGE_COLORS are the named colors badges accept, as on shields.io
+var BADGE_COLORS = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellow":      "#dfb317",
+	"yellowgreen": "#a4a61d",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"grey":        "#555",
+	"lightgrey":   "#9f9f9f",
+}
+
+var hexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// BadgeColor returns the badge color for a name or hex code, or def if the
+// color isn't valid
+func BadgeColor(color string, def string) string {
+	if c, ok := BADGE_COLORS[color]; ok {
+		return c
+	}
+
+	if matches := hexColor.FindStringSubmatch(color); matches != nil {
+		return "#" + matches[1]
+	}
+
+	return BADGE_COLORS[def]
+}
+
+func badgeTextWidth(text string) int {
+	return len([]rune(text))*BADGE_CHAR_WIDTH + 2*BADGE_PADDING
+}
+
+// RenderBadge draws a shields.io style badge with the label on a grey
+// background and the message on the given color
+func RenderBadge(label string, message string, color string) string {
+	labelWidth := badgeTextWidth(label)
+	messageWidth := badgeTextWidth(message)
+	width := labelWidth + messageWidth
+
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s: %s">`+
+		`<title>%s: %s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%d" height="%d" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%d" height="%d" fill="#555"/><rect x="%d" width="%d" height="%d" fill="%s"/><rect width="%d" height="%d" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`+
+		`</g></svg>`,
+		width, BADGE_HEIGHT, label, message,
+		label, message,
+		width, BADGE_HEIGHT,
+		labelWidth, BADGE_HEIGHT, labelWidth, messageWidth, BADGE_HEIGHT, color, width, BADGE_HEIGHT,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message)
+}
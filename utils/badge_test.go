@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBadgeColor(t *testing.T) {
+	if BadgeColor("red", "blue") != BADGE_COLORS["red"] {
+		t.Fatal("should use named colors")
+	}
+
+	if BadgeColor("ff00aa", "blue") != "#ff00aa" || BadgeColor("#abc", "blue") != "#abc" {
+		t.Fatal("should accept hex colors")
+	}
+
+	if BadgeColor("javascript:alert(1)", "blue") != BADGE_COLORS["blue"] || BadgeColor("", "blue") != BADGE_COLORS["blue"] {
+		t.Fatal("should fall back to the default")
+	}
+}
+
+func TestRenderBadge(t *testing.T) {
+	svg := RenderBadge("top contributor", "#3 with 42 points", "#4c1")
+
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatal("should be an svg document")
+	}
+
+	if !strings.Contains(svg, ">top contributor</text>") || !strings.Contains(svg, "#3 with 42 points") {
+		t.Fatal("should contain the label and message")
+	}
+
+	if strings.Contains(RenderBadge("<b>", "x", "#4c1"), "<b>") {
+		t.Fatal("should escape text")
+	}
+
+	if !strings.HasPrefix(RenderBadge("a", "b", "#4c1"), `<svg xmlns="http://www.w3.org/2000/svg" width="38"`) {
+		t.Fatal("should size the badge to its text")
+	}
+}
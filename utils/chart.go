@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+const (
+	CHART_MARGIN_LEFT   = 50
+	CHART_MARGIN_RIGHT  = 130
+	CHART_MARGIN_TOP    = 40
+	CHART_MARGIN_BOTTOM = 40
+	CHART_GRID_LINES    = 5
+	CHART_MAX_LABELS    = 8
+)
+
+// CHART_COLORS are used for the chart's series in order, repeating if there are more series
+var CHART_COLORS = []string{"#2389d7", "#db4437", "#f4b400", "#0f9d58", "#ab47bc", "#00acc1", "#ff7043", "#9e9d24"}
+
+type ChartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// Chart is rendered to a standalone SVG document, so it needs no JavaScript
+// or stylesheets. Every series has one value per label.
+type Chart struct {
+	Title  string
+	Labels []string
+	Series []*ChartSeries
+	Width  int
+	Height int
+}
+
+// NiceCeiling rounds v up to 1, 2 or 5 times a power of ten, so that the
+// axis labels are round numbers
+func NiceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+func (c *Chart) maxValue() float64 {
+	max := 0.0
+	for _, series := range c.Series {
+		for _, v := range series.Values {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	return NiceCeiling(max)
+}
+
+func (c *Chart) plotWidth() float64 {
+	return float64(c.Width - CHART_MARGIN_LEFT - CHART_MARGIN_RIGHT)
+}
+
+func (c *Chart) plotHeight() float64 {
+	return float64(c.Height - CHART_MARGIN_TOP - CHART_MARGIN_BOTTOM)
+}
+
+func (c *Chart) y(v float64, max float64) float64 {
+	return float64(CHART_MARGIN_TOP) + c.plotHeight()*(1-v/max)
+}
+
+// renderFrame writes the opening of the document along with the title,
+// the horizontal grid lines and their labels
+func (c *Chart) renderFrame(b *strings.Builder, max float64) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica,Arial,sans-serif" font-size="11">`, c.Width, c.Height, c.Width, c.Height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="#fff"/>`, c.Width, c.Height)
+	fmt.Fprintf(b, `<text x="%d" y="20" font-size="14" font-weight="bold">%s</text>`, CHART_MARGIN_LEFT, html.EscapeString(c.Title))
+
+	for i := 0; i <= CHART_GRID_LINES; i++ {
+		v := max * float64(i) / CHART_GRID_LINES
+		y := c.y(v, max)
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`, CHART_MARGIN_LEFT, y, float64(CHART_MARGIN_LEFT)+c.plotWidth(), y)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end" fill="#666">%s</text>`, CHART_MARGIN_LEFT-6, y+4, formatChartValue(v))
+	}
+}
+
+// renderLabels writes the labels along the x axis, skipping some if there
+// are too many to fit. x returns the position of the label at index i.
+func (c *Chart) renderLabels(b *strings.Builder, x func(i int) float64) {
+	every := (len(c.Labels) + CHART_MAX_LABELS - 1) / CHART_MAX_LABELS
+	if every < 1 {
+		every = 1
+	}
+
+	for i, label := range c.Labels {
+		if i%every == 0 {
+			fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle" fill="#666">%s</text>`, x(i), c.Height-CHART_MARGIN_BOTTOM+16, html.EscapeString(label))
+		}
+	}
+}
+
+func (c *Chart) renderLegend(b *strings.Builder) {
+	x := float64(c.Width - CHART_MARGIN_RIGHT + 10)
+	for i, series := range c.Series {
+		y := float64(CHART_MARGIN_TOP + i*18)
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="10" height="10" fill="%s"/>`, x, y, chartColor(i))
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f">%s</text>`, x+15, y+9, html.EscapeString(series.Name))
+	}
+}
+
+// RenderLine draws each series as a line across the labels
+func (c *Chart) RenderLine() string {
+	var b strings.Builder
+	max := c.maxValue()
+
+	c.renderFrame(&b, max)
+
+	x := func(i int) float64 {
+		if len(c.Labels) <= 1 {
+			return float64(CHART_MARGIN_LEFT) + c.plotWidth()/2
+		}
+		return float64(CHART_MARGIN_LEFT) + c.plotWidth()*float64(i)/float64(len(c.Labels)-1)
+	}
+
+	c.renderLabels(&b, x)
+
+	for i, series := range c.Series {
+		points := make([]string, 0, len(series.Values))
+		for j, v := range series.Values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(j), c.y(v, max)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, chartColor(i), strings.Join(points, " "))
+	}
+
+	c.renderLegend(&b)
+	b.WriteString("</svg>")
+
+	return b.String()
+}
+
+// RenderBar draws a bar per label for the first series
+func (c *Chart) RenderBar() string {
+	var b strings.Builder
+	max := c.maxValue()
+
+	c.renderFrame(&b, max)
+
+	slot := c.plotWidth() / math.Max(1, float64(len(c.Labels)))
+	x := func(i int) float64 {
+		return float64(CHART_MARGIN_LEFT) + slot*(float64(i)+0.5)
+	}
+
+	c.renderLabels(&b, x)
+
+	if len(c.Series) > 0 {
+		for i, v := range c.Series[0].Values {
+			y := c.y(v, max)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`,
+				x(i)-slot*0.4, y, slot*0.8, float64(CHART_MARGIN_TOP)+c.plotHeight()-y, chartColor(0), html.EscapeString(c.Labels[i]), formatChartValue(v))
+		}
+	}
+
+	b.WriteString("</svg>")
+
+	return b.String()
+}
+
+func chartColor(i int) string {
+	return CHART_COLORS[i%len(CHART_COLORS)]
+}
+
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	return fmt.Sprintf("%.1f", v)
+}
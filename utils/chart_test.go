@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNiceCeiling(t *testing.T) {
+	cases := map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 3: 5, 7: 10, 12: 20, 42: 50, 100: 100, 101: 200}
+	for v, expected := range cases {
+		if NiceCeiling(v) != expected {
+			t.Fatalf("nice ceiling of %v should be %v, got %v", v, expected, NiceCeiling(v))
+		}
+	}
+}
+
+func TestRenderLine(t *testing.T) {
+	chart := &Chart{
+		Title:  "Points <over> time",
+		Labels: []string{"Jan 1", "Jan 2", "Jan 3"},
+		Series: []*ChartSeries{
+			{Name: "jwilander", Values: []float64{1, 2, 3}},
+			{Name: "<script>", Values: []float64{0, 5, 8}},
+		},
+		Width:  600,
+		Height: 300,
+	}
+
+	svg := chart.RenderLine()
+
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatal("should be an svg document")
+	}
+
+	if strings.Count(svg, "<polyline") != 2 {
+		t.Fatal("should draw a line per series")
+	}
+
+	if strings.Contains(svg, "<script>") || strings.Contains(svg, "<over>") {
+		t.Fatal("should escape text")
+	}
+
+	if !strings.Contains(svg, ">10</text>") {
+		t.Fatal("axis should go up to a round number")
+	}
+}
+
+func TestRenderBar(t *testing.T) {
+	chart := &Chart{
+		Title:  "Contributions per week",
+		Labels: []string{"w1", "w2", "w3", "w4"},
+		Series: []*ChartSeries{{Name: "contributions", Values: []float64{3, 0, 7, 2}}},
+		Width:  600,
+		Height: 300,
+	}
+
+	svg := chart.RenderBar()
+
+	if strings.Count(svg, "<title>") != 4 {
+		t.Fatal("should draw a bar per label")
+	}
+
+	if (&Chart{Title: "empty", Width: 600, Height: 300}).RenderBar() == "" {
+		t.Fatal("should render an empty chart")
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+	"os"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// LoadConfig reads the config file at fileName and fills in defaults for any
+// missing settings. A missing file is not an error, all defaults are used instead.
+func LoadConfig(fileName string) (*model.Config, error) {
+	config := &model.Config{}
+
+	if file, err := os.Open(fileName); err == nil {
+		defer file.Close()
+
+		if config = model.ConfigFromJson(file); config == nil {
+			return nil, errors.New("Unable to parse config file, file=" + fileName)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, errors.New("Unable to open config file, file=" + fileName + ", " + err.Error())
+	}
+
+	config.SetDefaults()
+
+	if err := config.IsValid(); err != nil {
+		return nil, errors.New("Invalid config file, file=" + fileName + ", " + err.Error())
+	}
+
+	return config, nil
+}
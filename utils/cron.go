@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronShortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// CronSchedule is a parsed five field cron expression: minute, hour, day of
+// month, month and day of week. Sunday is day 0 or 7.
+type CronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// As in cron, a day matches either field if both are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+// ParseCron parses a cron expression, which may use lists, ranges, steps
+// and the @hourly, @daily, @weekly and @monthly shortcuts
+func ParseCron(spec string) (*CronSchedule, error) {
+	if shortcut, ok := cronShortcuts[strings.TrimSpace(spec)]; ok {
+		spec = shortcut
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("Cron schedule must have 5 fields, spec=" + spec)
+	}
+
+	s := &CronSchedule{
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+
+	if s.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+
+	if s.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+
+	return s, nil
+}
+
+func parseCronField(field string, min int, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		start, end, step := min, max, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.New("Invalid cron step, field=" + field)
+			}
+			part = part[:i]
+		}
+
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.New("Invalid cron value, field=" + field)
+			}
+
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.New("Invalid cron range, field=" + field)
+				}
+			} else if step == 1 {
+				end = start
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, errors.New("Cron value out of range, field=" + field)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// Next returns the first time after t that matches the schedule, or the
+// zero time if nothing matches within five years
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"0 9 * * 1", "*/15 * * * *", "0 9-17/2 1,15 * 1-5", "@weekly", "0 0 * * 7"} {
+		if _, err := ParseCron(spec); err != nil {
+			t.Fatal(spec, err)
+		}
+	}
+
+	for _, spec := range []string{"", "0 9 * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Fatal("should fail to parse", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 1, 10, 12, 30, 45, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"0 9 * * 1", time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 10, 12, 45, 0, 0, time.UTC)},
+		{"30 12 * * *", time.Date(2024, 1, 11, 12, 30, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * 12 *", time.Date(2024, 12, 1, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseCron(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if next := schedule.Next(now); !next.Equal(c.next) {
+			t.Fatalf("bad next time for %v, got %v", c.spec, next)
+		}
+	}
+
+	schedule, _ := ParseCron("0 0 31 2 *")
+	if !schedule.Next(now).IsZero() {
+		t.Fatal("impossible schedule should never run")
+	}
+}
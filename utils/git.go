@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// ReadGitLog reads the commits of a branch of the git repository at dir,
+// in topological order so the branch's tip comes first
+func ReadGitLog(dir string, branch string) ([]*model.GitCommit, error) {
+	if strings.HasPrefix(branch, "-") {
+		return nil, errors.New("Invalid branch, branch=" + branch)
+	}
+
+	cmd := exec.Command("git", "-C", dir, "log", "--topo-order", "--format="+model.GIT_LOG_FORMAT, branch, "--")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, errors.New("Error reading git log, " + strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, errors.New("Error reading git log, " + err.Error())
+	}
+
+	return model.ParseGitLog(string(output))
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestReadGitLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	dir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Author", "GIT_AUTHOR_EMAIL=author@example.com", "GIT_AUTHOR_DATE=2015-03-01T12:00:00Z",
+			"GIT_COMMITTER_NAME=Author", "GIT_COMMITTER_EMAIL=author@example.com", "GIT_COMMITTER_DATE=2015-03-01T12:00:00Z")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatal(string(output))
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	git("checkout", "-q", "-b", "feature")
+	git("commit", "-q", "--allow-empty", "-m", "Add feature")
+	git("checkout", "-q", "main")
+	git("merge", "-q", "--no-ff", "-m", "Merge pull request #7 from author/feature", "feature")
+
+	commits, err := ReadGitLog(dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commits) != 3 || !commits[0].IsMerge() || commits[0].Subject != "Merge pull request #7 from author/feature" {
+		t.Fatal("the merge should come first")
+	}
+
+	if commits[1].AuthorEmail != "author@example.com" || commits[1].AuthorAt != 1425211200000 {
+		t.Fatal("bad author")
+	}
+
+	if _, err := ReadGitLog(dir, "missing"); err == nil {
+		t.Fatal("should fail for a missing branch")
+	}
+
+	if _, err := ReadGitLog(dir, "--all"); err == nil {
+		t.Fatal("should refuse options as branches")
+	}
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	GITHUB_API_URL      = "https://api.github.com"
+	GITHUB_API_PER_PAGE = 100
+)
+
+var githubNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// GitHubClient reads a repository's pull requests, reviews and issues from
+// the GitHub API, or from a directory of files exported from it. Files are
+// named after the API paths they came from, pulls.json, issues.json and
+// pulls/{number}/reviews.json, each holding one JSON array. Missing files
+// are read as empty lists.
+type GitHubClient struct {
+	Repository string
+	apiUrl     string
+	dir        string
+	token      string
+	client     *http.Client
+}
+
+// NewGitHubApiClient reads the repository from the GitHub API at apiUrl,
+// authenticating with token unless it's empty
+func NewGitHubApiClient(apiUrl string, repository string, token string) *GitHubClient {
+	return &GitHubClient{
+		Repository: repository,
+		apiUrl:     strings.TrimRight(apiUrl, "/"),
+		token:      token,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewGitHubFileClient reads the repository from files exported to dir
+func NewGitHubFileClient(dir string, repository string) (*GitHubClient, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, errors.New("Not a directory, dir=" + dir)
+	}
+
+	return &GitHubClient{Repository: repository, dir: dir}, nil
+}
+
+// GetPullRequests returns every closed pull request, merged or not
+func (c *GitHubClient) GetPullRequests() ([]*model.EventPullRequest, error) {
+	pulls := []*model.EventPullRequest{}
+	err := c.getAll("pulls", url.Values{"state": {"closed"}}, func(data json.RawMessage) error {
+		var pr model.EventPullRequest
+		if err := json.Unmarshal(data, &pr); err != nil {
+			return err
+		}
+		pulls = append(pulls, &pr)
+		return nil
+	})
+
+	return pulls, err
+}
+
+func (c *GitHubClient) GetReviews(number int) ([]*model.EventReview, error) {
+	reviews := []*model.EventReview{}
+	err := c.getAll(fmt.Sprintf("pulls/%v/reviews", number), url.Values{}, func(data json.RawMessage) error {
+		var review model.EventReview
+		if err := json.Unmarshal(data, &review); err != nil {
+			return err
+		}
+		reviews = append(reviews, &review)
+		return nil
+	})
+
+	return reviews, err
+}
+
+// GetIssues returns every issue, open or closed, along with the pull
+// requests GitHub lists as issues
+func (c *GitHubClient) GetIssues() ([]*model.EventIssue, error) {
+	issues := []*model.EventIssue{}
+	err := c.getAll("issues", url.Values{"state": {"all"}}, func(data json.RawMessage) error {
+		var issue model.EventIssue
+		if err := json.Unmarshal(data, &issue); err != nil {
+			return err
+		}
+		issues = append(issues, &issue)
+		return nil
+	})
+
+	return issues, err
+}
+
+// GetEvents returns the events of every merged pull request, review and
+// issue in the repository, oldest first
+func (c *GitHubClient) GetEvents() ([]*model.BackfillEvent, error) {
+	events := []*model.BackfillEvent{}
+
+	pulls, err := c.GetPullRequests()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pr := range pulls {
+		if pr.MergedAt == nil {
+			continue
+		}
+
+		reviews, err := c.GetReviews(pr.Number)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, model.PullRequestEvents(c.Repository, pr, reviews)...)
+	}
+
+	issues, err := c.GetIssues()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if event := model.IssueEvent(c.Repository, issue); event != nil {
+			events = append(events, event)
+		}
+	}
+
+	model.SortBackfillEvents(events)
+
+	return events, nil
+}
+
+// getAll passes each item of the list at path to add, following the API's
+// pagination
+func (c *GitHubClient) getAll(path string, query url.Values, add func(data json.RawMessage) error) error {
+	if len(c.dir) > 0 {
+		data, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(path)+".json"))
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return addItems(path, data, add)
+	}
+
+	query.Set("per_page", fmt.Sprint(GITHUB_API_PER_PAGE))
+	next := c.apiUrl + "/repos/" + c.Repository + "/" + path + "?" + query.Encode()
+
+	for len(next) > 0 {
+		req, err := http.NewRequest("GET", next, nil)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("User-Agent", "contributor-leaderboard")
+		if len(c.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("Error getting %v, status=%v", next, resp.Status)
+		}
+
+		if err := addItems(path, data, add); err != nil {
+			return err
+		}
+
+		next = ""
+		if match := githubNextLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+			next = match[1]
+		}
+	}
+
+	return nil
+}
+
+func addItems(path string, data []byte, add func(data json.RawMessage) error) error {
+	items := []json.RawMessage{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return errors.New("Error parsing " + path + ", " + err.Error())
+	}
+
+	for _, item := range items {
+		if err := add(item); err != nil {
+			return errors.New("Error parsing " + path + ", " + err.Error())
+		}
+	}
+
+	return nil
+}
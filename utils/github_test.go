@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	testPullsPage1 = `[{"number": 1, "html_url": "https://github.com/o/r/pull/1", "user": {"login": "author"}, "merged_at": "2015-03-02T12:00:00Z"}]`
+	testPullsPage2 = `[{"number": 2, "html_url": "https://github.com/o/r/pull/2", "user": {"login": "author"}, "merged_at": null}]`
+	testReviews    = `[{"id": 5, "html_url": "https://github.com/o/r/pull/1#pullrequestreview-5", "user": {"login": "reviewer"}, "submitted_at": "2015-03-01T12:00:00Z"}]`
+	testIssues     = `[{"number": 3, "html_url": "https://github.com/o/r/issues/3", "user": {"login": "reporter"}, "created_at": "2015-01-01T12:00:00Z"},
+		{"number": 1, "user": {"login": "author"}, "created_at": "2015-02-01T12:00:00Z", "pull_request": {"url": "https://api.github.com/repos/o/r/pulls/1"}}]`
+)
+
+func checkBackfillEvents(t *testing.T, events []*model.BackfillEvent) {
+	if len(events) != 3 {
+		t.Fatal("should have the issue, review and merge", len(events))
+	}
+
+	if events[0].Type != model.EVENT_TYPE_ISSUES || events[1].Type != model.EVENT_TYPE_PULL_REQUEST_REVIEW || events[2].Type != model.EVENT_TYPE_PULL_REQUEST {
+		t.Fatal("events should be oldest first")
+	}
+
+	if events[2].Event.PullRequest.Number != 1 || events[2].Event.Repository.FullName != "o/r" {
+		t.Fatal("bad merge event")
+	}
+}
+
+func TestGitHubApiClient(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/repos/o/r/pulls":
+			if r.URL.Query().Get("state") != "closed" {
+				t.Error("should list closed pull requests")
+			}
+
+			if r.URL.Query().Get("page") == "2" {
+				w.Write([]byte(testPullsPage2))
+			} else {
+				w.Header().Set("Link", `<`+server.URL+`/repos/o/r/pulls?state=closed&page=2>; rel="next", <`+server.URL+`/repos/o/r/pulls?state=closed&page=2>; rel="last"`)
+				w.Write([]byte(testPullsPage1))
+			}
+		case "/repos/o/r/pulls/1/reviews":
+			w.Write([]byte(testReviews))
+		case "/repos/o/r/issues":
+			w.Write([]byte(testIssues))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGitHubApiClient(server.URL+"/", "o/r", "secret")
+
+	if pulls, err := client.GetPullRequests(); err != nil {
+		t.Fatal(err)
+	} else if len(pulls) != 2 {
+		t.Fatal("should follow the next link")
+	}
+
+	if events, err := client.GetEvents(); err != nil {
+		t.Fatal(err)
+	} else {
+		checkBackfillEvents(t, events)
+	}
+
+	if _, err := NewGitHubApiClient(server.URL, "o/r", "wrong").GetIssues(); err == nil {
+		t.Fatal("should fail on an error status")
+	}
+}
+
+func TestGitHubFileClient(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NewGitHubFileClient(filepath.Join(dir, "missing"), "o/r"); err == nil {
+		t.Fatal("should need the directory to exist")
+	}
+
+	os.MkdirAll(filepath.Join(dir, "pulls", "1"), 0755)
+	os.WriteFile(filepath.Join(dir, "pulls.json"), []byte(testPullsPage1), 0644)
+	os.WriteFile(filepath.Join(dir, "pulls", "1", "reviews.json"), []byte(testReviews), 0644)
+	os.WriteFile(filepath.Join(dir, "issues.json"), []byte(testIssues), 0644)
+
+	client, err := NewGitHubFileClient(dir, "o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events, err := client.GetEvents(); err != nil {
+		t.Fatal(err)
+	} else {
+		checkBackfillEvents(t, events)
+	}
+
+	os.Remove(filepath.Join(dir, "issues.json"))
+	if issues, err := client.GetIssues(); err != nil || len(issues) != 0 {
+		t.Fatal("a missing file should be an empty list")
+	}
+
+	os.WriteFile(filepath.Join(dir, "pulls.json"), []byte("{"), 0644)
+	if _, err := client.GetPullRequests(); err == nil {
+		t.Fatal("should fail to parse")
+	}
+}
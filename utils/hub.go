@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	HUB_SUBSCRIPTION_BUFFER = 64
+)
+
+var ErrTooManySubscribers = errors.New("Too many subscribers")
+
+// Subscription receives the events published for one leaderboard. Its
+// channel is closed when it's unsubscribed, or if it falls so far behind
+// that events would have to be dropped, so the subscriber can reconnect
+// and catch up from the hub's history.
+type Subscription struct {
+	LeaderboardId string
+	Events        chan *model.DomainEvent
+}
+
+// Hub passes domain events from the code that publishes them to listeners
+// inside the server and to subscribers streaming them to clients. It keeps
+// the most recent events so reconnecting subscribers can replay the ones
+// they missed.
+type Hub struct {
+	mutex          sync.Mutex
+	lastId         int64
+	history        []*model.DomainEvent
+	historySize    int
+	maxSubscribers int
+	subscriptions  map[*Subscription]bool
+	listeners      []func(*model.DomainEvent)
+}
+
+func NewHub(historySize int, maxSubscribers int) *Hub {
+	return &Hub{
+		historySize:    historySize,
+		maxSubscribers: maxSubscribers,
+		subscriptions:  make(map[*Subscription]bool),
+	}
+}
+
+// AddListener calls listener with every event published from now on. Each
+// call runs on its own goroutine so slow listeners don't hold up publishing.
+func (h *Hub) AddListener(listener func(*model.DomainEvent)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.listeners = append(h.listeners, listener)
+}
+
+// Publish numbers the event and sends it to every listener and to the
+// subscribers of its leaderboard
+func (h *Hub) Publish(event *model.DomainEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.lastId++
+	event.Id = h.lastId
+	if event.CreateAt == 0 {
+		event.CreateAt = model.GetMillis()
+	}
+
+	h.history = append(h.history, event)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for subscription := range h.subscriptions {
+		if subscription.LeaderboardId != event.LeaderboardId {
+			continue
+		}
+
+		select {
+		case subscription.Events <- event:
+		default:
+			h.remove(subscription)
+		}
+	}
+
+	for _, listener := range h.listeners {
+		go listener(event)
+	}
+}
+
+// Subscribe starts sending the leaderboard's events to a new subscription.
+// It also returns the events after lastEventId that are still in the
+// history, for subscribers that are reconnecting.
+func (h *Hub) Subscribe(leaderboardId string, lastEventId int64) (*Subscription, []*model.DomainEvent, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.subscriptions) >= h.maxSubscribers {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	subscription := &Subscription{
+		LeaderboardId: leaderboardId,
+		Events:        make(chan *model.DomainEvent, HUB_SUBSCRIPTION_BUFFER),
+	}
+	h.subscriptions[subscription] = true
+
+	missed := []*model.DomainEvent{}
+	if lastEventId > 0 {
+		for _, event := range h.history {
+			if event.Id > lastEventId && event.LeaderboardId == leaderboardId {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return subscription, missed, nil
+}
+
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.remove(subscription)
+}
+
+func (h *Hub) remove(subscription *Subscription) {
+	if h.subscriptions[subscription] {
+		delete(h.subscriptions, subscription)
+		close(subscription.Events)
+	}
+}
+
+func (h *Hub) SubscriberCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.subscriptions)
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub(10, 2)
+
+	listened := make(chan *model.DomainEvent, 10)
+	hub.AddListener(func(event *model.DomainEvent) {
+		listened <- event
+	})
+
+	subscription, missed, err := hub.Subscribe("lb", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(missed) != 0 {
+		t.Fatal("nothing should have been missed")
+	}
+
+	hub.Publish(&model.DomainEvent{Type: model.DOMAIN_EVENT_POINTS_AWARDED, LeaderboardId: "other"})
+	hub.Publish(&model.DomainEvent{Type: model.DOMAIN_EVENT_POINTS_AWARDED, LeaderboardId: "lb"})
+
+	select {
+	case event := <-subscription.Events:
+		if event.LeaderboardId != "lb" || event.Id != 2 || event.CreateAt == 0 {
+			t.Fatal("should only receive the subscribed leaderboard's events")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("should have received the event")
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-listened:
+		case <-time.After(time.Second):
+			t.Fatal("listener should receive every event")
+		}
+	}
+
+	hub.Unsubscribe(subscription)
+	if _, ok := <-subscription.Events; ok {
+		t.Fatal("channel should be closed after unsubscribing")
+	}
+
+	if hub.SubscriberCount() != 0 {
+		t.Fatal("should have no subscribers")
+	}
+}
+
+func TestHubReplay(t *testing.T) {
+	hub := NewHub(3, 10)
+
+	for i := 0; i < 5; i++ {
+		hub.Publish(&model.DomainEvent{Type: model.DOMAIN_EVENT_RANK_CHANGED, LeaderboardId: "lb"})
+	}
+
+	_, missed, err := hub.Subscribe("lb", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(missed) != 2 || missed[0].Id != 4 || missed[1].Id != 5 {
+		t.Fatal("should replay the events after the last one seen")
+	}
+
+	_, missed, _ = hub.Subscribe("lb", 1)
+	if len(missed) != 3 || missed[0].Id != 3 {
+		t.Fatal("should only replay events still in the history")
+	}
+}
+
+func TestHubLimits(t *testing.T) {
+	hub := NewHub(10, 1)
+
+	subscription, _, err := hub.Subscribe("lb", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := hub.Subscribe("lb", 0); err != ErrTooManySubscribers {
+		t.Fatal("should limit the number of subscribers")
+	}
+
+	for i := 0; i < HUB_SUBSCRIPTION_BUFFER+1; i++ {
+		hub.Publish(&model.DomainEvent{LeaderboardId: "lb"})
+	}
+
+	count := 0
+	for range subscription.Events {
+		count++
+	}
+
+	if count != HUB_SUBSCRIPTION_BUFFER {
+		t.Fatal("slow subscribers should be dropped once their buffer is full")
+	}
+
+	if hub.SubscriberCount() != 0 {
+		t.Fatal("dropped subscriber should be removed")
+	}
+}
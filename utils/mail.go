@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(s)
+}
+
+// SendMail sends a plain text email through the configured SMTP server,
+// authenticating if a username is set
+func SendMail(settings *model.EmailSettings, to []string, subject string, body string) error {
+	if len(*settings.SMTPServer) == 0 {
+		return errors.New("No SMTP server is configured")
+	}
+
+	if len(to) == 0 {
+		return errors.New("No recipients for email, subject=" + subject)
+	}
+
+	from := *settings.FromAddress
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %v\r\n", stripNewlines(from))
+	fmt.Fprintf(&msg, "To: %v\r\n", stripNewlines(strings.Join(to, ", ")))
+	fmt.Fprintf(&msg, "Subject: %v\r\n", stripNewlines(subject))
+	fmt.Fprintf(&msg, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if len(*settings.SMTPUsername) > 0 {
+		auth = smtp.PlainAuth("", *settings.SMTPUsername, *settings.SMTPPassword, *settings.SMTPServer)
+	}
+
+	addr := net.JoinHostPort(*settings.SMTPServer, *settings.SMTPPort)
+	if err := smtp.SendMail(addr, auth, from, to, msg.Bytes()); err != nil {
+		return errors.New("Unable to send email, subject=" + subject + ", " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type smtpStub struct {
+	listener   net.Listener
+	recipients []string
+	data       string
+	done       chan bool
+}
+
+// newSmtpStub accepts one connection and records the message sent over it
+func newSmtpStub(t *testing.T) *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &smtpStub{listener: listener, done: make(chan bool)}
+
+	go func() {
+		defer close(stub.done)
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			conn.Write([]byte(line + "\r\n"))
+		}
+
+		reply("220 localhost ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				stub.recipients = append(stub.recipients, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				stub.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return stub
+}
+
+func TestSendMail(t *testing.T) {
+	stub := newSmtpStub(t)
+	defer stub.listener.Close()
+
+	config := &model.Config{}
+	config.SetDefaults()
+
+	host, port, _ := net.SplitHostPort(stub.listener.Addr().String())
+	*config.EmailSettings.SMTPServer = host
+	*config.EmailSettings.SMTPPort = port
+	*config.EmailSettings.FromAddress = "leaderboard@example.com"
+
+	if err := SendMail(&config.EmailSettings, []string{"a@example.com", "b@example.com"}, "Weekly\r\nBcc: evil@example.com", "line one\nline two"); err != nil {
+		t.Fatal(err)
+	}
+
+	<-stub.done
+
+	if len(stub.recipients) != 2 || stub.recipients[1] != "b@example.com" {
+		t.Fatal("should send to every recipient")
+	}
+
+	if !strings.Contains(stub.data, "Subject: Weekly Bcc: evil@example.com\r\n") {
+		t.Fatal("newlines should be stripped from headers")
+	}
+
+	if !strings.Contains(stub.data, "From: leaderboard@example.com\r\n") || !strings.HasSuffix(stub.data, "\r\n\r\nline one\r\nline two\r\n") {
+		t.Fatal("bad message, got " + stub.data)
+	}
+}
+
+func TestSendMailNotConfigured(t *testing.T) {
+	config := &model.Config{}
+	config.SetDefaults()
+
+	if err := SendMail(&config.EmailSettings, []string{"a@example.com"}, "subject", "body"); err == nil {
+		t.Fatal("should fail without an SMTP server")
+	}
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// DeadLetter records a notification that couldn't be delivered
+type DeadLetter struct {
+	Url      string                `json:"url"`
+	Payload  *model.WebhookPayload `json:"payload"`
+	Error    string                `json:"error"`
+	CreateAt int64                 `json:"create_at"`
+}
+
+// Notifier posts messages about domain events to chat incoming webhooks.
+// Its Notify method is meant to be added as a hub listener.
+type Notifier struct {
+	routes          []*model.NotificationRoute
+	templates       map[string]*template.Template
+	topN            int
+	maxRetries      int
+	backoff         time.Duration
+	deadLetterFile  string
+	deadLetterMutex sync.Mutex
+	client          *http.Client
+	leaderboardName func(leaderboardId string) string
+}
+
+// NewNotifier creates a notifier from the settings. leaderboardName looks up
+// the name of an event's leaderboard, which routes are matched against.
+func NewNotifier(settings *model.NotificationSettings, leaderboardName func(leaderboardId string) string) (*Notifier, error) {
+	n := &Notifier{
+		routes:          settings.Routes,
+		templates:       make(map[string]*template.Template),
+		topN:            *settings.TopN,
+		maxRetries:      *settings.MaxRetries,
+		backoff:         time.Duration(*settings.RetryBackoffMillis) * time.Millisecond,
+		deadLetterFile:  *settings.DeadLetterFile,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		leaderboardName: leaderboardName,
+	}
+
+	for kind, text := range model.DEFAULT_NOTIFICATION_TEMPLATES {
+		if override, ok := settings.Templates[kind]; ok {
+			text = override
+		}
+
+		if t, err := template.New(kind).Parse(text); err != nil {
+			return nil, errors.New("Unable to parse notification template, kind=" + kind + ", " + err.Error())
+		} else {
+			n.templates[kind] = t
+		}
+	}
+
+	return n, nil
+}
+
+// Notify posts the message for the event to every route it matches. Rank
+// changes are only announced when they bring someone into the top.
+func (n *Notifier) Notify(event *model.DomainEvent) {
+	kind := event.Type
+	if kind == model.DOMAIN_EVENT_RANK_CHANGED {
+		if !model.EnteredTop(event, n.topN) {
+			return
+		}
+		kind = model.NOTIFICATION_TOP_ENTERED
+	}
+
+	if _, ok := n.templates[kind]; !ok {
+		return
+	}
+
+	name := n.leaderboardName(event.LeaderboardId)
+
+	var text string
+	for _, route := range n.routes {
+		if !route.Matches(name, kind) {
+			continue
+		}
+
+		if len(text) == 0 {
+			var err error
+			if text, err = n.Render(kind, name, event); err != nil {
+				l4g.Error("Unable to render notification, err=%v", err.Error())
+				return
+			}
+		}
+
+		payload := &model.WebhookPayload{
+			Text:     text,
+			Channel:  route.Channel,
+			Username: route.Username,
+		}
+
+		if err := n.Deliver(route.Url, payload); err != nil {
+			l4g.Error("Unable to deliver notification, url=%v, err=%v", route.Url, err.Error())
+		}
+	}
+}
+
+// Render executes the template for the kind of notification with the event
+func (n *Notifier) Render(kind string, leaderboardName string, event *model.DomainEvent) (string, error) {
+	data := make(map[string]interface{}, len(event.Data)+2)
+	for key, value := range event.Data {
+		data[key] = value
+	}
+	data["leaderboard"] = leaderboardName
+	data["top"] = n.topN
+
+	var buf bytes.Buffer
+	if err := n.templates[kind].Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Deliver posts the payload to the webhook, retrying with exponential
+// backoff. Payloads that still can't be delivered, or that the webhook
+// rejects as invalid, go to the dead letter file.
+func (n *Notifier) Deliver(url string, payload *model.WebhookPayload) error {
+	body := []byte(payload.ToJson())
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = n.post(url, body); err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= n.maxRetries {
+			break
+		}
+
+		time.Sleep(n.backoff << uint(attempt))
+	}
+
+	n.writeDeadLetter(&DeadLetter{
+		Url:      url,
+		Payload:  payload,
+		Error:    err.Error(),
+		CreateAt: model.GetMillis(),
+	})
+
+	return err
+}
+
+// post sends the body to the webhook once, reporting whether a failure is
+// worth retrying
+func (n *Notifier) post(url string, body []byte) (bool, error) {
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("Webhook returned status %v", resp.StatusCode)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, err
+	}
+
+	return false, nil
+}
+
+func (n *Notifier) writeDeadLetter(letter *DeadLetter) {
+	if len(n.deadLetterFile) == 0 {
+		return
+	}
+
+	b, err := json.Marshal(letter)
+	if err != nil {
+		l4g.Error("Unable to encode dead letter, err=%v", err.Error())
+		return
+	}
+
+	n.deadLetterMutex.Lock()
+	defer n.deadLetterMutex.Unlock()
+
+	file, err := os.OpenFile(n.deadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		l4g.Error("Unable to open dead letter file, file=%v, err=%v", n.deadLetterFile, err.Error())
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		l4g.Error("Unable to write dead letter, err=%v", err.Error())
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type webhookStub struct {
+	sync.Mutex
+	failures int
+	status   int
+	received []*model.WebhookPayload
+}
+
+func (s *webhookStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(s.status)
+		return
+	}
+
+	var payload model.WebhookPayload
+	json.NewDecoder(r.Body).Decode(&payload)
+	s.received = append(s.received, &payload)
+}
+
+func newTestNotifier(t *testing.T, routes []*model.NotificationRoute) (*Notifier, string) {
+	config := &model.Config{}
+	config.SetDefaults()
+
+	settings := &config.NotificationSettings
+	settings.Routes = routes
+	*settings.RetryBackoffMillis = 1
+	*settings.MaxRetries = 2
+	*settings.DeadLetterFile = filepath.Join(t.TempDir(), "dead.log")
+	settings.Templates[model.DOMAIN_EVENT_BADGE_EARNED] = "{{.username}} got {{.badge_name}}"
+
+	notifier, err := NewNotifier(settings, func(id string) string { return "lb-" + id })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return notifier, *settings.DeadLetterFile
+}
+
+func TestNotifierRouting(t *testing.T) {
+	stub := &webhookStub{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	notifier, _ := newTestNotifier(t, []*model.NotificationRoute{
+		{Leaderboard: "lb-1", Url: server.URL, Channel: "one"},
+		{Leaderboard: "lb-2", Url: server.URL, Channel: "two"},
+		{Url: server.URL, Channel: "badges", Events: []string{model.DOMAIN_EVENT_BADGE_EARNED}},
+	})
+
+	notifier.Notify(model.NewPointsAwardedEvent(&model.LedgerEntry{LeaderboardId: "1", Username: "jwilander", Points: 2, Repository: "org/repo", Number: 5, Title: "Fix", Url: "https://example.com/5"}))
+
+	if len(stub.received) != 1 || stub.received[0].Channel != "one" {
+		t.Fatal("should only post to the leaderboard's routes")
+	}
+
+	if stub.received[0].Text != "jwilander got +2 points on lb-1 for [org/repo#5](https://example.com/5) Fix" {
+		t.Fatal("bad message, got " + stub.received[0].Text)
+	}
+
+	notifier.Notify(model.NewBadgeEarnedEvent(&model.Achievement{LeaderboardId: "2", Username: "jwilander"}, &model.BadgeDefinition{Name: "Reviewer"}))
+
+	if len(stub.received) != 3 || stub.received[2].Channel != "badges" || stub.received[2].Text != "jwilander got Reviewer" {
+		t.Fatal("badge should go to both matching routes with the configured template")
+	}
+
+	notifier.Notify(model.NewRankChangedEvent("1", &model.RankMovement{Username: "a", Rank: 2, PreviousRank: 1}, 5))
+	notifier.Notify(model.NewRankChangedEvent("1", &model.RankMovement{Username: "b", Rank: 3, PreviousRank: 7}, 5))
+
+	if len(stub.received) != 4 || stub.received[3].Text != "b entered the top 3 of lb-1 at #3 with 5 points" {
+		t.Fatal("should only announce users entering the top")
+	}
+}
+
+func TestNotifierRetries(t *testing.T) {
+	stub := &webhookStub{failures: 2, status: http.StatusBadGateway}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	notifier, deadLetterFile := newTestNotifier(t, nil)
+
+	if err := notifier.Deliver(server.URL, &model.WebhookPayload{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stub.received) != 1 || stub.received[0].Text != "hello" {
+		t.Fatal("should have been delivered after retrying")
+	}
+
+	stub.failures = 3
+	if err := notifier.Deliver(server.URL, &model.WebhookPayload{Text: "lost"}); err == nil {
+		t.Fatal("should fail after running out of retries")
+	}
+
+	stub.failures = 1
+	stub.status = http.StatusBadRequest
+	if err := notifier.Deliver(server.URL, &model.WebhookPayload{Text: "invalid"}); err == nil {
+		t.Fatal("should not retry client errors")
+	}
+
+	if stub.failures != 0 || len(stub.received) != 1 {
+		t.Fatal("unexpected deliveries")
+	}
+
+	data, err := os.ReadFile(deadLetterFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatal("both failures should be dead lettered")
+	}
+
+	var letter DeadLetter
+	if err := json.Unmarshal([]byte(lines[0]), &letter); err != nil {
+		t.Fatal(err)
+	}
+
+	if letter.Url != server.URL || letter.Payload.Text != "lost" || !strings.Contains(letter.Error, "502") {
+		t.Fatal("dead letter should record the payload and error")
+	}
+}
+
+func TestNotifierBadTemplate(t *testing.T) {
+	config := &model.Config{}
+	config.SetDefaults()
+	config.NotificationSettings.Templates[model.NOTIFICATION_TOP_ENTERED] = "{{.username"
+
+	if _, err := NewNotifier(&config.NotificationSettings, nil); err == nil {
+		t.Fatal("should fail to parse the template")
+	}
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// OAuthClient logs users in through the configured OAuth 2 provider
+type OAuthClient struct {
+	settings *model.OAuthSettings
+	client   *http.Client
+}
+
+func NewOAuthClient(settings *model.OAuthSettings) *OAuthClient {
+	return &OAuthClient{
+		settings: settings,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExchangeCode trades the code the provider redirected back with for an access token
+func (c *OAuthClient) ExchangeCode(code string, redirectUri string) (string, error) {
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", redirectUri)
+	values.Set("client_id", *c.settings.ClientId)
+	values.Set("client_secret", *c.settings.ClientSecret)
+
+	req, err := http.NewRequest("POST", *c.settings.TokenUrl, strings.NewReader(values.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", errors.New("Error requesting oauth access token, " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error requesting oauth access token, status=%v", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.New("Error decoding oauth access token, " + err.Error())
+	}
+
+	if len(body.AccessToken) == 0 {
+		return "", errors.New("Missing oauth access token, error=" + body.Error)
+	}
+
+	return body.AccessToken, nil
+}
+
+// GetUsername asks the provider who the access token belongs to
+func (c *OAuthClient) GetUsername(accessToken string) (string, error) {
+	req, err := http.NewRequest("GET", *c.settings.UserApiUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", errors.New("Error requesting oauth user, " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error requesting oauth user, status=%v", resp.StatusCode)
+	}
+
+	user := model.StringInterfaceFromJson(resp.Body)
+	if username, ok := user[*c.settings.UsernameField].(string); ok && len(username) > 0 {
+		return username, nil
+	}
+
+	return "", errors.New("Missing oauth username, field=" + *c.settings.UsernameField)
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestOAuthClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			if r.FormValue("client_secret") != "secret" || r.FormValue("code") != "abc" {
+				w.Write([]byte(`{"error": "bad_verification_code"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "xyz", "token_type": "bearer"}`))
+		case "/user":
+			if r.Header.Get("Authorization") != "Bearer xyz" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id": 1, "login": "jwilander"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+
+	settings := &cfg.OAuthSettings
+	*settings.ClientId = "client"
+	*settings.ClientSecret = "secret"
+	*settings.TokenUrl = server.URL + "/login/oauth/access_token"
+	*settings.UserApiUrl = server.URL + "/user"
+
+	client := NewOAuthClient(settings)
+
+	if _, err := client.ExchangeCode("wrong", "http://localhost:8075/oauth/callback"); err == nil {
+		t.Fatal("should have failed with a bad code")
+	}
+
+	token, err := client.ExchangeCode("abc", "http://localhost:8075/oauth/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetUsername("wrong"); err == nil {
+		t.Fatal("should have failed with a bad token")
+	}
+
+	if username, err := client.GetUsername(token); err != nil {
+		t.Fatal(err)
+	} else if username != "jwilander" {
+		t.Fatal("wrong username " + username)
+	}
+}
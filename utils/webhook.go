@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	WEBHOOK_HEADER_EVENT     = "X-Leaderboard-Event"
+	WEBHOOK_HEADER_DELIVERY  = "X-Leaderboard-Delivery"
+	WEBHOOK_HEADER_SIGNATURE = "X-Leaderboard-Signature-256"
+)
+
+// SignPayload returns the signature sent with a webhook payload, the hex
+// HMAC-SHA256 of the payload keyed with the subscription's secret, prefixed
+// with "sha256=" like GitHub's X-Hub-Signature-256
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSender delivers domain events to webhook subscriptions
+type WebhookSender struct {
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+func NewWebhookSender(maxRetries int, backoff time.Duration, timeout time.Duration) *WebhookSender {
+	return &WebhookSender{
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		backoff:    backoff,
+	}
+}
+
+// Send posts the delivery's payload to the subscription, retrying with
+// exponential backoff after server errors, and records the outcome of the
+// last attempt on the delivery
+func (s *WebhookSender) Send(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) {
+	for attempt := 0; ; attempt++ {
+		retry := s.attempt(subscription, delivery)
+
+		delivery.Attempts++
+		delivery.UpdateAt = model.GetMillis()
+
+		if delivery.Success || !retry || attempt >= s.maxRetries {
+			return
+		}
+
+		time.Sleep(s.backoff << uint(attempt))
+	}
+}
+
+// attempt posts the payload once, reporting whether a failure is worth retrying
+func (s *WebhookSender) attempt(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) bool {
+	payload := []byte(delivery.Payload)
+
+	req, err := http.NewRequest("POST", subscription.Url, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "contributor-leaderboard")
+	req.Header.Set(WEBHOOK_HEADER_EVENT, delivery.EventType)
+	req.Header.Set(WEBHOOK_HEADER_DELIVERY, delivery.Id)
+	req.Header.Set(WEBHOOK_HEADER_SIGNATURE, SignPayload(subscription.Secret, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		delivery.StatusCode = 0
+		delivery.Error = err.Error()
+		return true
+	}
+	defer resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+
+	if resp.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("Webhook returned status %v", resp.StatusCode)
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	}
+
+	delivery.Error = ""
+	delivery.Success = true
+	return false
+}
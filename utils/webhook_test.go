@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestSignPayload(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`{"id":1}`))
+
+	if SignPayload("secret", []byte(`{"id":1}`)) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatal("bad signature")
+	}
+
+	if SignPayload("other", []byte(`{"id":1}`)) == SignPayload("secret", []byte(`{"id":1}`)) {
+		t.Fatal("signature should depend on the secret")
+	}
+}
+
+func TestWebhookSender(t *testing.T) {
+	failures := 2
+	status := http.StatusServiceUnavailable
+	var headers http.Header
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(status)
+			return
+		}
+
+		headers = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender(2, time.Millisecond, time.Second)
+
+	subscription := &model.WebhookSubscription{Url: server.URL, Secret: "secret"}
+	event := &model.DomainEvent{Id: 1, Type: model.DOMAIN_EVENT_BADGE_EARNED, LeaderboardId: "lb"}
+
+	delivery := model.NewWebhookDelivery(subscription, event)
+	delivery.PreSave()
+	sender.Send(subscription, delivery)
+
+	if !delivery.Success || delivery.Attempts != 3 || delivery.StatusCode != http.StatusOK || len(delivery.Error) != 0 {
+		t.Fatal("should succeed after retrying")
+	}
+
+	if string(body) != event.ToJson() {
+		t.Fatal("should post the event")
+	}
+
+	if headers.Get(WEBHOOK_HEADER_EVENT) != model.DOMAIN_EVENT_BADGE_EARNED || headers.Get(WEBHOOK_HEADER_DELIVERY) != delivery.Id {
+		t.Fatal("should send the event type and delivery id")
+	}
+
+	if headers.Get(WEBHOOK_HEADER_SIGNATURE) != SignPayload("secret", body) {
+		t.Fatal("should sign the payload")
+	}
+
+	failures = 3
+	delivery = delivery.Redeliver()
+	sender.Send(subscription, delivery)
+
+	if delivery.Success || delivery.Attempts != 3 || delivery.StatusCode != http.StatusServiceUnavailable || len(delivery.Error) == 0 {
+		t.Fatal("should fail after running out of retries")
+	}
+
+	failures = 1
+	status = http.StatusGone
+	delivery = delivery.Redeliver()
+	sender.Send(subscription, delivery)
+
+	if delivery.Success || delivery.Attempts != 1 || delivery.StatusCode != http.StatusGone {
+		t.Fatal("should not retry client errors")
+	}
+}
@@ -0,0 +1,221 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	SHARE_COOKIE             = "LBSHARE"
+	DEFAULT_SHARE_LINK_HOURS = 24 * 7
+	MAX_SHARE_LINK_HOURS     = 24 * 365
+)
+
+func initAccessApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards", getLeaderboardsHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/visibility", requireRole(model.ROLE_ADMIN, updateVisibilityHandler)).Methods("PUT")
+	api.HandleFunc("/leaderboards/{leaderboard}/viewers", requireRole(model.ROLE_ADMIN, getViewersHandler)).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/viewers/{username}", requireRole(model.ROLE_ADMIN, addViewerHandler)).Methods("PUT")
+	api.HandleFunc("/leaderboards/{leaderboard}/viewers/{username}", requireRole(model.ROLE_ADMIN, removeViewerHandler)).Methods("DELETE")
+	api.HandleFunc("/leaderboards/{leaderboard}/share", requireRole(model.ROLE_ADMIN, createShareLinkHandler)).Methods("POST")
+}
+
+// getShareToken returns the share link token sent with the request, either
+// in the share query parameter or in the cookie set when a link is followed
+func getShareToken(r *http.Request) string {
+	if token := r.URL.Query().Get("share"); len(token) > 0 {
+		return token
+	}
+
+	if cookie, err := r.Cookie(SHARE_COOKIE); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// canViewLeaderboard decides whether the request may see the leaderboard.
+// Anyone may see public and unlisted leaderboards, or anyone logged in with
+// AuthSettings.RequireLogin. Private ones are limited to moderators, admins,
+// the leaderboard's viewers and holders of a share link.
+func canViewLeaderboard(r *http.Request, leaderboard *model.Leaderboard) bool {
+	identity := getIdentity(r)
+
+	if !leaderboard.IsPrivate() && (identity != nil || !*Srv.Cfg.AuthSettings.RequireLogin) {
+		return true
+	}
+
+	if identity != nil {
+		if model.RoleAllows(identity.Role, model.ROLE_MODERATOR) {
+			return true
+		}
+
+		if result := <-Srv.Store.Leaderboard().IsViewer(leaderboard.Id, identity.Username); result.Err != nil {
+			l4g.Error("Failed to check leaderboard viewer, err=%v", result.Err.Error())
+		} else if result.Data.(bool) {
+			return true
+		}
+	}
+
+	if token := getShareToken(r); len(token) > 0 {
+		return model.IsValidShareToken(*Srv.Cfg.AuthSettings.ShareLinkSecret, token, leaderboard.Id, model.GetMillis())
+	}
+
+	return false
+}
+
+// denyLeaderboardAccess asks anonymous users to log in and refuses everyone else
+func denyLeaderboardAccess(w http.ResponseWriter, r *http.Request) {
+	if getIdentity(r) != nil {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	if *Srv.Cfg.OAuthSettings.Enable && r.Method == "GET" {
+		if _, ok := mux.Vars(r)["leaderboard"]; !ok {
+			http.Redirect(w, r, "/oauth/login", http.StatusFound)
+			return
+		}
+	}
+
+	unauthorized(w)
+}
+
+// requireLeaderboardAccess guards the pages showing the server's leaderboard.
+// Following a share link sets a cookie so that the link keeps working while
+// browsing between pages.
+func requireLeaderboardAccess(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !canViewLeaderboard(r, Srv.Leaderboard) {
+			denyLeaderboardAccess(w, r)
+			return
+		}
+
+		if token := r.URL.Query().Get("share"); len(token) > 0 {
+			http.SetCookie(w, &http.Cookie{
+				Name:     SHARE_COOKIE,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		handler(w, r)
+	}
+}
+
+// getLeaderboardsHandler lists the leaderboards the request may see. Unlisted
+// leaderboards are only listed for moderators and admins.
+func getLeaderboardsHandler(w http.ResponseWriter, r *http.Request) {
+	identity := getIdentity(r)
+	moderator := identity != nil && model.RoleAllows(identity.Role, model.ROLE_MODERATOR)
+
+	if result := <-Srv.Store.Leaderboard().GetAll(); result.Err != nil {
+		l4g.Error("Failed to load leaderboards, err=%v", result.Err.Error())
+		http.Error(w, "failed to load leaderboards", http.StatusInternalServerError)
+	} else {
+		leaderboards := []*model.Leaderboard{}
+		for _, leaderboard := range result.Data.([]*model.Leaderboard) {
+			if moderator || leaderboard.Visibility == model.LEADERBOARD_VISIBILITY_PUBLIC || (leaderboard.IsPrivate() && canViewLeaderboard(r, leaderboard)) {
+				leaderboards = append(leaderboards, leaderboard)
+			}
+		}
+		writeJson(w, model.LeaderboardListToJson(leaderboards))
+	}
+}
+
+func updateVisibilityHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	visibility := model.MapFromJson(r.Body)["visibility"]
+
+	if result := <-Srv.Store.Leaderboard().UpdateVisibility(leaderboard.Id, visibility); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	leaderboard.Visibility = visibility
+	if leaderboard.Id == Srv.Leaderboard.Id {
+		Srv.Leaderboard.Visibility = visibility
+	}
+
+	l4g.Info("%v made leaderboard %v %v", getActor(r), leaderboard.Name, visibility)
+
+	writeJson(w, leaderboard.ToJson())
+}
+
+func getViewersHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Leaderboard().GetViewers(leaderboard.Id); result.Err != nil {
+		l4g.Error("Failed to load leaderboard viewers, err=%v", result.Err.Error())
+		http.Error(w, "failed to load leaderboard viewers", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.LeaderboardViewerListToJson(result.Data.([]*model.LeaderboardViewer)))
+	}
+}
+
+func addViewerHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	viewer := &model.LeaderboardViewer{LeaderboardId: leaderboard.Id, Username: mux.Vars(r)["username"]}
+
+	if result := <-Srv.Store.Leaderboard().SaveViewer(viewer); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func removeViewerHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Leaderboard().RemoveViewer(leaderboard.Id, mux.Vars(r)["username"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// createShareLinkHandler returns a link to the leaderboard that works without
+// logging in until it expires, after expire_hours or a week by default, and
+// at most a year
+func createShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	hours := getIntParam(r, "expire_hours", DEFAULT_SHARE_LINK_HOURS, MAX_SHARE_LINK_HOURS)
+	expireAt := time.Now().Add(time.Duration(hours)*time.Hour).UnixNano() / int64(time.Millisecond)
+	token := model.NewShareToken(*Srv.Cfg.AuthSettings.ShareLinkSecret, leaderboard.Id, expireAt)
+
+	link := "/?share=" + token
+	if leaderboard.Id != Srv.Leaderboard.Id {
+		link = "/api/v1/leaderboards/" + leaderboard.Name + "/rankings?share=" + token
+	}
+
+	writeCreatedJson(w, model.StringInterfaceToJson(map[string]interface{}{
+		"token":     token,
+		"url":       link,
+		"expire_at": expireAt,
+	}))
+}
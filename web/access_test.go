@@ -0,0 +1,247 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestAuthMiddlewareShareLinks(t *testing.T) {
+	other := &model.Leaderboard{Name: "other", Visibility: model.LEADERBOARD_VISIBILITY_PRIVATE}
+	setupTestServer(true, other)
+
+	handler := authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	status := func(target string, cookie string) int {
+		r := testRequest("GET", target)
+		if len(cookie) > 0 {
+			r.AddCookie(&http.Cookie{Name: SHARE_COOKIE, Value: cookie})
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	mainLink := testShareLink(Srv.Leaderboard)
+	otherLink := testShareLink(other)
+
+	for _, target := range []string{"/api/v1/teams?share=x", "/api/v1/leaderboards?share=x", "/api/v1/leaderboards/main/rankings?share=x"} {
+		if code := status(target, ""); code != http.StatusUnauthorized {
+			t.Fatal("an invalid share link shouldn't get past the login", target, code)
+		}
+	}
+
+	if status("/api/v1/teams", "x") != http.StatusUnauthorized {
+		t.Fatal("an invalid share cookie shouldn't get past the login")
+	}
+
+	if status("/api/v1/leaderboards/main/rankings?share="+mainLink, "") != http.StatusOK || status("/api/v1/leaderboards/main/rankings", mainLink) != http.StatusOK {
+		t.Fatal("a share link should work for its leaderboard")
+	}
+
+	if status("/api/v1/leaderboards/other/rankings?share="+otherLink, "") != http.StatusOK {
+		t.Fatal("a share link should work for the leaderboard named in the route")
+	}
+
+	if status("/api/v1/leaderboards/other/rankings?share="+mainLink, "") != http.StatusUnauthorized {
+		t.Fatal("a share link shouldn't work for another leaderboard")
+	}
+
+	if status("/api/v1/teams?share="+otherLink, "") != http.StatusUnauthorized {
+		t.Fatal("routes without a leaderboard belong to the server's leaderboard")
+	}
+
+	expired := model.NewShareToken(TEST_SHARE_SECRET, Srv.Leaderboard.Id, model.GetMillis()-1)
+	if status("/api/v1/leaderboards/main/rankings?share="+expired, "") != http.StatusUnauthorized {
+		t.Fatal("an expired share link shouldn't work")
+	}
+
+	if status("/event", "") != http.StatusOK {
+		t.Fatal("public paths shouldn't need a login")
+	}
+}
+
+func TestCanViewLeaderboard(t *testing.T) {
+	private := &model.Leaderboard{Name: "private", Visibility: model.LEADERBOARD_VISIBILITY_PRIVATE}
+	unlisted := &model.Leaderboard{Name: "unlisted", Visibility: model.LEADERBOARD_VISIBILITY_UNLISTED}
+	ts := setupTestServer(false, private, unlisted)
+	ts.leaderboards.viewers[private.Id+":friend"] = true
+
+	as := func(r *http.Request, username string, role string) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), IDENTITY_CONTEXT_KEY, &model.Identity{Username: username, Role: role}))
+	}
+
+	anonymous := testRequest("GET", "/")
+	if !canViewLeaderboard(anonymous, Srv.Leaderboard) || !canViewLeaderboard(anonymous, unlisted) {
+		t.Fatal("anyone should see public and unlisted leaderboards")
+	}
+
+	if canViewLeaderboard(anonymous, private) || canViewLeaderboard(as(testRequest("GET", "/"), "stranger", model.ROLE_VIEWER), private) {
+		t.Fatal("private leaderboards should be hidden")
+	}
+
+	if !canViewLeaderboard(as(testRequest("GET", "/"), "friend", model.ROLE_VIEWER), private) {
+		t.Fatal("viewers should see the private leaderboard")
+	}
+
+	if !canViewLeaderboard(as(testRequest("GET", "/"), "mod", model.ROLE_MODERATOR), private) {
+		t.Fatal("moderators should see private leaderboards")
+	}
+
+	if !canViewLeaderboard(testRequest("GET", "/?share="+testShareLink(private)), private) {
+		t.Fatal("a share link should show the private leaderboard")
+	}
+
+	if canViewLeaderboard(testRequest("GET", "/?share="+testShareLink(Srv.Leaderboard)), private) || canViewLeaderboard(testRequest("GET", "/?share=x"), private) {
+		t.Fatal("only a valid share link for the leaderboard should show it")
+	}
+
+	*Srv.Cfg.AuthSettings.RequireLogin = true
+
+	if canViewLeaderboard(anonymous, Srv.Leaderboard) {
+		t.Fatal("anonymous users shouldn't see public leaderboards when login is required")
+	}
+
+	if !canViewLeaderboard(as(testRequest("GET", "/"), "stranger", model.ROLE_VIEWER), Srv.Leaderboard) {
+		t.Fatal("logged in users should see public leaderboards")
+	}
+
+	if !canViewLeaderboard(testRequest("GET", "/?share="+testShareLink(Srv.Leaderboard)), Srv.Leaderboard) {
+		t.Fatal("a share link should work when login is required")
+	}
+}
+
+func TestRequireCsrfToken(t *testing.T) {
+	setupTestServer(false)
+
+	handler := requireCsrfToken(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	status := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	post := func(csrfToken string) *http.Request {
+		r := httptest.NewRequest("POST", "/admin/adjustments", strings.NewReader(CSRF_TOKEN_PARAM+"="+csrfToken))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	r := post(model.NewCsrfToken("session"))
+	r.AddCookie(&http.Cookie{Name: model.SESSION_COOKIE, Value: "session"})
+	if status(r) != http.StatusOK {
+		t.Fatal("the session's token should be accepted")
+	}
+
+	r = post(model.NewCsrfToken("other"))
+	r.AddCookie(&http.Cookie{Name: model.SESSION_COOKIE, Value: "session"})
+	if status(r) != http.StatusForbidden {
+		t.Fatal("another session's token shouldn't be accepted")
+	}
+
+	r = post("")
+	r.AddCookie(&http.Cookie{Name: model.SESSION_COOKIE, Value: "session"})
+	if status(r) != http.StatusForbidden {
+		t.Fatal("a form post without a token shouldn't be accepted")
+	}
+
+	r = post("")
+	r.SetBasicAuth("", "test-admin-token")
+	if status(r) != http.StatusForbidden {
+		t.Fatal("basic auth form posts should need a token too")
+	}
+
+	r = post(model.NewCsrfToken("test-admin-token"))
+	r.SetBasicAuth("", "test-admin-token")
+	if status(r) != http.StatusOK {
+		t.Fatal("the basic auth password's token should be accepted")
+	}
+
+	r = post("")
+	r.Header.Set("Authorization", "Bearer test-admin-token")
+	if status(r) != http.StatusOK {
+		t.Fatal("bearer token requests don't need a token")
+	}
+
+	if status(post("")) != http.StatusForbidden {
+		t.Fatal("anonymous form posts shouldn't be accepted")
+	}
+}
+
+func TestAuthenticateApiChanges(t *testing.T) {
+	setupTestServer(false)
+
+	identity := func(method string, target string, bearer bool) *model.Identity {
+		r := testRequest(method, target)
+		if bearer {
+			r.Header.Set("Authorization", "Bearer test-admin-token")
+		} else {
+			r.SetBasicAuth("", "test-admin-token")
+		}
+		return authenticate(r)
+	}
+
+	if identity("POST", "/api/v1/tokens", false) != nil {
+		t.Fatal("basic auth shouldn't be accepted for API changes")
+	}
+
+	if identity("DELETE", "/api/v1/tokens/abc", false) != nil {
+		t.Fatal("basic auth shouldn't be accepted for API changes")
+	}
+
+	if identity("POST", "/api/v1/tokens", true) == nil {
+		t.Fatal("bearer tokens should be accepted for API changes")
+	}
+
+	if identity("GET", "/api/v1/tokens", false) == nil {
+		t.Fatal("basic auth should be accepted for reading the API")
+	}
+
+	if identity("POST", "/admin/adjustments", false) == nil {
+		t.Fatal("basic auth should be accepted for the admin forms, which check a CSRF token")
+	}
+}
+
+func TestCreateShareLink(t *testing.T) {
+	setupTestServer(false)
+	handler := authMiddleware(Srv.Router)
+	hour := int64(time.Hour / time.Millisecond)
+
+	create := func(expireHours string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		r := testRequest("POST", "/api/v1/leaderboards/main/share?expire_hours="+expireHours)
+		r.Header.Set("Authorization", "Bearer test-admin-token")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w, model.StringInterfaceFromJson(w.Body)
+	}
+
+	w, link := create("2")
+	if w.Code != http.StatusCreated {
+		t.Fatal("should have created the link", w.Code)
+	} else if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Fatal("wrong content type", contentType)
+	} else if expireAt := int64(link["expire_at"].(float64)); expireAt < model.GetMillis()+hour || expireAt > model.GetMillis()+2*hour {
+		t.Fatal("the link should expire in two hours", expireAt)
+	}
+
+	maxExpireAt := model.GetMillis() + int64(MAX_SHARE_LINK_HOURS)*hour
+	if _, link := create("9223372036854775807"); int64(link["expire_at"].(float64)) > maxExpireAt+hour {
+		t.Fatal("the link should expire within a year", link["expire_at"])
+	}
+
+	if _, link := create("1000000000"); int64(link["expire_at"].(float64)) < model.GetMillis() {
+		t.Fatal("a long expiry shouldn't overflow into the past", link["expire_at"])
+	}
+}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initAchievementApi(api *mux.Router) {
+	api.HandleFunc("/badges", getBadgeDefinitions).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/achievements", getAchievementsHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/achievements/evaluate", requireRole(model.ROLE_MODERATOR, evaluateAchievementsHandler)).Methods("POST")
+	api.HandleFunc("/leaderboards/{leaderboard}/users/{username}/badges", getUserBadges).Methods("GET")
+}
+
+func getBadgeDefinition(badgeId string) *model.BadgeDefinition {
+	for _, definition := range Srv.Cfg.AchievementSettings.Badges {
+		if definition.Id == badgeId {
+			return definition
+		}
+	}
+
+	return nil
+}
+
+// evaluateAchievements awards the user any badges their history has earned
+// them that they don't have yet
+func evaluateAchievements(leaderboardId string, username string) error {
+	if !*Srv.Cfg.AchievementSettings.Enable {
+		return nil
+	}
+
+	hchan := Srv.Store.LedgerEntry().GetForUser(leaderboardId, username)
+	achan := Srv.Store.Achievement().GetForUser(leaderboardId, username)
+
+	var history []*model.LedgerEntry
+	if result := <-hchan; result.Err != nil {
+		return result.Err
+	} else {
+		history = result.Data.([]*model.LedgerEntry)
+	}
+
+	existing := make(map[string]bool)
+	if result := <-achan; result.Err != nil {
+		return result.Err
+	} else {
+		for _, achievement := range result.Data.([]*model.Achievement) {
+			existing[achievement.BadgeId] = true
+		}
+	}
+
+	for badgeId, earnedAt := range model.EvaluateAchievements(Srv.Cfg.AchievementSettings.Badges, history) {
+		if existing[badgeId] {
+			continue
+		}
+
+		achievement := &model.Achievement{
+			LeaderboardId: leaderboardId,
+			Username:      username,
+			BadgeId:       badgeId,
+			EarnedAt:      earnedAt,
+		}
+
+		if result := <-Srv.Store.Achievement().Save(achievement); result.Err != nil {
+			return result.Err
+		}
+
+		l4g.Info("User %v earned badge %v", username, badgeId)
+
+		if badge := getBadgeDefinition(badgeId); badge != nil {
+			publish(model.NewBadgeEarnedEvent(achievement, badge))
+		}
+	}
+
+	return nil
+}
+
+// evaluateAllAchievements evaluates the achievements of everyone on the
+// leaderboard, so that new or changed badge definitions apply retroactively
+func evaluateAllAchievements(leaderboardId string) error {
+	if result := <-Srv.Store.LeaderboardEntry().GetRankings(leaderboardId); result.Err != nil {
+		return result.Err
+	} else {
+		for _, entry := range result.Data.([]*model.LeaderboardEntry) {
+			if err := evaluateAchievements(leaderboardId, entry.Username); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getAchievements returns the leaderboard's achievements whose badges are
+// still defined, with the badge definitions filled in
+func getAchievements(leaderboardId string) ([]*model.Achievement, error) {
+	if result := <-Srv.Store.Achievement().GetForLeaderboard(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return withBadgeDefinitions(result.Data.([]*model.Achievement)), nil
+	}
+}
+
+func withBadgeDefinitions(achievements []*model.Achievement) []*model.Achievement {
+	defined := make([]*model.Achievement, 0, len(achievements))
+	for _, achievement := range achievements {
+		if achievement.Badge = getBadgeDefinition(achievement.BadgeId); achievement.Badge != nil {
+			defined = append(defined, achievement)
+		}
+	}
+
+	return defined
+}
+
+// getBadgesByUsername groups the badges earned on the leaderboard by the
+// names they're ranked under, for display
+func getBadgesByUsername(leaderboardId string) map[string][]*model.BadgeDefinition {
+	achievements, err := getAchievements(leaderboardId)
+	if err != nil {
+		l4g.Error("Failed to load achievements, err=%v", err.Error())
+		return map[string][]*model.BadgeDefinition{}
+	}
+
+	byLogin, err := getContributorsByLogin()
+	if err != nil {
+		l4g.Error("Failed to load contributors, err=%v", err.Error())
+		return map[string][]*model.BadgeDefinition{}
+	}
+
+	return model.GroupBadges(achievements, byLogin)
+}
+
+func getBadgeDefinitions(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, model.BadgeDefinitionListToJson(Srv.Cfg.AchievementSettings.Badges))
+}
+
+func getAchievementsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if achievements, err := getAchievements(leaderboard.Id); err != nil {
+		l4g.Error("Failed to load achievements, err=%v", err.Error())
+		http.Error(w, "failed to load achievements", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.AchievementListToJson(achievements))
+	}
+}
+
+func getUserBadges(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Achievement().GetForUser(leaderboard.Id, mux.Vars(r)["username"]); result.Err != nil {
+		l4g.Error("Failed to load achievements, err=%v", result.Err.Error())
+		http.Error(w, "failed to load badges", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.AchievementListToJson(withBadgeDefinitions(result.Data.([]*model.Achievement))))
+	}
+}
+
+func evaluateAchievementsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if err := evaluateAllAchievements(leaderboard.Id); err != nil {
+		l4g.Error("Failed to evaluate achievements, err=%v", err.Error())
+		http.Error(w, "failed to evaluate achievements", http.StatusInternalServerError)
+		return
+	}
+
+	getAchievementsHandler(w, r)
+}
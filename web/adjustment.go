@@ -0,0 +1,195 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	AUDIT_LIMIT = 100
+)
+
+func initAdjustmentApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/adjustments", requireRole(model.ROLE_MODERATOR, createAdjustmentHandler)).Methods("POST")
+	api.HandleFunc("/leaderboards/{leaderboard}/adjustments/{id}/reverse", requireRole(model.ROLE_MODERATOR, reverseAdjustmentHandler)).Methods("POST")
+	api.HandleFunc("/audits", requireRole(model.ROLE_MODERATOR, getAuditsHandler)).Methods("GET")
+}
+
+// getActor names whoever is making a request for the audit log
+func getActor(r *http.Request) string {
+	if identity := getIdentity(r); identity != nil {
+		return identity.Username
+	}
+
+	return "anonymous"
+}
+
+// adjustPoints applies a manual adjustment and records who made it and why
+func adjustPoints(leaderboardId string, adjustment *model.Adjustment, actor string) (*model.LedgerEntry, error) {
+	if err := adjustment.IsValid(); err != nil {
+		return nil, err
+	}
+
+	ledgerEntry := adjustment.ToLedgerEntry(leaderboardId)
+	if err := awardPoints(ledgerEntry); err != nil {
+		return nil, err
+	}
+
+	audit := &model.Audit{
+		Actor:         actor,
+		Action:        model.AUDIT_ACTION_ADJUST_POINTS,
+		LeaderboardId: leaderboardId,
+		Username:      ledgerEntry.Username,
+		Delta:         ledgerEntry.Points,
+		Reason:        ledgerEntry.Reason,
+		RelatedId:     ledgerEntry.Id,
+	}
+
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error("Failed to save audit, err=%v", result.Err.Error())
+	}
+
+	l4g.Info("%v adjusted points for %v by %v, reason=%v", actor, ledgerEntry.Username, ledgerEntry.Points, ledgerEntry.Reason)
+
+	return ledgerEntry, nil
+}
+
+// reverseAdjustment undoes the adjustment with the given ledger entry id
+func reverseAdjustment(leaderboardId string, id string, reason string, actor string) (*model.LedgerEntry, error) {
+	var entry *model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		entry = result.Data.(*model.LedgerEntry)
+	}
+
+	if entry.LeaderboardId != leaderboardId {
+		return nil, errors.New("Adjustment is not on this leaderboard, id=" + id)
+	}
+
+	var reversal *model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().GetByRelatedId(entry.Id); result.Err != nil {
+		return nil, result.Err
+	} else if r, err := model.ReverseAdjustment(entry, result.Data.([]*model.LedgerEntry), reason); err != nil {
+		return nil, err
+	} else {
+		reversal = r
+	}
+
+	if err := awardPoints(reversal); err != nil {
+		return nil, err
+	}
+
+	audit := &model.Audit{
+		Actor:         actor,
+		Action:        model.AUDIT_ACTION_REVERSE_ADJUSTMENT,
+		LeaderboardId: leaderboardId,
+		Username:      reversal.Username,
+		Delta:         reversal.Points,
+		Reason:        reversal.Reason,
+		RelatedId:     reversal.Id,
+	}
+
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error("Failed to save audit, err=%v", result.Err.Error())
+	}
+
+	l4g.Info("%v reversed adjustment %v for %v, reason=%v", actor, entry.Id, reversal.Username, reversal.Reason)
+
+	return reversal, nil
+}
+
+func createAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	adjustment := model.AdjustmentFromJson(r.Body)
+	if adjustment == nil {
+		http.Error(w, "invalid adjustment", http.StatusBadRequest)
+		return
+	}
+
+	if ledgerEntry, err := adjustPoints(leaderboard.Id, adjustment, getActor(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	} else {
+		writeCreatedJson(w, ledgerEntry.ToJson())
+	}
+}
+
+func reverseAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	if reversal, err := reverseAdjustment(leaderboard.Id, mux.Vars(r)["id"], props["reason"], getActor(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	} else {
+		writeCreatedJson(w, reversal.ToJson())
+	}
+}
+
+func getAuditsHandler(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.Audit().Get(r.URL.Query().Get("username"), AUDIT_LIMIT); result.Err != nil {
+		l4g.Error("Failed to load audits, err=%v", result.Err.Error())
+		http.Error(w, "failed to load audits", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.AuditListToJson(result.Data.([]*model.Audit)))
+	}
+}
+
+func adjustmentsPage(w http.ResponseWriter, r *http.Request) {
+	page := NewHtmlTemplatePage("adjustments", "Adjustments")
+
+	page.Props["Error"] = r.URL.Query().Get("error")
+	page.Props["CsrfToken"] = getCsrfToken(r)
+
+	if result := <-Srv.Store.Audit().Get("", AUDIT_LIMIT); result.Err != nil {
+		l4g.Error("Failed to load audits, err=%v", result.Err.Error())
+	} else {
+		page.Props["Audits"] = result.Data.([]*model.Audit)
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func submitAdjustment(w http.ResponseWriter, r *http.Request) {
+	points, _ := strconv.Atoi(r.FormValue("points"))
+
+	adjustment := &model.Adjustment{
+		Username: r.FormValue("username"),
+		Points:   points,
+		Reason:   r.FormValue("reason"),
+	}
+
+	if _, err := adjustPoints(Srv.Leaderboard.Id, adjustment, getActor(r)); err != nil {
+		redirectWithError(w, r, "/admin/adjustments", err)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/adjustments", http.StatusSeeOther)
+}
+
+func submitReversal(w http.ResponseWriter, r *http.Request) {
+	if _, err := reverseAdjustment(Srv.Leaderboard.Id, mux.Vars(r)["id"], r.FormValue("reason"), getActor(r)); err != nil {
+		redirectWithError(w, r, "/admin/adjustments", err)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/adjustments", http.StatusSeeOther)
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, path string, err error) {
+	http.Redirect(w, r, path+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func InitApi() {
+	l4g.Debug("Initializing api routes")
+
+	api := Srv.Router.PathPrefix("/api/v1").Subrouter()
+
+	leaderboards := api.PathPrefix("/leaderboards/{leaderboard}").Subrouter()
+	leaderboards.HandleFunc("/rankings", getRankingsHandler).Methods("GET")
+	leaderboards.HandleFunc("/users/{username}/history", getUserHistory).Methods("GET")
+
+	initContributorApi(api)
+	initTeamApi(api)
+	initSeasonApi(api)
+	initAchievementApi(api)
+	initStreakApi(api)
+	initNewcomerApi(api)
+	initAdjustmentApi(api)
+	initAuthApi(api)
+	initAccessApi(api)
+	initProfileApi(api)
+	initSnapshotApi(api)
+	initLiveApi(api)
+	initDigestApi(api)
+	initWebhookApi(api)
+	initQueueApi(api)
+	initArchiveApi(api)
+	initRecomputeApi(api)
+}
+
+// getLeaderboard looks up the leaderboard named in the request's route,
+// writing an error and returning nil if there is no such leaderboard or the
+// request may not see it.
+func getLeaderboard(w http.ResponseWriter, r *http.Request) *model.Leaderboard {
+	name := mux.Vars(r)["leaderboard"]
+
+	var leaderboard *model.Leaderboard
+	if len(name) == 0 || name == Srv.Leaderboard.Name {
+		leaderboard = Srv.Leaderboard
+	} else if result := <-Srv.Store.Leaderboard().GetByName(name); result.Err != nil {
+		l4g.Debug("Unable to find leaderboard, err=%v", result.Err.Error())
+		http.Error(w, "leaderboard not found", http.StatusNotFound)
+		return nil
+	} else {
+		leaderboard = result.Data.(*model.Leaderboard)
+	}
+
+	if !canViewLeaderboard(r, leaderboard) {
+		denyLeaderboardAccess(w, r)
+		return nil
+	}
+
+	return leaderboard
+}
+
+func writeJson(w http.ResponseWriter, json string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(json))
+}
+
+// writeCreatedJson answers with a 201, setting the content type first since
+// headers can't be changed once the status is written
+func writeCreatedJson(w http.ResponseWriter, json string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(json))
+}
+
+func getUserHistory(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	username := mux.Vars(r)["username"]
+
+	if result := <-Srv.Store.LedgerEntry().GetForUser(leaderboard.Id, username); result.Err != nil {
+		l4g.Error("Failed to load history, err=%v", result.Err.Error())
+		http.Error(w, "failed to load history", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.LedgerEntryListToJson(result.Data.([]*model.LedgerEntry)))
+	}
+}
+
+func getRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if rankings, err := getRankings(leaderboard.Id, rankByActiveScore(r)); err != nil {
+		l4g.Error("Failed to load rankings, err=%v", err.Error())
+		http.Error(w, "failed to load rankings", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.LeaderboardEntryListToJson(rankings))
+	}
+}
@@ -0,0 +1,285 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	MAX_REPLAY_DELIVERIES = 1000
+)
+
+func initArchiveApi(api *mux.Router) {
+	api.HandleFunc("/deliveries", requireRole(model.ROLE_ADMIN, searchDeliveriesHandler)).Methods("GET")
+	api.HandleFunc("/deliveries/replay", requireRole(model.ROLE_ADMIN, replayDeliveriesHandler)).Methods("POST")
+	api.HandleFunc("/deliveries/{id}", requireRole(model.ROLE_ADMIN, getDeliveryHandler)).Methods("GET")
+}
+
+// ReplayDeliveries runs the selected archived deliveries through the current
+// scoring rules, oldest first. Points already in the ledger are skipped, and
+// unless the request commits nothing is awarded, the report only lists what
+// would be.
+func ReplayDeliveries(request *model.ReplayRequest, actor string) (*model.ReplayReport, error) {
+	var deliveries []*model.ArchivedDelivery
+
+	if len(request.Ids) > 0 {
+		if result := <-Srv.Store.Archive().GetByIds(request.Ids); result.Err != nil {
+			return nil, result.Err
+		} else {
+			deliveries = result.Data.([]*model.ArchivedDelivery)
+		}
+	} else {
+		search, err := request.ToSearch(MAX_REPLAY_DELIVERIES)
+		if err != nil {
+			return nil, err
+		}
+
+		if result := <-Srv.Store.Archive().Search(search); result.Err != nil {
+			return nil, result.Err
+		} else {
+			deliveries = result.Data.([]*model.ArchivedDelivery)
+		}
+
+		for i, j := 0, len(deliveries)-1; i < j; i, j = i+1, j-1 {
+			deliveries[i], deliveries[j] = deliveries[j], deliveries[i]
+		}
+	}
+
+	report := &model.ReplayReport{Commit: request.Commit, Results: []*model.ReplayResult{}}
+	for _, delivery := range deliveries {
+		report.Add(replayDelivery(delivery, request.Commit))
+	}
+
+	if request.Commit {
+		audit := &model.Audit{
+			Actor:         actor,
+			Action:        model.AUDIT_ACTION_REPLAY_DELIVERIES,
+			LeaderboardId: Srv.Leaderboard.Id,
+			Delta:         report.Points,
+			Reason:        fmt.Sprintf("Replayed %v deliveries", len(report.Results)),
+		}
+
+		if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+			l4g.Error("Failed to save audit, err=%v", result.Err.Error())
+		}
+
+		settleReplay(report)
+
+		l4g.Info("%v replayed %v deliveries for %v points", actor, len(report.Results), report.Points)
+	}
+
+	return report, nil
+}
+
+// settleReplay evaluates the achievements of the users a committed replay
+// awarded points to, and publishes the rank changes once for the whole replay
+// rather than for every award
+func settleReplay(report *model.ReplayReport) {
+	evaluated := make(map[string]bool)
+	for _, result := range report.Results {
+		for _, ledgerEntry := range result.Awarded {
+			if evaluated[ledgerEntry.Username] {
+				continue
+			}
+			evaluated[ledgerEntry.Username] = true
+
+			if err := evaluateAchievements(ledgerEntry.LeaderboardId, ledgerEntry.Username); err != nil {
+				l4g.Error("Unable to evaluate achievements, err=%v", err.Error())
+			}
+		}
+	}
+
+	if err := publishRankChanges(Srv.Leaderboard.Id); err != nil {
+		l4g.Error("Unable to publish rank changes, err=%v", err.Error())
+	}
+}
+
+func replayDelivery(delivery *model.ArchivedDelivery, commit bool) *model.ReplayResult {
+	event := model.EventFromJson(strings.NewReader(delivery.Payload))
+	if event == nil {
+		return &model.ReplayResult{ArchiveId: delivery.Id, EventType: delivery.EventType, Error: "Unable to parse archived delivery"}
+	}
+
+	replayed := replayEvent(delivery.EventType, event, delivery.CreateAt, commit)
+	replayed.ArchiveId = delivery.Id
+
+	return replayed
+}
+
+// replayEvent scores an event as if it had been received at createAt,
+// skipping points that are already in the ledger. Points are only awarded
+// when committing, and without publishing the historical awards.
+func replayEvent(eventType string, event *model.Event, createAt int64, commit bool) *model.ReplayResult {
+	replayed := &model.ReplayResult{
+		EventType: eventType,
+		Awarded:   []*model.LedgerEntry{},
+		Skipped:   []*model.LedgerEntry{},
+	}
+
+	award := func(ledgerEntry *model.LedgerEntry) error {
+		if ledgerEntry.CreateAt == 0 {
+			ledgerEntry.CreateAt = createAt
+		}
+
+		if awarded, err := findAwarded(ledgerEntry); err != nil {
+			return err
+		} else if awarded {
+			replayed.Skipped = append(replayed.Skipped, ledgerEntry)
+			return nil
+		}
+
+		if commit {
+			if err := recordPoints(ledgerEntry); err != nil {
+				return err
+			}
+		}
+
+		replayed.Awarded = append(replayed.Awarded, ledgerEntry)
+		return nil
+	}
+
+	if err := scoreEvent(eventType, event, award); err != nil {
+		replayed.Error = err.Error()
+	}
+
+	return replayed
+}
+
+func searchDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	search, err := model.DeliverySearchFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if result := <-Srv.Store.Archive().Search(search); result.Err != nil {
+		l4g.Error("Failed to search deliveries, err=%v", result.Err.Error())
+		http.Error(w, "failed to search deliveries", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.ArchivedDeliveryListToJson(result.Data.([]*model.ArchivedDelivery)))
+	}
+}
+
+func getDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.Archive().Get(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, "delivery not found", http.StatusNotFound)
+	} else {
+		writeJson(w, result.Data.(*model.ArchivedDelivery).ToJson())
+	}
+}
+
+func replayDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	request := model.ReplayRequestFromJson(r.Body)
+	if request == nil {
+		http.Error(w, "invalid replay request", http.StatusBadRequest)
+		return
+	}
+
+	if report, err := ReplayDeliveries(request, getActor(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	} else {
+		writeJson(w, report.ToJson())
+	}
+}
+
+func deliveriesPage(w http.ResponseWriter, r *http.Request) {
+	renderDeliveriesPage(w, r, nil, "")
+}
+
+func submitReplay(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	request := &model.ReplayRequest{
+		Ids:    r.Form["id"],
+		Commit: r.FormValue("mode") == "commit",
+	}
+
+	if len(request.Ids) == 0 {
+		renderDeliveriesPage(w, r, nil, "Select the deliveries to replay")
+		return
+	}
+
+	if report, err := ReplayDeliveries(request, getActor(r)); err != nil {
+		renderDeliveriesPage(w, r, nil, err.Error())
+	} else {
+		renderDeliveriesPage(w, r, report, "")
+	}
+}
+
+// renderDeliveriesPage shows the archived deliveries matching the search in
+// the query string, along with the report of a replay
+func renderDeliveriesPage(w http.ResponseWriter, r *http.Request, report *model.ReplayReport, errMessage string) {
+	page := NewHtmlTemplatePage("deliveries", "Deliveries")
+
+	query := r.URL.Query()
+	page.Props["EventType"] = query.Get("event_type")
+	page.Props["Username"] = query.Get("username")
+	page.Props["Since"] = query.Get("since")
+	page.Props["Until"] = query.Get("until")
+	page.Props["Report"] = report
+	page.Props["Error"] = errMessage
+	page.Props["CsrfToken"] = getCsrfToken(r)
+
+	if search, err := model.DeliverySearchFromQuery(query); err != nil {
+		page.Props["Error"] = err.Error()
+	} else if result := <-Srv.Store.Archive().Search(search); result.Err != nil {
+		l4g.Error("Failed to search deliveries, err=%v", result.Err.Error())
+	} else {
+		deliveries := result.Data.([]*model.ArchivedDelivery)
+		page.Props["Deliveries"] = deliveries
+
+		if search.Page > 0 {
+			page.Props["PrevUrl"] = deliveriesPageUrl(query, search.Page-1)
+		}
+
+		if len(deliveries) == search.PerPage {
+			page.Props["NextUrl"] = deliveriesPageUrl(query, search.Page+1)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func deliveriesPageUrl(query url.Values, page int) string {
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Set("page", strconv.Itoa(page))
+
+	return "/admin/deliveries?" + values.Encode()
+}
+
+func deliveryPage(w http.ResponseWriter, r *http.Request) {
+	var delivery *model.ArchivedDelivery
+	if result := <-Srv.Store.Archive().Get(mux.Vars(r)["id"]); result.Err != nil {
+		http.NotFound(w, r)
+		return
+	} else {
+		delivery = result.Data.(*model.ArchivedDelivery)
+	}
+
+	page := NewHtmlTemplatePage("delivery", "Delivery")
+	page.Props["Delivery"] = delivery
+	page.Props["CsrfToken"] = getCsrfToken(r)
+
+	var payload bytes.Buffer
+	if err := json.Indent(&payload, []byte(delivery.Payload), "", "  "); err != nil {
+		page.Props["Payload"] = delivery.Payload
+	} else {
+		page.Props["Payload"] = payload.String()
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
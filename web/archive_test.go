@@ -0,0 +1,31 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestReplayEventCommit(t *testing.T) {
+	ts := setupTestServer(false)
+
+	event := &model.Event{Action: "opened"}
+	event.Issue.User.Login = "author"
+	event.Issue.User.Type = "User"
+	event.Issue.HtmlUrl = "https://github.com/org/repo/issues/1"
+
+	// There is no hub, so publishing the historical award would panic
+	replayed := replayEvent(model.EVENT_TYPE_ISSUES, event, 1000, true)
+	if len(replayed.Error) > 0 {
+		t.Fatal(replayed.Error)
+	}
+
+	if len(replayed.Awarded) != 1 || len(ts.ledger.entries) != 1 || ts.ledger.entries[0].CreateAt != 1000 {
+		t.Fatal("the issue should have been awarded as of when it was received", ts.ledger.entries)
+	}
+
+	replayed = replayEvent(model.EVENT_TYPE_ISSUES, event, 2000, true)
+	if len(replayed.Awarded) != 0 || len(replayed.Skipped) != 1 || len(ts.ledger.entries) != 1 {
+		t.Fatal("the issue shouldn't be awarded twice", ts.ledger.entries)
+	}
+}
@@ -0,0 +1,251 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+type contextKey string
+
+const (
+	IDENTITY_CONTEXT_KEY contextKey = "identity"
+
+	CSRF_TOKEN_PARAM = "csrf_token"
+)
+
+func initAuthApi(api *mux.Router) {
+	api.HandleFunc("/users/me", getMe).Methods("GET")
+	api.HandleFunc("/tokens", requireRole(model.ROLE_ADMIN, getApiTokens)).Methods("GET")
+	api.HandleFunc("/tokens", requireRole(model.ROLE_ADMIN, createApiToken)).Methods("POST")
+	api.HandleFunc("/tokens/{id}", requireRole(model.ROLE_ADMIN, deleteApiToken)).Methods("DELETE")
+}
+
+// isApiChange returns true for API requests that may change something
+func isApiChange(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/") && r.Method != "GET" && r.Method != "HEAD"
+}
+
+// authenticate works out who made the request from, in order, the configured
+// admin token, an API token or a session cookie. The admin token and API
+// tokens are accepted as bearer tokens or as the password of basic auth so
+// that browsers can prompt for them. It returns nil for anonymous requests.
+// Browsers send basic auth and cookies along with other sites' form posts
+// too, so changes through the API are only accepted with a bearer token.
+func authenticate(r *http.Request) *model.Identity {
+	token := ""
+	username, password, basic := r.BasicAuth()
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	} else if isApiChange(r) {
+		return nil
+	} else if basic {
+		token = password
+	}
+
+	if len(token) > 0 {
+		adminToken := *Srv.Cfg.AdminToken
+		if len(adminToken) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			if len(username) == 0 {
+				username = "admin"
+			}
+			return &model.Identity{Username: username, Role: model.ROLE_ADMIN}
+		}
+
+		if result := <-Srv.Store.ApiToken().GetByHash(model.HashSecret(token)); result.Err == nil {
+			apiToken := result.Data.(*model.ApiToken)
+			Srv.Store.ApiToken().UpdateLastUsed(apiToken.Id, model.GetMillis())
+			return &model.Identity{Username: apiToken.Name, Role: apiToken.Role}
+		}
+
+		return nil
+	}
+
+	if cookie, err := r.Cookie(model.SESSION_COOKIE); err == nil && len(cookie.Value) > 0 {
+		if result := <-Srv.Store.Session().GetByHash(model.HashSecret(cookie.Value)); result.Err == nil {
+			session := result.Data.(*model.Session)
+			if session.IsExpired() {
+				Srv.Store.Session().Delete(session.Hash)
+				return nil
+			}
+			return &model.Identity{Username: session.Username, Role: session.Role}
+		}
+	}
+
+	return nil
+}
+
+// getIdentity returns who made the request, or nil if they're anonymous
+func getIdentity(r *http.Request) *model.Identity {
+	if identity, ok := r.Context().Value(IDENTITY_CONTEXT_KEY).(*model.Identity); ok {
+		return identity
+	}
+
+	return authenticate(r)
+}
+
+// isPublicPath returns true for the paths that must work without logging in,
+// even when AuthSettings.RequireLogin is set
+func isPublicPath(path string) bool {
+	return path == "/event" || path == "/logout" || strings.HasPrefix(path, "/static/") || strings.HasPrefix(path, "/oauth/")
+}
+
+// hasValidShareLink reports whether the request carries a valid share link
+// for the leaderboard it targets, the one named in its route or else the
+// server's leaderboard
+func hasValidShareLink(r *http.Request) bool {
+	token := getShareToken(r)
+	if len(token) == 0 {
+		return false
+	}
+
+	leaderboard := Srv.Leaderboard
+
+	var match mux.RouteMatch
+	if Srv.Router.Match(r, &match) {
+		if name, ok := match.Vars["leaderboard"]; ok && name != Srv.Leaderboard.Name {
+			if result := <-Srv.Store.Leaderboard().GetByName(name); result.Err != nil {
+				return false
+			} else {
+				leaderboard = result.Data.(*model.Leaderboard)
+			}
+		}
+	}
+
+	return model.IsValidShareToken(*Srv.Cfg.AuthSettings.ShareLinkSecret, token, leaderboard.Id, model.GetMillis())
+}
+
+// authMiddleware authenticates every request, making the identity available
+// to handlers through the request's context. With AuthSettings.RequireLogin
+// anonymous requests are refused everywhere but the public paths, unless
+// they carry a valid share link for the leaderboard they target.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := authenticate(r)
+
+		if identity == nil && *Srv.Cfg.AuthSettings.RequireLogin && !isPublicPath(r.URL.Path) && !hasValidShareLink(r) {
+			if *Srv.Cfg.OAuthSettings.Enable && r.Method == "GET" && !strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Redirect(w, r, "/oauth/login", http.StatusFound)
+				return
+			}
+
+			unauthorized(w)
+			return
+		}
+
+		if identity != nil {
+			r = r.WithContext(context.WithValue(r.Context(), IDENTITY_CONTEXT_KEY, identity))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireRole only lets requests through from users with at least the given
+// role. Anonymous requests get a 401 so that browsers prompt for a token.
+func requireRole(role string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity := getIdentity(r)
+
+		if identity == nil {
+			unauthorized(w)
+			return
+		}
+
+		if !model.RoleAllows(identity.Role, role) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// getCsrfToken returns the token the request's forms must carry, derived
+// from the credential the browser sends along with every request, its basic
+// auth password or session cookie. It's empty for anonymous requests.
+func getCsrfToken(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return model.NewCsrfToken(password)
+	}
+
+	if cookie, err := r.Cookie(model.SESSION_COOKIE); err == nil && len(cookie.Value) > 0 {
+		return model.NewCsrfToken(cookie.Value)
+	}
+
+	return ""
+}
+
+// requireCsrfToken refuses form posts without the CSRF token of the
+// credential they were sent with, so that other sites can't post forms on a
+// logged in user's behalf. Requests with a bearer token aren't sent by
+// browsers on their own and don't need one.
+func requireCsrfToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			expected := getCsrfToken(r)
+			if len(expected) == 0 || subtle.ConstantTimeCompare([]byte(r.FormValue(CSRF_TOKEN_PARAM)), []byte(expected)) != 1 {
+				http.Error(w, "invalid csrf token", http.StatusForbidden)
+				return
+			}
+		}
+
+		handler(w, r)
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="leaderboard"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+func getMe(w http.ResponseWriter, r *http.Request) {
+	if identity := getIdentity(r); identity == nil {
+		unauthorized(w)
+	} else {
+		writeJson(w, identity.ToJson())
+	}
+}
+
+func getApiTokens(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.ApiToken().GetAll(); result.Err != nil {
+		l4g.Error("Failed to load api tokens, err=%v", result.Err.Error())
+		http.Error(w, "failed to load api tokens", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.ApiTokenListToJson(result.Data.([]*model.ApiToken)))
+	}
+}
+
+func createApiToken(w http.ResponseWriter, r *http.Request) {
+	token := model.ApiTokenFromJson(r.Body)
+	if token == nil {
+		http.Error(w, "invalid api token", http.StatusBadRequest)
+		return
+	}
+
+	token.Id = ""
+	token.Hash = ""
+
+	if result := <-Srv.Store.ApiToken().Save(token); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l4g.Info("%v created api token %v with role %v", getActor(r), token.Name, token.Role)
+
+	writeCreatedJson(w, token.ToJson())
+}
+
+func deleteApiToken(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.ApiToken().Delete(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
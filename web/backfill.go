@@ -0,0 +1,73 @@
+package web
+
+import (
+	"fmt"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// BackfillEvents scores historical events, oldest first, as of when they
+// happened. Points already in the ledger are skipped, so a backfill can be
+// run again or overlap events that were scored when they happened. Unless
+// commit is set the report only lists the points that would be awarded.
+func BackfillEvents(events []*model.BackfillEvent, commit bool, actor string) *model.ReplayReport {
+	report := &model.ReplayReport{Commit: commit, Results: []*model.ReplayResult{}}
+	for _, event := range events {
+		report.Add(replayEvent(event.Type, event.Event, event.CreateAt, commit))
+	}
+
+	if commit {
+		audit := &model.Audit{
+			Actor:         actor,
+			Action:        model.AUDIT_ACTION_BACKFILL,
+			LeaderboardId: Srv.Leaderboard.Id,
+			Delta:         report.Points,
+			Reason:        fmt.Sprintf("Backfilled %v events", len(events)),
+		}
+
+		if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+			l4g.Error("Failed to save audit, err=%v", result.Err.Error())
+		}
+
+		settleReplay(report)
+
+		l4g.Info("%v backfilled %v events for %v points", actor, len(events), report.Points)
+	}
+
+	return report
+}
+
+// getGitIdentities credits commit author emails to the contributors they
+// belong to, or to the usernames in mapping
+func getGitIdentities(mapping map[string]string) (model.GitIdentities, error) {
+	cchan := Srv.Store.Contributor().GetAll()
+	achan := Srv.Store.Contributor().GetAllAccounts()
+
+	var contributors []*model.Contributor
+	if result := <-cchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		contributors = result.Data.([]*model.Contributor)
+	}
+
+	if result := <-achan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		return model.NewGitIdentities(contributors, result.Data.([]*model.ContributorAccount), mapping), nil
+	}
+}
+
+// BackfillGitHistory scores the commits of a git branch like BackfillEvents,
+// crediting them with the identities of the leaderboard's contributors and
+// the email to username mapping
+func BackfillGitHistory(history *model.GitHistory, commits []*model.GitCommit, mapping map[string]string, commit bool, actor string) (*model.ReplayReport, error) {
+	identities, err := getGitIdentities(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	history.Identities = identities
+
+	return BackfillEvents(history.Events(commits), commit, actor), nil
+}
@@ -0,0 +1,179 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+const (
+	BADGE_METRIC_RANK   = "rank"
+	BADGE_METRIC_POINTS = "points"
+	BADGE_METRIC_STREAK = "streak"
+
+	DEFAULT_BADGE_LABEL = "top contributor"
+	DEFAULT_BADGE_COLOR = "brightgreen"
+	BADGE_CACHE_CONTROL = "public, max-age=300, s-maxage=300"
+)
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%v %v", count, noun)
+	}
+
+	return fmt.Sprintf("%v %vs", count, noun)
+}
+
+// badgeMessage describes the ranked user by the requested metric, or by
+// rank and points if there is none
+func badgeMessage(metric string, rank int, entry *model.LeaderboardEntry, streak *model.Streak) string {
+	switch metric {
+	case BADGE_METRIC_RANK:
+		return fmt.Sprintf("#%v", rank)
+	case BADGE_METRIC_POINTS:
+		return pluralize(entry.Points, "point")
+	case BADGE_METRIC_STREAK:
+		return pluralize(streak.Current, streak.Period)
+	}
+
+	return fmt.Sprintf("#%v with %v", rank, pluralize(entry.Points, "point"))
+}
+
+// writeBadge renders the leaderboard's badge, answering with a 304 if the
+// client already has it so that caching proxies like GitHub's camo can
+// revalidate cheaply
+func writeBadge(w http.ResponseWriter, r *http.Request, leaderboard *model.Leaderboard, label string, message string, color string) {
+	svg := utils.RenderBadge(label, message, color)
+
+	sum := sha256.Sum256([]byte(svg))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if leaderboard.IsPrivate() {
+		w.Header().Set("Cache-Control", PRIVATE_CACHE_CONTROL)
+	} else {
+		w.Header().Set("Cache-Control", BADGE_CACHE_CONTROL)
+	}
+
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if strings.TrimSpace(match) == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write([]byte(svg))
+}
+
+func getBadgeLabel(r *http.Request) string {
+	if label := r.URL.Query().Get("label"); len(label) > 0 {
+		return label
+	}
+
+	return DEFAULT_BADGE_LABEL
+}
+
+// userBadge shows where the user stands on the leaderboard
+func userBadge(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	username := mux.Vars(r)["login"]
+	metric := r.URL.Query().Get("metric")
+	label := getBadgeLabel(r)
+
+	rankings, err := getRankings(leaderboard.Id, false)
+	if err != nil {
+		l4g.Error("Failed to load rankings, err=%v", err.Error())
+		http.Error(w, "failed to load rankings", http.StatusInternalServerError)
+		return
+	}
+
+	name, _, err := getProfileLogins(username)
+	if err != nil {
+		l4g.Error("Failed to load contributors, err=%v", err.Error())
+		http.Error(w, "failed to load contributors", http.StatusInternalServerError)
+		return
+	}
+
+	for i, entry := range model.NewSeasonStandings("", rankings) {
+		if !strings.EqualFold(entry.Username, name) {
+			continue
+		}
+
+		streak := &model.Streak{Period: *Srv.Cfg.StreakSettings.Period}
+		if metric == BADGE_METRIC_STREAK {
+			if streak, err = getUserStreak(leaderboard.Id, username); err != nil {
+				l4g.Error("Failed to load streak, err=%v", err.Error())
+				http.Error(w, "failed to load streak", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		message := badgeMessage(metric, entry.Rank, rankings[i], streak)
+		writeBadge(w, r, leaderboard, label, message, utils.BadgeColor(r.URL.Query().Get("color"), DEFAULT_BADGE_COLOR))
+		return
+	}
+
+	writeBadge(w, r, leaderboard, label, "unranked", utils.BadgeColor("", "lightgrey"))
+}
+
+// topBadge shows who leads the leaderboard
+func topBadge(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	metric := r.URL.Query().Get("metric")
+	label := getBadgeLabel(r)
+
+	rankings, err := getRankings(leaderboard.Id, false)
+	if err != nil {
+		l4g.Error("Failed to load rankings, err=%v", err.Error())
+		http.Error(w, "failed to load rankings", http.StatusInternalServerError)
+		return
+	}
+
+	if len(rankings) == 0 {
+		writeBadge(w, r, leaderboard, label, "nobody yet", utils.BadgeColor("", "lightgrey"))
+		return
+	}
+
+	top := rankings[0]
+	message := fmt.Sprintf("%v with %v", top.Username, pluralize(top.Points, "point"))
+
+	if metric == BADGE_METRIC_STREAK {
+		streaks, err := getStreaks(leaderboard.Id)
+		if err != nil {
+			l4g.Error("Failed to load streaks, err=%v", err.Error())
+			http.Error(w, "failed to load streaks", http.StatusInternalServerError)
+			return
+		}
+
+		var longest *model.Streak
+		for _, streak := range streaks {
+			if longest == nil || streak.Current > longest.Current || (streak.Current == longest.Current && streak.Username < longest.Username) {
+				longest = streak
+			}
+		}
+
+		if longest != nil && longest.Current > 0 {
+			message = fmt.Sprintf("%v with %v", longest.Username, pluralize(longest.Current, longest.Period))
+		} else {
+			message = "nobody yet"
+		}
+	}
+
+	writeBadge(w, r, leaderboard, label, message, utils.BadgeColor(r.URL.Query().Get("color"), DEFAULT_BADGE_COLOR))
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestWriteBadgeCacheControl(t *testing.T) {
+	for visibility, expected := range map[string]string{
+		model.LEADERBOARD_VISIBILITY_PUBLIC:   BADGE_CACHE_CONTROL,
+		model.LEADERBOARD_VISIBILITY_UNLISTED: BADGE_CACHE_CONTROL,
+		model.LEADERBOARD_VISIBILITY_PRIVATE:  PRIVATE_CACHE_CONTROL,
+	} {
+		w := httptest.NewRecorder()
+		writeBadge(w, testRequest("GET", "/badge/main/top.svg"), &model.Leaderboard{Visibility: visibility}, "label", "message", "green")
+
+		if cacheControl := w.Header().Get("Cache-Control"); cacheControl != expected {
+			t.Fatal("wrong cache control for a "+visibility+" leaderboard", cacheControl)
+		}
+	}
+}
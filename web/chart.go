@@ -0,0 +1,134 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+const (
+	CHART_WIDTH         = 800
+	CHART_HEIGHT        = 400
+	DEFAULT_CHART_TOP   = 5
+	MAX_CHART_TOP       = 20
+	DEFAULT_CHART_DAYS  = 90
+	MAX_CHART_DAYS      = 730
+	DEFAULT_CHART_WEEKS = 12
+	MAX_CHART_WEEKS     = 104
+)
+
+// getIntParam returns the query parameter as a number between 1 and max,
+// or def if it's missing or invalid
+func getIntParam(r *http.Request, name string, def int, max int) int {
+	if v, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil && v > 0 {
+		if v > max {
+			return max
+		}
+		return v
+	}
+
+	return def
+}
+
+func writeSvg(w http.ResponseWriter, leaderboard *model.Leaderboard, svg string) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if leaderboard.IsPrivate() {
+		w.Header().Set("Cache-Control", PRIVATE_CACHE_CONTROL)
+	} else {
+		w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	}
+	w.Write([]byte(svg))
+}
+
+// pointsChart draws the running points totals of the top users over the
+// last days, ?top=5 users over ?days=90 days by default
+func pointsChart(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	top := getIntParam(r, "top", DEFAULT_CHART_TOP, MAX_CHART_TOP)
+	numDays := getIntParam(r, "days", DEFAULT_CHART_DAYS, MAX_CHART_DAYS)
+
+	rankings, err := getRankings(leaderboard.Id, false)
+	if err != nil {
+		l4g.Error("Failed to load rankings, err=%v", err.Error())
+		http.Error(w, "failed to load rankings", http.StatusInternalServerError)
+		return
+	}
+
+	timeline, err := getRankedTimeline(leaderboard.Id)
+	if err != nil {
+		l4g.Error("Failed to load timeline, err=%v", err.Error())
+		http.Error(w, "failed to load timeline", http.StatusInternalServerError)
+		return
+	}
+
+	usernames := []string{}
+	for i := 0; i < len(rankings) && i < top; i++ {
+		usernames = append(usernames, rankings[i].Username)
+	}
+
+	today := model.SnapshotDay(model.GetMillis())
+	days := make([]int64, numDays)
+	labels := make([]string, numDays)
+	for i := range days {
+		days[i] = today - int64(numDays-1-i)*model.MILLIS_PER_DAY
+		labels[i] = time.Unix(0, days[i]*int64(time.Millisecond)).UTC().Format("Jan 2")
+	}
+
+	values := model.CumulativePoints(timeline, usernames, days)
+
+	chart := &utils.Chart{
+		Title:  "Points over the last " + strconv.Itoa(numDays) + " days",
+		Labels: labels,
+		Width:  CHART_WIDTH,
+		Height: CHART_HEIGHT,
+	}
+
+	for _, username := range usernames {
+		chart.Series = append(chart.Series, &utils.ChartSeries{Name: username, Values: values[username]})
+	}
+
+	writeSvg(w, leaderboard, chart.RenderLine())
+}
+
+// contributionsChart draws the number of contributions to the leaderboard in
+// each of the last ?weeks=12 weeks
+func contributionsChart(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	weeks := getIntParam(r, "weeks", DEFAULT_CHART_WEEKS, MAX_CHART_WEEKS)
+
+	timeline, err := getRankedTimeline(leaderboard.Id)
+	if err != nil {
+		l4g.Error("Failed to load timeline, err=%v", err.Error())
+		http.Error(w, "failed to load timeline", http.StatusInternalServerError)
+		return
+	}
+
+	firstWeek := model.PeriodIndex(model.GetMillis(), model.STREAK_PERIOD_WEEK) - int64(weeks-1)
+
+	labels := make([]string, weeks)
+	for i := range labels {
+		labels[i] = time.Unix(0, model.WeekStart(firstWeek+int64(i))*int64(time.Millisecond)).UTC().Format("Jan 2")
+	}
+
+	chart := &utils.Chart{
+		Title:  "Contributions per week",
+		Labels: labels,
+		Series: []*utils.ChartSeries{{Name: "Contributions", Values: model.ContributionsPerWeek(timeline, firstWeek, weeks)}},
+		Width:  CHART_WIDTH,
+		Height: CHART_HEIGHT,
+	}
+
+	writeSvg(w, leaderboard, chart.RenderBar())
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestWriteSvgCacheControl(t *testing.T) {
+	for visibility, expected := range map[string]string{
+		model.LEADERBOARD_VISIBILITY_PUBLIC:   "no-cache, max-age=31556926, public",
+		model.LEADERBOARD_VISIBILITY_UNLISTED: "no-cache, max-age=31556926, public",
+		model.LEADERBOARD_VISIBILITY_PRIVATE:  PRIVATE_CACHE_CONTROL,
+	} {
+		w := httptest.NewRecorder()
+		writeSvg(w, &model.Leaderboard{Visibility: visibility}, "<svg></svg>")
+
+		if cacheControl := w.Header().Get("Cache-Control"); cacheControl != expected {
+			t.Fatal("wrong cache control for a "+visibility+" leaderboard", cacheControl)
+		}
+	}
+}
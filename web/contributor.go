@@ -0,0 +1,159 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initContributorApi(api *mux.Router) {
+	api.HandleFunc("/contributors", requireRole(model.ROLE_ADMIN, getContributors)).Methods("GET")
+	api.HandleFunc("/contributors", requireRole(model.ROLE_ADMIN, createContributor)).Methods("POST")
+	api.HandleFunc("/contributors/{id}", requireRole(model.ROLE_ADMIN, getContributor)).Methods("GET")
+	api.HandleFunc("/contributors/{id}/accounts", requireRole(model.ROLE_ADMIN, linkAccount)).Methods("POST")
+	api.HandleFunc("/contributors/{id}/accounts/{provider}/{login}", requireRole(model.ROLE_ADMIN, unlinkAccount)).Methods("DELETE")
+	api.HandleFunc("/contributors/{id}/merge", requireRole(model.ROLE_ADMIN, mergeContributors)).Methods("POST")
+}
+
+// getContributorsByLogin maps the login of every linked account to its contributor
+func getContributorsByLogin() (map[string]*model.Contributor, error) {
+	cchan := Srv.Store.Contributor().GetAll()
+	achan := Srv.Store.Contributor().GetAllAccounts()
+
+	var contributors []*model.Contributor
+	if result := <-cchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		contributors = result.Data.([]*model.Contributor)
+	}
+
+	if result := <-achan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		return model.ContributorsByLogin(contributors, result.Data.([]*model.ContributorAccount)), nil
+	}
+}
+
+func getContributors(w http.ResponseWriter, r *http.Request) {
+	cchan := Srv.Store.Contributor().GetAll()
+	achan := Srv.Store.Contributor().GetAllAccounts()
+
+	var contributors []*model.Contributor
+	if result := <-cchan; result.Err != nil {
+		l4g.Error("Failed to load contributors, err=%v", result.Err.Error())
+		http.Error(w, "failed to load contributors", http.StatusInternalServerError)
+		return
+	} else {
+		contributors = result.Data.([]*model.Contributor)
+	}
+
+	if result := <-achan; result.Err != nil {
+		l4g.Error("Failed to load contributor accounts, err=%v", result.Err.Error())
+		http.Error(w, "failed to load contributors", http.StatusInternalServerError)
+		return
+	} else {
+		byId := make(map[string]*model.Contributor, len(contributors))
+		for _, contributor := range contributors {
+			contributor.Accounts = []*model.ContributorAccount{}
+			byId[contributor.Id] = contributor
+		}
+
+		for _, account := range result.Data.([]*model.ContributorAccount) {
+			if contributor, ok := byId[account.ContributorId]; ok {
+				contributor.Accounts = append(contributor.Accounts, account)
+			}
+		}
+	}
+
+	writeJson(w, model.ContributorListToJson(contributors))
+}
+
+func createContributor(w http.ResponseWriter, r *http.Request) {
+	contributor := model.ContributorFromJson(r.Body)
+	if contributor == nil {
+		http.Error(w, "invalid contributor", http.StatusBadRequest)
+		return
+	}
+
+	accounts := contributor.Accounts
+	contributor.Id = ""
+	contributor.Accounts = []*model.ContributorAccount{}
+
+	if result := <-Srv.Store.Contributor().Save(contributor); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, account := range accounts {
+		account.ContributorId = contributor.Id
+		if result := <-Srv.Store.Contributor().LinkAccount(account); result.Err != nil {
+			http.Error(w, result.Err.Error(), http.StatusBadRequest)
+			return
+		}
+		contributor.Accounts = append(contributor.Accounts, account)
+	}
+
+	writeCreatedJson(w, contributor.ToJson())
+}
+
+func getContributor(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.Contributor().Get(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusNotFound)
+	} else {
+		writeJson(w, result.Data.(*model.Contributor).ToJson())
+	}
+}
+
+func linkAccount(w http.ResponseWriter, r *http.Request) {
+	account := model.ContributorAccountFromJson(r.Body)
+	if account == nil {
+		http.Error(w, "invalid account", http.StatusBadRequest)
+		return
+	}
+
+	account.ContributorId = mux.Vars(r)["id"]
+
+	if result := <-Srv.Store.Contributor().Get(account.ContributorId); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if result := <-Srv.Store.Contributor().LinkAccount(account); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	getContributor(w, r)
+}
+
+func unlinkAccount(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	if result := <-Srv.Store.Contributor().UnlinkAccount(params["id"], params["provider"], params["login"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	getContributor(w, r)
+}
+
+func mergeContributors(w http.ResponseWriter, r *http.Request) {
+	var props struct {
+		ContributorId string `json:"contributor_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&props); err != nil || len(props.ContributorId) == 0 {
+		http.Error(w, "missing contributor_id", http.StatusBadRequest)
+		return
+	}
+
+	if result := <-Srv.Store.Contributor().Merge(mux.Vars(r)["id"], props.ContributorId); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	getContributor(w, r)
+}
@@ -0,0 +1,205 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+func initDigestApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/digest", getDigestHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/digest/send", requireRole(model.ROLE_ADMIN, sendDigestHandler)).Methods("POST")
+}
+
+// watchDigests sends every leaderboard's digest on the configured schedule
+// until the server stops
+func watchDigests() {
+	if !*Srv.Cfg.DigestSettings.Enable {
+		return
+	}
+
+	schedule, err := utils.ParseCron(*Srv.Cfg.DigestSettings.Schedule)
+	if err != nil {
+		l4g.Error("Unable to schedule digests, err=%v", err.Error())
+		return
+	}
+
+	go func() {
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				l4g.Warn("Digest schedule %v never runs", *Srv.Cfg.DigestSettings.Schedule)
+				return
+			}
+
+			time.Sleep(time.Until(next))
+			sendDigests()
+		}
+	}()
+}
+
+func sendDigests() {
+	if result := <-Srv.Store.Leaderboard().GetAll(); result.Err != nil {
+		l4g.Error("Unable to load leaderboards, err=%v", result.Err.Error())
+	} else {
+		for _, leaderboard := range result.Data.([]*model.Leaderboard) {
+			if err := sendDigest(leaderboard); err != nil {
+				l4g.Error("Unable to send digest, leaderboard=%v, err=%v", leaderboard.Name, err.Error())
+			}
+		}
+	}
+}
+
+// sendDigest delivers the leaderboard's digest for the past week to each
+// configured delivery, returning the last error if any failed
+func sendDigest(leaderboard *model.Leaderboard) error {
+	deliveries := []*model.DigestDelivery{}
+	for _, delivery := range Srv.Cfg.DigestSettings.Deliveries {
+		if delivery.Matches(leaderboard.Name) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	digest, err := getDigest(leaderboard, model.GetMillis())
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, delivery := range deliveries {
+		if err := deliverDigest(delivery, digest); err != nil {
+			l4g.Error("Unable to deliver digest, type=%v, err=%v", delivery.Type, err.Error())
+			lastErr = err
+		}
+	}
+
+	l4g.Info("Sent digest for %v", leaderboard.Name)
+
+	return lastErr
+}
+
+func deliverDigest(delivery *model.DigestDelivery, digest *model.Digest) error {
+	switch delivery.Type {
+	case model.DIGEST_DELIVERY_CHAT:
+		if notifier == nil {
+			return errors.New("Notifier is not available")
+		}
+
+		return notifier.Deliver(delivery.Url, &model.WebhookPayload{
+			Text:     digest.ToMarkdown(),
+			Channel:  delivery.Channel,
+			Username: delivery.Username,
+		})
+	case model.DIGEST_DELIVERY_EMAIL:
+		return utils.SendMail(&Srv.Cfg.EmailSettings, delivery.To, digest.Subject(), digest.ToMarkdown())
+	case model.DIGEST_DELIVERY_FILE:
+		if err := os.MkdirAll(delivery.Directory, 0755); err != nil {
+			return err
+		}
+
+		name := digest.LeaderboardName + "-" + time.Unix(0, digest.EndAt*int64(time.Millisecond)).Format("2006-01-02") + ".md"
+		return os.WriteFile(filepath.Join(delivery.Directory, name), []byte(digest.ToMarkdown()), 0644)
+	}
+
+	return errors.New("Unknown digest delivery type, type=" + delivery.Type)
+}
+
+// getDigest sums up the week on the leaderboard that ended at endAt
+func getDigest(leaderboard *model.Leaderboard, endAt int64) (*model.Digest, error) {
+	limit := *Srv.Cfg.DigestSettings.Limit
+
+	digest := &model.Digest{
+		LeaderboardName: leaderboard.Name,
+		StartAt:         endAt - model.DIGEST_DAYS*model.MILLIS_PER_DAY,
+		EndAt:           endAt,
+		Top:             []*model.LeaderboardEntry{},
+		Newcomers:       []*model.LeaderboardEntry{},
+		Badges:          []*model.Achievement{},
+	}
+
+	if result := <-Srv.Store.LedgerEntry().GetTotals(leaderboard.Id, digest.StartAt, digest.EndAt); result.Err != nil {
+		return nil, result.Err
+	} else if rankings, err := rankEntries(result.Data.([]*model.LeaderboardEntry)); err != nil {
+		return nil, err
+	} else {
+		for _, entry := range rankings {
+			if entry.Points > 0 && len(digest.Top) < limit {
+				digest.Top = append(digest.Top, entry)
+			}
+		}
+	}
+
+	if newcomers, err := getNewcomersSince(leaderboard.Id, digest.StartAt); err != nil {
+		return nil, err
+	} else if len(newcomers) > limit {
+		digest.Newcomers = newcomers[:limit]
+	} else {
+		digest.Newcomers = newcomers
+	}
+
+	if movements, err := getMovements(leaderboard.Id, model.MOVEMENT_WEEK); err != nil {
+		return nil, err
+	} else {
+		digest.Movers = model.BiggestMovers(movements, limit)
+	}
+
+	if achievements, err := getAchievements(leaderboard.Id); err != nil {
+		return nil, err
+	} else {
+		for _, achievement := range achievements {
+			if achievement.EarnedAt >= digest.StartAt && achievement.EarnedAt <= digest.EndAt {
+				digest.Badges = append(digest.Badges, achievement)
+			}
+		}
+	}
+
+	return digest, nil
+}
+
+func getDigestHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	digest, err := getDigest(leaderboard, model.GetMillis())
+	if err != nil {
+		l4g.Error("Failed to build digest, err=%v", err.Error())
+		http.Error(w, "failed to build digest", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(digest.ToMarkdown()))
+		return
+	}
+
+	writeJson(w, digest.ToJson())
+}
+
+func sendDigestHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if err := sendDigest(leaderboard); err != nil {
+		l4g.Error("Failed to send digest, err=%v", err.Error())
+		http.Error(w, "failed to send digest", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+const (
+	LIVE_RETRY_MILLIS = 3000
+)
+
+var liveRanks = struct {
+	sync.Mutex
+	byLeaderboard map[string]map[string]int
+}{byLeaderboard: make(map[string]map[string]int)}
+
+func initLiveApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/events", streamEvents).Methods("GET")
+}
+
+// initHub creates the hub domain events are published to, and remembers
+// every leaderboard's current ranks so that later changes can be published
+func initHub() {
+	Srv.Hub = utils.NewHub(*Srv.Cfg.LiveSettings.HistorySize, *Srv.Cfg.LiveSettings.MaxSubscribers)
+
+	if result := <-Srv.Store.Leaderboard().GetAll(); result.Err != nil {
+		l4g.Error("Unable to load leaderboards, err=%v", result.Err.Error())
+	} else {
+		for _, leaderboard := range result.Data.([]*model.Leaderboard) {
+			if err := publishRankChanges(leaderboard.Id); err != nil {
+				l4g.Error("Unable to load ranks, err=%v", err.Error())
+			}
+		}
+	}
+}
+
+func publish(event *model.DomainEvent) {
+	Srv.Hub.Publish(event)
+}
+
+// publishRankChanges publishes a rank.changed event for every user whose rank
+// changed since the leaderboard's ranks were last checked. The first check of
+// a leaderboard only records its ranks.
+func publishRankChanges(leaderboardId string) error {
+	rankings, err := getRankings(leaderboardId, false)
+	if err != nil {
+		return err
+	}
+
+	standings := model.NewSeasonStandings("", rankings)
+
+	ranks := make(map[string]int, len(standings))
+	points := make(map[string]int, len(standings))
+	for _, standing := range standings {
+		ranks[standing.Username] = standing.Rank
+		points[standing.Username] = standing.Points
+	}
+
+	liveRanks.Lock()
+	defer liveRanks.Unlock()
+
+	previous, ok := liveRanks.byLeaderboard[leaderboardId]
+	liveRanks.byLeaderboard[leaderboardId] = ranks
+
+	if !ok {
+		return nil
+	}
+
+	for _, movement := range model.RankChanges(previous, standings) {
+		publish(model.NewRankChangedEvent(leaderboardId, movement, points[movement.Username]))
+	}
+
+	return nil
+}
+
+func getLastEventId(r *http.Request) int64 {
+	value := r.Header.Get("Last-Event-ID")
+	if len(value) == 0 {
+		value = r.URL.Query().Get("last_event_id")
+	}
+
+	id, _ := strconv.ParseInt(value, 10, 64)
+	return id
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event *model.DomainEvent) error {
+	_, err := fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %v\n\n", event.Id, event.Type, event.ToJson())
+	return err
+}
+
+// streamEvents streams the leaderboard's domain events to the client as
+// server-sent events, starting with any it missed since the Last-Event-ID
+// it reconnected with
+func streamEvents(w http.ResponseWriter, r *http.Request) {
+	if !*Srv.Cfg.LiveSettings.Enable {
+		http.NotFound(w, r)
+		return
+	}
+
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	subscription, missed, err := Srv.Hub.Subscribe(leaderboard.Id, getLastEventId(r))
+	if err == utils.ErrTooManySubscribers {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "too many subscribers", http.StatusServiceUnavailable)
+		return
+	}
+	defer Srv.Hub.Unsubscribe(subscription)
+
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		l4g.Debug("Unable to clear write deadline, err=%v", err.Error())
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	fmt.Fprintf(w, "retry: %v\n\n", LIVE_RETRY_MILLIS)
+	for _, event := range missed {
+		if writeServerSentEvent(w, event) != nil {
+			return
+		}
+	}
+
+	if controller.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(*Srv.Cfg.LiveSettings.HeartbeatSeconds) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+
+			if writeServerSentEvent(w, event) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+
+		if controller.Flush() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initNewcomerApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/rankings/newcomers", getNewcomerRankingsHandler).Methods("GET")
+}
+
+// awardNewcomerBonus grants the configured bonus if the contribution is the
+// user's first on the leaderboard, or GitHub says it is their first to the
+// repository. The bonus is only ever awarded once per user.
+func awardNewcomerBonus(ledgerEntry *model.LedgerEntry, firstTimeContributor bool, award awardFunc) error {
+	bonus := *Srv.Cfg.NewcomerSettings.BonusPoints
+	if bonus <= 0 {
+		return nil
+	}
+
+	if result := <-Srv.Store.LedgerEntry().GetForUser(ledgerEntry.LeaderboardId, ledgerEntry.Username); result.Err != nil {
+		return result.Err
+	} else {
+		contributedBefore := false
+		for _, entry := range result.Data.([]*model.LedgerEntry) {
+			if entry.Type == model.LEDGER_TYPE_NEWCOMER_BONUS {
+				return nil
+			}
+
+			if entry.Id != ledgerEntry.Id && entry.IsContribution() {
+				contributedBefore = true
+			}
+		}
+
+		if contributedBefore && !firstTimeContributor {
+			return nil
+		}
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: ledgerEntry.LeaderboardId,
+		Username:      ledgerEntry.Username,
+		Type:          model.LEDGER_TYPE_NEWCOMER_BONUS,
+		Points:        bonus,
+		Repository:    ledgerEntry.Repository,
+		Number:        ledgerEntry.Number,
+		Title:         ledgerEntry.Title,
+		Url:           ledgerEntry.Url,
+		RelatedId:     ledgerEntry.Id,
+		CreateAt:      ledgerEntry.CreateAt,
+	})
+}
+
+// getNewcomerRankings ranks the users whose first contribution falls within
+// the configured window
+func getNewcomerRankings(leaderboardId string) ([]*model.LeaderboardEntry, error) {
+	return getNewcomersSince(leaderboardId, model.GetMillis()-int64(*Srv.Cfg.NewcomerSettings.WindowDays)*model.MILLIS_PER_DAY)
+}
+
+// getNewcomersSince ranks the users whose first contribution was at or after since
+func getNewcomersSince(leaderboardId string, since int64) ([]*model.LeaderboardEntry, error) {
+	var firsts []*model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().GetFirstContributions(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		firsts = result.Data.([]*model.LedgerEntry)
+	}
+
+	byLogin, err := getContributorsByLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	rankings, err := getRankings(leaderboardId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.FilterNewcomers(rankings, firsts, byLogin, since), nil
+}
+
+func newcomersPage(w http.ResponseWriter, r *http.Request) {
+	page := NewHtmlTemplatePage("newcomers", "Rising Newcomers")
+	page.Props["WindowDays"] = *Srv.Cfg.NewcomerSettings.WindowDays
+
+	if rankings, err := getNewcomerRankings(Srv.Leaderboard.Id); err != nil {
+		l4g.Error("Failed to load newcomer rankings, err=%v", err.Error())
+	} else {
+		page.Props["Rankings"] = rankings
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func getNewcomerRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if rankings, err := getNewcomerRankings(leaderboard.Id); err != nil {
+		l4g.Error("Failed to load newcomer rankings, err=%v", err.Error())
+		http.Error(w, "failed to load newcomer rankings", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.LeaderboardEntryListToJson(rankings))
+	}
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+// notifier posts to chat webhooks, with retries and dead lettering
+var notifier *utils.Notifier
+
+// initNotifier creates the notifier, and if notifications are enabled starts
+// posting them for the events published to the hub
+func initNotifier() {
+	var err error
+	if notifier, err = utils.NewNotifier(&Srv.Cfg.NotificationSettings, getLeaderboardName); err != nil {
+		l4g.Error("Unable to create notifier, err=%v", err.Error())
+		return
+	}
+
+	if *Srv.Cfg.NotificationSettings.Enable {
+		Srv.Hub.AddListener(notifier.Notify)
+	}
+}
+
+func getLeaderboardName(leaderboardId string) string {
+	if leaderboardId == Srv.Leaderboard.Id {
+		return Srv.Leaderboard.Name
+	}
+
+	if result := <-Srv.Store.Leaderboard().Get(leaderboardId); result.Err != nil {
+		l4g.Error("Unable to find leaderboard, err=%v", result.Err.Error())
+		return ""
+	} else {
+		return result.Data.(*model.Leaderboard).Name
+	}
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+const (
+	OAUTH_STATE_COOKIE = "LBOAUTHSTATE"
+)
+
+// isSecureRequest reports whether the client connected over https, directly
+// or through a proxy terminating TLS
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// oauthRedirectUri returns the callback the provider should send users back
+// to, which must match the one registered with the provider
+func oauthRedirectUri(r *http.Request) string {
+	scheme := "http"
+	if isSecureRequest(r) {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + "/oauth/callback"
+}
+
+func oauthLogin(w http.ResponseWriter, r *http.Request) {
+	if !*Srv.Cfg.OAuthSettings.Enable {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := model.NewSecret()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     OAUTH_STATE_COOKIE,
+		Value:    state,
+		Path:     "/oauth/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+	})
+
+	http.Redirect(w, r, Srv.Cfg.OAuthSettings.AuthorizeUrl(oauthRedirectUri(r), state), http.StatusFound)
+}
+
+func oauthCallback(w http.ResponseWriter, r *http.Request) {
+	settings := &Srv.Cfg.OAuthSettings
+	if !*settings.Enable {
+		http.NotFound(w, r)
+		return
+	}
+
+	cookie, err := r.Cookie(OAUTH_STATE_COOKIE)
+	if err != nil || len(cookie.Value) == 0 || cookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "invalid oauth state", http.StatusBadRequest)
+		return
+	}
+
+	client := utils.NewOAuthClient(settings)
+
+	accessToken, err := client.ExchangeCode(r.URL.Query().Get("code"), oauthRedirectUri(r))
+	if err != nil {
+		l4g.Error("Failed to log in with oauth, err=%v", err.Error())
+		http.Error(w, "failed to log in", http.StatusUnauthorized)
+		return
+	}
+
+	username, err := client.GetUsername(accessToken)
+	if err != nil {
+		l4g.Error("Failed to log in with oauth, err=%v", err.Error())
+		http.Error(w, "failed to log in", http.StatusUnauthorized)
+		return
+	}
+
+	length := time.Duration(*Srv.Cfg.AuthSettings.SessionLengthHours) * time.Hour
+
+	session := &model.Session{
+		Username: username,
+		Role:     settings.RoleFor(username),
+		ExpireAt: model.GetMillis() + int64(length/time.Millisecond),
+	}
+
+	if result := <-Srv.Store.Session().Save(session); result.Err != nil {
+		l4g.Error("Failed to save session, err=%v", result.Err.Error())
+		http.Error(w, "failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	Srv.Store.Session().RemoveExpired(model.GetMillis())
+
+	http.SetCookie(w, &http.Cookie{Name: OAUTH_STATE_COOKIE, Path: "/oauth/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{
+		Name:     model.SESSION_COOKIE,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  time.Now().Add(length),
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	l4g.Info("%v logged in as %v", username, session.Role)
+
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+func logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(model.SESSION_COOKIE); err == nil {
+		Srv.Store.Session().Delete(model.HashSecret(cookie.Value))
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: model.SESSION_COOKIE, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
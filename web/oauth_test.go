@@ -0,0 +1,33 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOAuthLoginBehindProxy(t *testing.T) {
+	setupTestServer(false)
+	*Srv.Cfg.OAuthSettings.Enable = true
+
+	r := testRequest("GET", "/oauth/login")
+	r.Header.Set("X-Forwarded-Proto", "https")
+
+	w := httptest.NewRecorder()
+	oauthLogin(w, r)
+
+	if location := w.Header().Get("Location"); !strings.Contains(location, "https%3A%2F%2F") {
+		t.Fatal("the callback should use https", location)
+	}
+
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || !cookies[0].Secure {
+		t.Fatal("the state cookie should be secure behind a proxy terminating TLS", cookies)
+	}
+
+	w = httptest.NewRecorder()
+	oauthLogin(w, testRequest("GET", "/oauth/login"))
+
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Secure {
+		t.Fatal("the state cookie can't be secure over plain http", cookies)
+	}
+}
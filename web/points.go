@@ -0,0 +1,316 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+// awardFunc records a ledger entry earned by an event. It's awardPoints,
+// except when replaying events.
+type awardFunc func(ledgerEntry *model.LedgerEntry) error
+
+// processEvent awards or revokes points for a GitHub webhook event of the
+// given type. Events that aren't scored are ignored. Points already in the
+// ledger for the event are skipped, so an event that failed partway through
+// can be retried.
+func processEvent(eventType string, event *model.Event) error {
+	return scoreEvent(eventType, event, awardOnce)
+}
+
+// awardOnce awards the ledger entry unless it has already been awarded
+func awardOnce(ledgerEntry *model.LedgerEntry) error {
+	if awarded, err := findAwarded(ledgerEntry); err != nil {
+		return err
+	} else if awarded {
+		l4g.Debug("Already awarded %v points for %v", ledgerEntry.Type, ledgerEntry.Url)
+		return nil
+	}
+
+	return awardPoints(ledgerEntry)
+}
+
+// findAwarded reports whether the ledger already has an entry of the same
+// type for the url of the ledger entry, and if so fills the ledger entry in
+// from it so that bonuses related to it see the awarded entry. Entries
+// without a url, like bonuses and adjustments, are never found.
+func findAwarded(ledgerEntry *model.LedgerEntry) (bool, error) {
+	if len(ledgerEntry.Url) == 0 {
+		return false, nil
+	}
+
+	if result := <-Srv.Store.LedgerEntry().GetByUrl(ledgerEntry.LeaderboardId, ledgerEntry.Url, ledgerEntry.Type); result.Err != nil {
+		return false, result.Err
+	} else if existing := result.Data.([]*model.LedgerEntry); len(existing) > 0 {
+		*ledgerEntry = *existing[0]
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// scoreEvent passes the ledger entries a GitHub webhook event of the given
+// type earns, including the bonuses they earn, to award
+func scoreEvent(eventType string, event *model.Event, award awardFunc) error {
+	award = withStreakBonus(award)
+
+	switch {
+	case eventType == model.EVENT_TYPE_PULL_REQUEST_REVIEW:
+		if event.Action == "submitted" {
+			return handleReview(event, award)
+		}
+	case eventType == model.EVENT_TYPE_ISSUES:
+		if event.Action == "opened" {
+			return handleOpenedIssue(event, award)
+		}
+	case eventType == model.EVENT_TYPE_COMMIT:
+		return handleCommit(event, award)
+	case event.Action == "closed" && event.PullRequest.Merged:
+		return handleMergedPullRequest(event, award)
+	}
+
+	return nil
+}
+
+func handleMergedPullRequest(event *model.Event, award awardFunc) error {
+	pr := &event.PullRequest
+
+	if revert := pr.RevertInfo(); revert != nil {
+		return revokeRevertedPoints(event, revert, award)
+	}
+
+	if Srv.Cfg.ExclusionSettings.IsExcluded(pr.User.Login, pr.User.Type) {
+		l4g.Debug("Not awarding points to excluded user %v", pr.User.Login)
+		return nil
+	}
+
+	ledgerEntry := &model.LedgerEntry{
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      pr.User.Login,
+		Type:          model.LEDGER_TYPE_PULL_REQUEST_MERGED,
+		Points:        *Srv.Cfg.ScoringSettings.PullRequestMergedPoints,
+		Repository:    event.Repository.FullName,
+		Number:        pr.Number,
+		Title:         pr.Title,
+		Url:           pr.HtmlUrl,
+	}
+
+	if err := award(ledgerEntry); err != nil {
+		return err
+	}
+
+	return awardNewcomerBonus(ledgerEntry, pr.IsFirstTimeContributor(), award)
+}
+
+func handleReview(event *model.Event, award awardFunc) error {
+	review := &event.Review
+
+	if review.User.Login == event.PullRequest.User.Login {
+		return nil
+	}
+
+	if Srv.Cfg.ExclusionSettings.IsExcluded(review.User.Login, review.User.Type) {
+		l4g.Debug("Not awarding points to excluded user %v", review.User.Login)
+		return nil
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      review.User.Login,
+		Type:          model.LEDGER_TYPE_REVIEW,
+		Points:        *Srv.Cfg.ScoringSettings.ReviewSubmittedPoints,
+		Repository:    event.Repository.FullName,
+		Number:        event.PullRequest.Number,
+		Title:         event.PullRequest.Title,
+		Url:           review.HtmlUrl,
+	})
+}
+
+func handleOpenedIssue(event *model.Event, award awardFunc) error {
+	issue := &event.Issue
+
+	if Srv.Cfg.ExclusionSettings.IsExcluded(issue.User.Login, issue.User.Type) {
+		l4g.Debug("Not awarding points to excluded user %v", issue.User.Login)
+		return nil
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      issue.User.Login,
+		Type:          model.LEDGER_TYPE_ISSUE_OPENED,
+		Points:        *Srv.Cfg.ScoringSettings.IssueOpenedPoints,
+		Repository:    event.Repository.FullName,
+		Number:        issue.Number,
+		Title:         issue.Title,
+		Url:           issue.HtmlUrl,
+	})
+}
+
+func handleCommit(event *model.Event, award awardFunc) error {
+	commit := &event.Commit
+
+	if Srv.Cfg.ExclusionSettings.IsExcluded(commit.User.Login, commit.User.Type) {
+		l4g.Debug("Not awarding points to excluded user %v", commit.User.Login)
+		return nil
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      commit.User.Login,
+		Type:          model.LEDGER_TYPE_COMMIT,
+		Points:        *Srv.Cfg.ScoringSettings.CommitPoints,
+		Repository:    event.Repository.FullName,
+		Title:         commit.Message,
+		Url:           commit.HtmlUrl,
+	})
+}
+
+// awardPoints records the ledger entry and publishes the award, along with
+// any badges and rank changes it leads to
+func awardPoints(ledgerEntry *model.LedgerEntry) error {
+	if err := recordPoints(ledgerEntry); err != nil {
+		return err
+	}
+
+	publish(model.NewPointsAwardedEvent(ledgerEntry))
+
+	if err := evaluateAchievements(ledgerEntry.LeaderboardId, ledgerEntry.Username); err != nil {
+		l4g.Error("Unable to evaluate achievements, err=%v", err.Error())
+	}
+
+	if err := publishRankChanges(ledgerEntry.LeaderboardId); err != nil {
+		l4g.Error("Unable to publish rank changes, err=%v", err.Error())
+	}
+
+	return nil
+}
+
+// recordPoints records the ledger entry and applies its points, which may be
+// negative, to the user's leaderboard entry. Both happen together or not at
+// all.
+func recordPoints(ledgerEntry *model.LedgerEntry) error {
+	entry := &model.LeaderboardEntry{
+		LeaderboardId: ledgerEntry.LeaderboardId,
+		Username:      ledgerEntry.Username,
+	}
+
+	if result := <-Srv.Store.LeaderboardEntry().Save(entry); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.LeaderboardEntry().Award(ledgerEntry, activeScoreHalfLife()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// revokeRevertedPoints takes back the points awarded for the pull request
+// being reverted. The revert itself earns nothing. The reverted pull request
+// may be by another user whose events are still queued, so a revert with no
+// matching original fails and is retried.
+func revokeRevertedPoints(event *model.Event, revert *model.RevertInfo, award awardFunc) error {
+	pr := &event.PullRequest
+
+	if revert.OriginalNumber == 0 && len(revert.OriginalTitle) == 0 {
+		l4g.Info("Unable to tell which pull request %v#%v reverts, no points revoked", event.Repository.FullName, pr.Number)
+		return nil
+	}
+
+	var original *model.LedgerEntry
+
+	if revert.OriginalNumber != 0 {
+		if result := <-Srv.Store.LedgerEntry().GetByNumber(Srv.Leaderboard.Id, event.Repository.FullName, revert.OriginalNumber, model.LEDGER_TYPE_PULL_REQUEST_MERGED); result.Err == nil {
+			original = result.Data.(*model.LedgerEntry)
+		}
+	}
+
+	if original == nil && len(revert.OriginalTitle) > 0 {
+		if result := <-Srv.Store.LedgerEntry().GetByTitle(Srv.Leaderboard.Id, event.Repository.FullName, revert.OriginalTitle, model.LEDGER_TYPE_PULL_REQUEST_MERGED); result.Err == nil {
+			original = result.Data.(*model.LedgerEntry)
+		}
+	}
+
+	if original == nil {
+		return errors.New("Unable to find the pull request reverted, repository=" + event.Repository.FullName + ", number=" + strconv.Itoa(pr.Number))
+	}
+
+	if result := <-Srv.Store.LedgerEntry().GetByRelatedId(original.Id); result.Err != nil {
+		return result.Err
+	} else {
+		for _, related := range result.Data.([]*model.LedgerEntry) {
+			if related.Type == model.LEDGER_TYPE_REVERT {
+				l4g.Info("Pull request %v#%v was already reverted, no points revoked", original.Repository, original.Number)
+				return nil
+			}
+		}
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: original.LeaderboardId,
+		Username:      original.Username,
+		Type:          model.LEDGER_TYPE_REVERT,
+		Points:        -original.Points,
+		Repository:    event.Repository.FullName,
+		Number:        pr.Number,
+		Title:         pr.Title,
+		Url:           pr.HtmlUrl,
+		RelatedId:     original.Id,
+	})
+}
+
+// getRankings returns the leaderboard's rankings with excluded users hidden
+// and linked accounts combined into their contributor. Entries are sorted by
+// lifetime points unless byActiveScore is set.
+func getRankings(leaderboardId string, byActiveScore bool) ([]*model.LeaderboardEntry, error) {
+	if result := <-Srv.Store.LeaderboardEntry().GetRankings(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		entries := result.Data.([]*model.LeaderboardEntry)
+
+		now := model.GetMillis()
+		for _, entry := range entries {
+			entry.SetActiveScore(now, activeScoreHalfLife())
+		}
+
+		rankings, err := rankEntries(entries)
+		if err == nil && byActiveScore {
+			model.SortByActiveScore(rankings)
+		}
+
+		return rankings, err
+	}
+}
+
+// rankByActiveScore reports whether a request asked for rankings by active
+// score, falling back to the configured default
+func rankByActiveScore(r *http.Request) bool {
+	if !*Srv.Cfg.DecaySettings.Enable {
+		return false
+	}
+
+	switch r.URL.Query().Get("rank_by") {
+	case "active":
+		return true
+	case "lifetime":
+		return false
+	}
+
+	return *Srv.Cfg.DecaySettings.RankByActiveScore
+}
+
+func activeScoreHalfLife() int64 {
+	return int64(*Srv.Cfg.DecaySettings.HalfLifeDays) * model.MILLIS_PER_DAY
+}
+
+// rankEntries applies user exclusion and contributor aggregation to entries
+func rankEntries(entries []*model.LeaderboardEntry) ([]*model.LeaderboardEntry, error) {
+	if byLogin, err := getContributorsByLogin(); err != nil {
+		return nil, err
+	} else {
+		return model.AggregateRankings(Srv.Cfg.ExclusionSettings.FilterRankings(entries), byLogin), nil
+	}
+}
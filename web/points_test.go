@@ -0,0 +1,92 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestProcessEventSkipsAwardedPoints(t *testing.T) {
+	ts := setupTestServer(false)
+
+	awarded := &model.LedgerEntry{
+		Id:            model.NewId(),
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      "reviewer",
+		Type:          model.LEDGER_TYPE_REVIEW,
+		Points:        1,
+		Url:           "https://github.com/org/repo/pull/1#review-1",
+	}
+	ts.ledger.entries = append(ts.ledger.entries, awarded)
+
+	event := &model.Event{Action: "submitted"}
+	event.PullRequest.User.Login = "author"
+	event.Review.User.Login = "reviewer"
+	event.Review.User.Type = "User"
+	event.Review.HtmlUrl = awarded.Url
+
+	if err := processEvent(model.EVENT_TYPE_PULL_REQUEST_REVIEW, event); err != nil {
+		t.Fatal(err)
+	} else if len(ts.ledger.entries) != 1 {
+		t.Fatal("the review shouldn't have been awarded again", ts.ledger.entries)
+	}
+
+	ledgerEntry := &model.LedgerEntry{LeaderboardId: Srv.Leaderboard.Id, Type: model.LEDGER_TYPE_REVIEW, Url: awarded.Url}
+	if found, err := findAwarded(ledgerEntry); err != nil {
+		t.Fatal(err)
+	} else if !found || ledgerEntry.Id != awarded.Id {
+		t.Fatal("should have found the awarded entry", ledgerEntry)
+	}
+
+	ledgerEntry = &model.LedgerEntry{LeaderboardId: Srv.Leaderboard.Id, Type: model.LEDGER_TYPE_REVIEW, Url: awarded.Url + "2"}
+	if found, err := findAwarded(ledgerEntry); err != nil {
+		t.Fatal(err)
+	} else if found || len(ledgerEntry.Id) > 0 {
+		t.Fatal("shouldn't have found an entry for another url", ledgerEntry)
+	}
+}
+
+func TestRevokeRevertedPointsBeforeOriginal(t *testing.T) {
+	ts := setupTestServer(false)
+
+	revert := &model.Event{Action: "closed"}
+	revert.Repository.FullName = "org/repo"
+	revert.PullRequest.Merged = true
+	revert.PullRequest.Number = 6
+	revert.PullRequest.Title = "Undo the login fix"
+	revert.PullRequest.Body = "Reverts org/repo#5"
+	revert.PullRequest.User.Login = "reverter"
+
+	var awarded []*model.LedgerEntry
+	award := func(ledgerEntry *model.LedgerEntry) error {
+		awarded = append(awarded, ledgerEntry)
+		return nil
+	}
+
+	if err := revokeRevertedPoints(revert, revert.PullRequest.RevertInfo(), award); err == nil {
+		t.Fatal("a revert scored before the pull request it reverts should fail so it's retried")
+	} else if len(awarded) != 0 {
+		t.Fatal("nothing should have been revoked", awarded)
+	}
+
+	original := &model.LedgerEntry{
+		Id:            model.NewId(),
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      "author",
+		Type:          model.LEDGER_TYPE_PULL_REQUEST_MERGED,
+		Points:        5,
+		Repository:    "org/repo",
+		Number:        5,
+	}
+	ts.ledger.entries = append(ts.ledger.entries, original)
+
+	if err := revokeRevertedPoints(revert, revert.PullRequest.RevertInfo(), award); err != nil {
+		t.Fatal(err)
+	} else if len(awarded) != 1 || awarded[0].Username != "author" || awarded[0].Points != -5 || awarded[0].RelatedId != original.Id {
+		t.Fatal("the original author's points should have been revoked", awarded)
+	}
+
+	if err := revokeRevertedPoints(revert, &model.RevertInfo{}, award); err != nil {
+		t.Fatal("a revert that doesn't say what it reverts can't be retried into succeeding", err)
+	}
+}
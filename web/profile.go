@@ -0,0 +1,180 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initProfileApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/users/{username}", getProfileHandler).Methods("GET")
+}
+
+// getProfileLogins returns the name to show on the profile of name, which is
+// either a login or the name of a contributor, along with the logins whose
+// history makes up the profile
+func getProfileLogins(name string) (string, []string, error) {
+	byLogin, err := getContributorsByLogin()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if contributor, ok := byLogin[strings.ToLower(name)]; ok {
+		name = contributor.Name
+	}
+
+	logins := []string{}
+	for login, contributor := range byLogin {
+		if contributor.Name == name {
+			logins = append(logins, login)
+		}
+	}
+
+	if len(logins) == 0 {
+		logins = append(logins, name)
+	}
+
+	return name, logins, nil
+}
+
+// getProfile builds the profile of a login or contributor from their ledger
+// entries, with the given page of contributions
+func getProfile(leaderboard *model.Leaderboard, name string, page int, perPage int) (*model.Profile, error) {
+	name, logins, err := getProfileLogins(name)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*model.LedgerEntry{}
+	badges := []*model.Achievement{}
+	earned := make(map[string]bool)
+
+	for _, login := range logins {
+		if result := <-Srv.Store.LedgerEntry().GetForUser(leaderboard.Id, login); result.Err != nil {
+			return nil, result.Err
+		} else {
+			history = append(history, result.Data.([]*model.LedgerEntry)...)
+		}
+
+		if result := <-Srv.Store.Achievement().GetForUser(leaderboard.Id, login); result.Err != nil {
+			return nil, result.Err
+		} else {
+			for _, achievement := range withBadgeDefinitions(result.Data.([]*model.Achievement)) {
+				if !earned[achievement.BadgeId] {
+					earned[achievement.BadgeId] = true
+					badges = append(badges, achievement)
+				}
+			}
+		}
+	}
+
+	now := model.GetMillis()
+
+	profile := model.NewProfile(name, history, now)
+	profile.Badges = badges
+	profile.SetPage(history, page, perPage)
+
+	profile.Streak = computeContributorStreak(name, history, now)
+
+	if rankings, err := getRankings(leaderboard.Id, false); err != nil {
+		return nil, err
+	} else {
+		for i, entry := range rankings {
+			if strings.EqualFold(entry.Username, name) {
+				profile.Rank = i + 1
+				break
+			}
+		}
+	}
+
+	if err := setRankHistory(profile, leaderboard.Id); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// setRankHistory fills in where the profile's user finished in past seasons
+func setRankHistory(profile *model.Profile, leaderboardId string) error {
+	schan := Srv.Store.Season().GetAll(leaderboardId)
+	stchan := Srv.Store.Season().GetStandingsForUser(leaderboardId, profile.Username)
+
+	seasons := make(map[string]*model.Season)
+	if result := <-schan; result.Err != nil {
+		return result.Err
+	} else {
+		for _, season := range result.Data.([]*model.Season) {
+			seasons[season.Id] = season
+		}
+	}
+
+	if result := <-stchan; result.Err != nil {
+		return result.Err
+	} else {
+		for _, standing := range result.Data.([]*model.SeasonStanding) {
+			if season, ok := seasons[standing.SeasonId]; ok {
+				profile.RankHistory = append(profile.RankHistory, &model.ProfileRank{
+					SeasonId:   season.Id,
+					SeasonName: season.Name,
+					StartAt:    season.StartAt,
+					EndAt:      season.EndAt,
+					Rank:       standing.Rank,
+					Points:     standing.Points,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func getPage(r *http.Request) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	return page, perPage
+}
+
+func getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	page, perPage := getPage(r)
+
+	if profile, err := getProfile(leaderboard, mux.Vars(r)["username"], page, perPage); err != nil {
+		l4g.Error("Failed to load profile, err=%v", err.Error())
+		http.Error(w, "failed to load profile", http.StatusInternalServerError)
+	} else {
+		writeJson(w, profile.ToJson())
+	}
+}
+
+func profilePage(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	username := mux.Vars(r)["username"]
+	page, perPage := getPage(r)
+
+	profile, err := getProfile(leaderboard, username, page, perPage)
+	if err != nil {
+		l4g.Error("Failed to load profile, err=%v", err.Error())
+		http.Error(w, "failed to load profile", http.StatusInternalServerError)
+		return
+	}
+
+	htmlPage := NewHtmlTemplatePage("profile", profile.Username)
+	htmlPage.Props["LeaderboardName"] = leaderboard.Name
+	htmlPage.Props["Profile"] = profile
+	htmlPage.Props["StreakPeriod"] = *Srv.Cfg.StreakSettings.Period
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	htmlPage.Render(w)
+}
@@ -0,0 +1,209 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	DEFAULT_QUEUE_LIMIT = 50
+	MAX_QUEUE_LIMIT     = 200
+)
+
+// queueWake wakes the dispatcher up early when there may be new work
+var queueWake = make(chan bool, 1)
+
+func initQueueApi(api *mux.Router) {
+	api.HandleFunc("/queue", requireRole(model.ROLE_ADMIN, getQueueHandler)).Methods("GET")
+	api.HandleFunc("/queue/{id}/retry", requireRole(model.ROLE_ADMIN, retryQueuedEventHandler)).Methods("POST")
+	api.HandleFunc("/queue/{id}", requireRole(model.ROLE_ADMIN, deleteQueuedEventHandler)).Methods("DELETE")
+}
+
+func wakeQueue() {
+	select {
+	case queueWake <- true:
+	default:
+	}
+}
+
+// startQueueWorkers processes queued events until the server stops. Each
+// worker handles one partition of the users, and the dispatcher only hands
+// out a user's next event once the previous one is done.
+func startQueueWorkers() {
+	settings := &Srv.Cfg.QueueSettings
+
+	if result := <-Srv.Store.Queue().ResetProcessing(); result.Err != nil {
+		l4g.Error("Unable to reset queued events, err=%v", result.Err.Error())
+	}
+
+	workers := make([]chan *model.QueuedEvent, *settings.Workers)
+	for i := range workers {
+		workers[i] = make(chan *model.QueuedEvent, *settings.BatchSize)
+		go runQueueWorker(workers[i])
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(*settings.PollIntervalMillis) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			dispatchQueuedEvents(workers)
+
+			select {
+			case <-ticker.C:
+			case <-queueWake:
+			}
+		}
+	}()
+}
+
+// dispatchQueuedEvents hands up to BatchSize events to the workers. Events
+// held back behind an earlier event of the same user are paged past, so a
+// backlog for one user doesn't keep everyone else's events waiting.
+func dispatchQueuedEvents(workers []chan *model.QueuedEvent) {
+	batchSize := *Srv.Cfg.QueueSettings.BatchSize
+	now := model.GetMillis()
+
+	blocked := make(map[string]bool)
+	dispatchable := []*model.QueuedEvent{}
+
+	for offset := 0; len(dispatchable) < batchSize; offset += batchSize {
+		var events []*model.QueuedEvent
+		if result := <-Srv.Store.Queue().GetUnfinished(offset, batchSize); result.Err != nil {
+			l4g.Error("Unable to load queued events, err=%v", result.Err.Error())
+			return
+		} else {
+			events = result.Data.([]*model.QueuedEvent)
+		}
+
+		dispatchable = append(dispatchable, model.SelectDispatchable(events, blocked, now)...)
+
+		if len(events) < batchSize {
+			break
+		}
+	}
+
+	if len(dispatchable) > batchSize {
+		dispatchable = dispatchable[:batchSize]
+	}
+
+	for _, event := range dispatchable {
+		event.Status = model.QUEUE_STATUS_PROCESSING
+		event.UpdateAt = now
+
+		if result := <-Srv.Store.Queue().Update(event); result.Err != nil {
+			l4g.Error("Unable to dispatch queued event, err=%v", result.Err.Error())
+			continue
+		}
+
+		workers[model.Partition(event.PartitionKey, len(workers))] <- event
+	}
+}
+
+func runQueueWorker(events chan *model.QueuedEvent) {
+	for event := range events {
+		processQueuedEvent(event)
+	}
+}
+
+// processQueuedEvent scores the event and removes it from the queue. Failed
+// events are retried later, until they have failed too often and are left
+// in the queue marked failed.
+func processQueuedEvent(event *model.QueuedEvent) {
+	settings := &Srv.Cfg.QueueSettings
+
+	var err error
+	maxAttempts := *settings.MaxAttempts
+
+	if parsed := model.EventFromJson(strings.NewReader(event.Payload)); parsed == nil {
+		err = errors.New("Unable to parse queued event")
+		maxAttempts = 1
+	} else {
+		err = processEvent(event.EventType, parsed)
+	}
+
+	if err == nil {
+		if result := <-Srv.Store.Queue().Delete(event.Id); result.Err != nil {
+			l4g.Error("Unable to remove processed event from the queue, err=%v", result.Err.Error())
+		}
+
+		wakeQueue()
+		return
+	}
+
+	event.Fail(err, maxAttempts, int64(*settings.RetryBackoffMillis), model.GetMillis())
+
+	if event.Status == model.QUEUE_STATUS_FAILED {
+		l4g.Error("Giving up on queued event %v after %v attempts, err=%v", event.Id, event.Attempts, err.Error())
+	} else {
+		l4g.Warn("Unable to process queued event %v, will retry, err=%v", event.Id, err.Error())
+	}
+
+	if result := <-Srv.Store.Queue().Update(event); result.Err != nil {
+		l4g.Error("Unable to update queued event, err=%v", result.Err.Error())
+	}
+}
+
+// getQueueHandler lists queued events with ?status=, by default the ones
+// that failed
+func getQueueHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if len(status) == 0 {
+		status = model.QUEUE_STATUS_FAILED
+	}
+
+	limit := getIntParam(r, "limit", DEFAULT_QUEUE_LIMIT, MAX_QUEUE_LIMIT)
+
+	if result := <-Srv.Store.Queue().GetByStatus(status, limit); result.Err != nil {
+		l4g.Error("Failed to load queued events, err=%v", result.Err.Error())
+		http.Error(w, "failed to load queued events", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.QueuedEventListToJson(result.Data.([]*model.QueuedEvent)))
+	}
+}
+
+// retryQueuedEventHandler puts a failed event back in the queue
+func retryQueuedEventHandler(w http.ResponseWriter, r *http.Request) {
+	var event *model.QueuedEvent
+	if result := <-Srv.Store.Queue().Get(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, "queued event not found", http.StatusNotFound)
+		return
+	} else {
+		event = result.Data.(*model.QueuedEvent)
+	}
+
+	if event.Status != model.QUEUE_STATUS_FAILED {
+		http.Error(w, "only failed events can be retried", http.StatusBadRequest)
+		return
+	}
+
+	event.Status = model.QUEUE_STATUS_PENDING
+	event.Attempts = 0
+	event.NextAttemptAt = 0
+	event.UpdateAt = model.GetMillis()
+
+	if result := <-Srv.Store.Queue().Update(event); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	l4g.Info("%v retried queued event %v", getActor(r), event.Id)
+
+	wakeQueue()
+	writeJson(w, event.ToJson())
+}
+
+func deleteQueuedEventHandler(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.Queue().Delete(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
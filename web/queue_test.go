@@ -0,0 +1,41 @@
+package web
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestDispatchQueuedEventsPastBlockedEvents(t *testing.T) {
+	ts := setupTestServer(false)
+	*Srv.Cfg.QueueSettings.BatchSize = 2
+
+	ts.queue.events = []*model.QueuedEvent{
+		{Id: "0", PartitionKey: "busy", Status: model.QUEUE_STATUS_PROCESSING},
+	}
+	for i := 1; i <= 5; i++ {
+		ts.queue.events = append(ts.queue.events, &model.QueuedEvent{Id: strconv.Itoa(i), PartitionKey: "busy", Status: model.QUEUE_STATUS_PENDING})
+	}
+	ts.queue.events = append(ts.queue.events,
+		&model.QueuedEvent{Id: "6", PartitionKey: "other", Status: model.QUEUE_STATUS_PENDING},
+		&model.QueuedEvent{Id: "7", PartitionKey: "other", Status: model.QUEUE_STATUS_PENDING},
+		&model.QueuedEvent{Id: "8", PartitionKey: "third", Status: model.QUEUE_STATUS_PENDING},
+		&model.QueuedEvent{Id: "9", PartitionKey: "fourth", Status: model.QUEUE_STATUS_PENDING},
+	)
+
+	workers := []chan *model.QueuedEvent{make(chan *model.QueuedEvent, 2)}
+	dispatchQueuedEvents(workers)
+	close(workers[0])
+
+	dispatched := []string{}
+	for event := range workers[0] {
+		dispatched = append(dispatched, event.Id)
+	}
+
+	if len(dispatched) != 2 || dispatched[0] != "6" || dispatched[1] != "8" {
+		t.Fatal("should have dispatched the first events of the other users", dispatched)
+	} else if ts.queue.events[6].Status != model.QUEUE_STATUS_PROCESSING || ts.queue.events[1].Status != model.QUEUE_STATUS_PENDING {
+		t.Fatal("only the dispatched events should be processing")
+	}
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initRecomputeApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/recompute", requireRole(model.ROLE_ADMIN, recomputeHandler)).Methods("POST")
+}
+
+// RecomputeScores rescores the leaderboard's ledger under the current
+// scoring settings and rebuilds every user's points from it. The report
+// lists how the rankings change, and unless commit is set nothing is saved.
+func RecomputeScores(leaderboardId string, commit bool, actor string) (*model.RecomputeReport, error) {
+	var ledger []*model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().GetHistory(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		ledger = result.Data.([]*model.LedgerEntry)
+	}
+
+	var before []*model.LeaderboardEntry
+	if result := <-Srv.Store.LeaderboardEntry().GetRankings(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		before = result.Data.([]*model.LeaderboardEntry)
+	}
+
+	rescored := model.RescoreLedger(ledger, &Srv.Cfg)
+	entries := model.RebuildLeaderboardEntries(leaderboardId, ledger, activeScoreHalfLife())
+
+	beforeRankings, err := rankEntries(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterRankings, err := rankEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.RecomputeReport{
+		Commit:        commit,
+		LedgerEntries: len(ledger),
+		Rescored:      len(rescored),
+		Changes:       model.DiffRankings(beforeRankings, afterRankings),
+	}
+
+	if !commit {
+		return report, nil
+	}
+
+	if result := <-Srv.Store.LeaderboardEntry().Rebuild(leaderboardId, entries, rescored, int64(len(ledger))); result.Err != nil {
+		return nil, result.Err
+	}
+
+	audit := &model.Audit{
+		Actor:         actor,
+		Action:        model.AUDIT_ACTION_RECOMPUTE_SCORES,
+		LeaderboardId: leaderboardId,
+		Reason:        fmt.Sprintf("Recomputed %v ledger entries, %v rescored", len(ledger), len(rescored)),
+	}
+
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error("Failed to save audit, err=%v", result.Err.Error())
+	}
+
+	if err := publishRankChanges(leaderboardId); err != nil {
+		l4g.Error("Unable to publish rank changes, err=%v", err.Error())
+	}
+
+	l4g.Info("%v recomputed leaderboard %v, %v of %v ledger entries rescored", actor, leaderboardId, len(rescored), len(ledger))
+
+	return report, nil
+}
+
+func recomputeHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	request := model.RecomputeRequestFromJson(r.Body)
+	if request == nil {
+		http.Error(w, "invalid recompute request", http.StatusBadRequest)
+		return
+	}
+
+	if report, err := RecomputeScores(leaderboard.Id, request.Commit, getActor(r)); err != nil {
+		l4g.Error("Failed to recompute scores, err=%v", err.Error())
+		http.Error(w, "failed to recompute scores", http.StatusInternalServerError)
+	} else {
+		writeJson(w, report.ToJson())
+	}
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	SEASON_CHECK_INTERVAL = time.Hour
+	MAX_SEASON_STANDINGS  = 1000
+)
+
+func initSeasonApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/seasons", getSeasonsHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/seasons/{id}/standings", getSeasonStandingsHandler).Methods("GET")
+}
+
+// watchSeasons keeps the configured leaderboard's seasons rolling over until the server stops
+func watchSeasons() {
+	if !*Srv.Cfg.SeasonSettings.Enable {
+		return
+	}
+
+	checkSeasonRollover(Srv.Leaderboard.Id)
+
+	go func() {
+		ticker := time.NewTicker(SEASON_CHECK_INTERVAL)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			checkSeasonRollover(Srv.Leaderboard.Id)
+		}
+	}()
+}
+
+// checkSeasonRollover closes the current season once it has ended, archiving
+// its final standings, and starts the season containing the current time.
+// Nothing changes if the current season can't be loaded, so that an outage
+// doesn't start a second season.
+func checkSeasonRollover(leaderboardId string) {
+	now := time.Now()
+
+	if result := <-Srv.Store.Season().GetCurrent(leaderboardId); result.Err != nil {
+		l4g.Error("Unable to load current season, err=%v", result.Err.Error())
+		return
+	} else if season, ok := result.Data.(*model.Season); ok {
+		if model.GetMillis() < season.EndAt {
+			return
+		}
+
+		if err := closeSeason(season); err != nil {
+			l4g.Error("Unable to close season, err=%v", err.Error())
+			return
+		}
+	}
+
+	season := model.NewSeason(leaderboardId, now, *Srv.Cfg.SeasonSettings.LengthMonths)
+	if result := <-Srv.Store.Season().Save(season); result.Err != nil {
+		l4g.Error("Unable to start season, err=%v", result.Err.Error())
+	} else {
+		l4g.Info("Started season %v", season.Name)
+	}
+}
+
+func closeSeason(season *model.Season) error {
+	rankings, err := getSeasonRankings(season)
+	if err != nil {
+		return err
+	}
+
+	standings := model.NewSeasonStandings(season.Id, rankings)
+
+	if result := <-Srv.Store.Season().Close(season, standings); result.Err != nil {
+		return result.Err
+	}
+
+	l4g.Info("Closed season %v", season.Name)
+
+	winners := []*model.SeasonStanding{}
+	for _, standing := range standings {
+		if standing.Rank == 1 {
+			winners = append(winners, standing)
+		}
+	}
+
+	publish(model.NewSeasonClosedEvent(season, winners))
+
+	return nil
+}
+
+// getSeasonRankings ranks the points earned during the season
+func getSeasonRankings(season *model.Season) ([]*model.LeaderboardEntry, error) {
+	if result := <-Srv.Store.LedgerEntry().GetTotals(season.LeaderboardId, season.StartAt, season.EndAt); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return rankEntries(result.Data.([]*model.LeaderboardEntry))
+	}
+}
+
+// getSeasonStandings returns the archived standings of a closed season, or
+// the standings so far of an open one
+func getSeasonStandings(season *model.Season, limit int) ([]*model.SeasonStanding, error) {
+	if season.IsClosed() {
+		if result := <-Srv.Store.Season().GetStandings(season.Id, limit); result.Err != nil {
+			return nil, result.Err
+		} else {
+			return result.Data.([]*model.SeasonStanding), nil
+		}
+	}
+
+	rankings, err := getSeasonRankings(season)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := model.NewSeasonStandings(season.Id, rankings)
+	if len(standings) > limit {
+		standings = standings[:limit]
+	}
+
+	return standings, nil
+}
+
+func getSeasonsWithWinners(leaderboardId string) ([]*model.Season, error) {
+	if result := <-Srv.Store.Season().GetAll(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		seasons := result.Data.([]*model.Season)
+
+		for _, season := range seasons {
+			if winners, err := getSeasonStandings(season, model.SEASON_WINNERS); err != nil {
+				return nil, err
+			} else {
+				season.Winners = winners
+			}
+		}
+
+		return seasons, nil
+	}
+}
+
+// getSeason looks up the season in the request's route, writing a 404 and
+// returning nil if it doesn't belong to the leaderboard
+func getSeason(w http.ResponseWriter, r *http.Request, leaderboard *model.Leaderboard) *model.Season {
+	if result := <-Srv.Store.Season().Get(mux.Vars(r)["id"]); result.Err != nil || result.Data.(*model.Season).LeaderboardId != leaderboard.Id {
+		http.Error(w, "season not found", http.StatusNotFound)
+		return nil
+	} else {
+		return result.Data.(*model.Season)
+	}
+}
+
+func seasonsPage(w http.ResponseWriter, r *http.Request) {
+	page := NewHtmlTemplatePage("seasons", "Seasons")
+
+	if seasons, err := getSeasonsWithWinners(Srv.Leaderboard.Id); err != nil {
+		l4g.Error("Failed to load seasons, err=%v", err.Error())
+	} else {
+		page.Props["Seasons"] = seasons
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func seasonPage(w http.ResponseWriter, r *http.Request) {
+	season := getSeason(w, r, Srv.Leaderboard)
+	if season == nil {
+		return
+	}
+
+	page := NewHtmlTemplatePage("season", season.Name)
+	page.Props["Season"] = season
+
+	if standings, err := getSeasonStandings(season, MAX_SEASON_STANDINGS); err != nil {
+		l4g.Error("Failed to load season standings, err=%v", err.Error())
+	} else {
+		page.Props["Standings"] = standings
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func getSeasonsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if seasons, err := getSeasonsWithWinners(leaderboard.Id); err != nil {
+		l4g.Error("Failed to load seasons, err=%v", err.Error())
+		http.Error(w, "failed to load seasons", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.SeasonListToJson(seasons))
+	}
+}
+
+func getSeasonStandingsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	season := getSeason(w, r, leaderboard)
+	if season == nil {
+		return
+	}
+
+	if standings, err := getSeasonStandings(season, MAX_SEASON_STANDINGS); err != nil {
+		l4g.Error("Failed to load season standings, err=%v", err.Error())
+		http.Error(w, "failed to load season standings", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.SeasonStandingListToJson(standings))
+	}
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestCheckSeasonRollover(t *testing.T) {
+	ts := setupTestServer(false)
+
+	ts.seasons.err = errors.New("database unavailable")
+	checkSeasonRollover(Srv.Leaderboard.Id)
+	if len(ts.seasons.saved) != 0 {
+		t.Fatal("a season shouldn't be started when the current one can't be loaded")
+	}
+
+	ts.seasons.err = nil
+	ts.seasons.current = model.NewSeason(Srv.Leaderboard.Id, time.Now(), 3)
+	checkSeasonRollover(Srv.Leaderboard.Id)
+	if len(ts.seasons.saved) != 0 {
+		t.Fatal("a season shouldn't be started while the current one is open")
+	}
+
+	ts.seasons.current = nil
+	checkSeasonRollover(Srv.Leaderboard.Id)
+	if len(ts.seasons.saved) != 1 {
+		t.Fatal("a season should be started when there is none")
+	}
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/jwilander/contributor-leaderboard/model"
 	"github.com/jwilander/contributor-leaderboard/store"
+	"github.com/jwilander/contributor-leaderboard/utils"
 )
 
 type Server struct {
@@ -16,6 +18,7 @@ type Server struct {
 	Server      *http.Server
 	Cfg         model.Config
 	Leaderboard *model.Leaderboard
+	Hub         *utils.Hub
 }
 
 type CorsWrapper struct {
@@ -24,35 +27,93 @@ type CorsWrapper struct {
 
 var Srv *Server
 
-func StartServer(config model.Config) {
+// InitServer connects to the store and loads the configured leaderboard,
+// enough to score events from the command line. Notifications and webhooks
+// are only set up by StartServer.
+func InitServer(config model.Config) error {
+	if !model.IsValidVisibility(*config.LeaderboardVisibility) {
+		return errors.New("Invalid LeaderboardVisibility, visibility=" + *config.LeaderboardVisibility)
+	}
+
 	Srv = &Server{}
 
 	Srv.Cfg = config
 
 	Srv.Store = store.NewSqlStore(*config.DatabaseSource)
 
+	leaderboard := &model.Leaderboard{
+		Name:       *config.LeaderboardName,
+		Visibility: *config.LeaderboardVisibility,
+	}
+
+	if result := <-Srv.Store.Leaderboard().Save(leaderboard); result.Err != nil {
+		return result.Err
+	} else {
+		Srv.Leaderboard = result.Data.(*model.Leaderboard)
+	}
+
+	if Srv.Leaderboard.Visibility != *config.LeaderboardVisibility {
+		if result := <-Srv.Store.Leaderboard().UpdateVisibility(Srv.Leaderboard.Id, *config.LeaderboardVisibility); result.Err != nil {
+			return result.Err
+		}
+
+		l4g.Info("Made leaderboard %v %v as configured", Srv.Leaderboard.Name, *config.LeaderboardVisibility)
+		Srv.Leaderboard.Visibility = *config.LeaderboardVisibility
+	}
+
+	initHub()
+
+	return nil
+}
+
+func StartServer(config model.Config) {
+	if err := InitServer(config); err != nil {
+		l4g.Critical("Unable to start server, err=%v", err.Error())
+		return
+	}
+
+	if len(*Srv.Cfg.AuthSettings.ShareLinkSecret) == 0 {
+		l4g.Warn("No AuthSettings.ShareLinkSecret is configured, share links will stop working when the server restarts")
+		*Srv.Cfg.AuthSettings.ShareLinkSecret = model.NewSecret()
+	}
+
 	Srv.Router = mux.NewRouter()
 
 	Srv.Server = &http.Server{
 		Addr:         ":8075",
-		Handler:      Srv.Router,
+		Handler:      authMiddleware(Srv.Router),
 		ReadTimeout:  20 * time.Second,
 		WriteTimeout: 20 * time.Second,
 	}
 
-	leaderboard := &model.Leaderboard{
-		Name: *config.LeaderboardName,
+	if result := <-Srv.Store.LeaderboardEntry().BackfillActivePoints(Srv.Leaderboard.Id, activeScoreHalfLife()); result.Err != nil {
+		l4g.Error("Unable to backfill active points, err=%v", result.Err.Error())
 	}
 
-	if result := <-Srv.Store.Leaderboard().Save(leaderboard); result.Err != nil {
-		l4g.Critical("Unable to create leaderboard, err=%v", result.Err.Error())
-		return
-	} else {
-		Srv.Leaderboard = result.Data.(*model.Leaderboard)
+	if err := syncTeams(); err != nil {
+		l4g.Error("Unable to sync teams, err=%v", err.Error())
 	}
 
+	initNotifier()
+
+	initWebhooks()
+
 	InitWeb()
 
+	watchSeasons()
+
+	watchSnapshots()
+
+	watchDigests()
+
+	startQueueWorkers()
+
+	go func() {
+		if err := evaluateAllAchievements(Srv.Leaderboard.Id); err != nil {
+			l4g.Error("Unable to evaluate achievements, err=%v", err.Error())
+		}
+	}()
+
 	go func() {
 		Srv.Server.ListenAndServe()
 	}()
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/store"
+)
+
+const TEST_SHARE_SECRET = "test-share-secret"
+
+func testStoreResult(data interface{}, err error) store.StoreChannel {
+	storeChannel := make(store.StoreChannel, 1)
+	storeChannel <- store.StoreResult{Data: data, Err: err}
+	close(storeChannel)
+	return storeChannel
+}
+
+// testStore implements the stores the web tests use in memory. Calling any
+// other store panics.
+type testStore struct {
+	store.Store
+	leaderboards *testLeaderboardStore
+	contributors *testContributorStore
+	entries      *testLeaderboardEntryStore
+	ledger       *testLedgerEntryStore
+	seasons      *testSeasonStore
+	queue        *testQueueStore
+}
+
+func (s *testStore) Leaderboard() store.LeaderboardStore {
+	return s.leaderboards
+}
+
+func (s *testStore) Contributor() store.ContributorStore {
+	return s.contributors
+}
+
+func (s *testStore) LeaderboardEntry() store.LeaderboardEntryStore {
+	return s.entries
+}
+
+func (s *testStore) LedgerEntry() store.LedgerEntryStore {
+	return s.ledger
+}
+
+func (s *testStore) Season() store.SeasonStore {
+	return s.seasons
+}
+
+func (s *testStore) Queue() store.QueueStore {
+	return s.queue
+}
+
+type testLeaderboardStore struct {
+	store.LeaderboardStore
+	byName  map[string]*model.Leaderboard
+	viewers map[string]bool
+}
+
+func (s *testLeaderboardStore) GetByName(name string) store.StoreChannel {
+	if leaderboard, ok := s.byName[name]; ok {
+		return testStoreResult(leaderboard, nil)
+	}
+
+	return testStoreResult(nil, errors.New("leaderboard not found"))
+}
+
+func (s *testLeaderboardStore) IsViewer(leaderboardId string, username string) store.StoreChannel {
+	return testStoreResult(s.viewers[leaderboardId+":"+username], nil)
+}
+
+type testContributorStore struct {
+	store.ContributorStore
+	contributors []*model.Contributor
+	accounts     []*model.ContributorAccount
+}
+
+func (s *testContributorStore) GetAll() store.StoreChannel {
+	return testStoreResult(s.contributors, nil)
+}
+
+func (s *testContributorStore) GetAllAccounts() store.StoreChannel {
+	return testStoreResult(s.accounts, nil)
+}
+
+// testLeaderboardEntryStore adds awarded ledger entries to the ledger store
+type testLeaderboardEntryStore struct {
+	store.LeaderboardEntryStore
+	ledger *testLedgerEntryStore
+}
+
+func (s *testLeaderboardEntryStore) Save(entry *model.LeaderboardEntry) store.StoreChannel {
+	return testStoreResult(entry, nil)
+}
+
+func (s *testLeaderboardEntryStore) Award(ledgerEntry *model.LedgerEntry, halfLife int64) store.StoreChannel {
+	ledgerEntry.PreSave()
+	s.ledger.entries = append(s.ledger.entries, ledgerEntry)
+	return testStoreResult(ledgerEntry, nil)
+}
+
+type testLedgerEntryStore struct {
+	store.LedgerEntryStore
+	entries []*model.LedgerEntry
+}
+
+func (s *testLedgerEntryStore) GetByUrl(leaderboardId string, url string, entryType string) store.StoreChannel {
+	entries := []*model.LedgerEntry{}
+	for _, entry := range s.entries {
+		if entry.LeaderboardId == leaderboardId && entry.Url == url && entry.Type == entryType {
+			entries = append(entries, entry)
+		}
+	}
+
+	return testStoreResult(entries, nil)
+}
+
+func (s *testLedgerEntryStore) GetByNumber(leaderboardId string, repository string, number int, entryType string) store.StoreChannel {
+	for _, entry := range s.entries {
+		if entry.LeaderboardId == leaderboardId && entry.Repository == repository && entry.Number == number && entry.Type == entryType {
+			return testStoreResult(entry, nil)
+		}
+	}
+
+	return testStoreResult(nil, errors.New("not found"))
+}
+
+func (s *testLedgerEntryStore) GetByRelatedId(relatedId string) store.StoreChannel {
+	entries := []*model.LedgerEntry{}
+	for _, entry := range s.entries {
+		if entry.RelatedId == relatedId {
+			entries = append(entries, entry)
+		}
+	}
+
+	return testStoreResult(entries, nil)
+}
+
+func (s *testLedgerEntryStore) GetForUser(leaderboardId string, username string) store.StoreChannel {
+	entries := []*model.LedgerEntry{}
+	for _, entry := range s.entries {
+		if entry.LeaderboardId == leaderboardId && entry.Username == username {
+			entries = append(entries, entry)
+		}
+	}
+
+	return testStoreResult(entries, nil)
+}
+
+// testSeasonStore holds the open season, or fails to load it with err
+type testSeasonStore struct {
+	store.SeasonStore
+	current *model.Season
+	err     error
+	saved   []*model.Season
+}
+
+func (s *testSeasonStore) GetCurrent(leaderboardId string) store.StoreChannel {
+	if s.err != nil || s.current == nil {
+		return testStoreResult(nil, s.err)
+	}
+
+	return testStoreResult(s.current, nil)
+}
+
+func (s *testSeasonStore) Save(season *model.Season) store.StoreChannel {
+	s.saved = append(s.saved, season)
+	return testStoreResult(season, nil)
+}
+
+// testQueueStore holds the unfinished queued events, oldest first
+type testQueueStore struct {
+	store.QueueStore
+	events []*model.QueuedEvent
+}
+
+func (s *testQueueStore) GetUnfinished(offset int, limit int) store.StoreChannel {
+	events := []*model.QueuedEvent{}
+	for i := offset; i < len(s.events) && len(events) < limit; i++ {
+		events = append(events, s.events[i])
+	}
+
+	return testStoreResult(events, nil)
+}
+
+func (s *testQueueStore) Update(event *model.QueuedEvent) store.StoreChannel {
+	return testStoreResult(event, nil)
+}
+
+// setupTestServer points Srv at an in memory store holding the server's
+// leaderboard, named "main", and the given leaderboards, with the API routes
+// registered
+func setupTestServer(requireLogin bool, leaderboards ...*model.Leaderboard) *testStore {
+	cfg := model.Config{}
+	cfg.SetDefaults()
+	*cfg.AuthSettings.RequireLogin = requireLogin
+	*cfg.AuthSettings.ShareLinkSecret = TEST_SHARE_SECRET
+	*cfg.AdminToken = "test-admin-token"
+
+	main := &model.Leaderboard{Name: "main"}
+	main.PreSave()
+
+	ts := &testStore{
+		leaderboards: &testLeaderboardStore{
+			byName:  map[string]*model.Leaderboard{main.Name: main},
+			viewers: map[string]bool{},
+		},
+		contributors: &testContributorStore{},
+		ledger:       &testLedgerEntryStore{},
+		seasons:      &testSeasonStore{},
+		queue:        &testQueueStore{},
+	}
+	ts.entries = &testLeaderboardEntryStore{ledger: ts.ledger}
+
+	for _, leaderboard := range leaderboards {
+		leaderboard.PreSave()
+		ts.leaderboards.byName[leaderboard.Name] = leaderboard
+	}
+
+	Srv = &Server{Cfg: cfg, Store: ts, Router: mux.NewRouter(), Leaderboard: main}
+	InitApi()
+
+	return ts
+}
+
+func testRequest(method string, target string) *http.Request {
+	return httptest.NewRequest(method, target, strings.NewReader(""))
+}
+
+func testShareLink(leaderboard *model.Leaderboard) string {
+	return model.NewShareToken(TEST_SHARE_SECRET, leaderboard.Id, model.GetMillis()+model.MILLIS_PER_DAY)
+}
@@ -0,0 +1,128 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+const (
+	SNAPSHOT_INTERVAL       = time.Hour
+	DEFAULT_TRAJECTORY_DAYS = 90
+)
+
+func initSnapshotApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/rankings/movements", getMovementsHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/users/{username}/ranks", getUserRanksHandler).Methods("GET")
+}
+
+// watchSnapshots keeps today's rank snapshot of every leaderboard up to date
+// until the server stops. The last snapshot taken on a day is the one kept.
+func watchSnapshots() {
+	takeSnapshots()
+
+	go func() {
+		ticker := time.NewTicker(SNAPSHOT_INTERVAL)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			takeSnapshots()
+		}
+	}()
+}
+
+func takeSnapshots() {
+	if result := <-Srv.Store.Leaderboard().GetAll(); result.Err != nil {
+		l4g.Error("Unable to load leaderboards for snapshots, err=%v", result.Err.Error())
+	} else {
+		for _, leaderboard := range result.Data.([]*model.Leaderboard) {
+			if err := takeSnapshot(leaderboard.Id, model.SnapshotDay(model.GetMillis())); err != nil {
+				l4g.Error("Unable to take rank snapshot, leaderboard=%v, err=%v", leaderboard.Name, err.Error())
+			}
+		}
+	}
+}
+
+func takeSnapshot(leaderboardId string, day int64) error {
+	rankings, err := getRankings(leaderboardId, false)
+	if err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.Snapshot().Save(leaderboardId, day, model.NewRankSnapshots(leaderboardId, day, rankings)); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// getMovements compares the current rankings with the latest snapshot from
+// before the movement period, a day or a week
+func getMovements(leaderboardId string, movement string) (map[string]*model.RankMovement, error) {
+	rankings, err := getRankings(leaderboardId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []*model.RankSnapshot{}
+
+	if result := <-Srv.Store.Snapshot().GetLatestDay(leaderboardId, model.MovementSince(movement, model.GetMillis())); result.Err != nil {
+		return nil, result.Err
+	} else if day := result.Data.(int64); day > 0 {
+		if result := <-Srv.Store.Snapshot().GetForDay(leaderboardId, day); result.Err != nil {
+			return nil, result.Err
+		} else {
+			previous = result.Data.([]*model.RankSnapshot)
+		}
+	}
+
+	return model.ComputeMovements(rankings, previous), nil
+}
+
+func getMovementParam(r *http.Request) string {
+	if r.URL.Query().Get("movement") == model.MOVEMENT_WEEK {
+		return model.MOVEMENT_WEEK
+	}
+
+	return model.MOVEMENT_DAY
+}
+
+func getMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if movements, err := getMovements(leaderboard.Id, getMovementParam(r)); err != nil {
+		l4g.Error("Failed to load rank movements, err=%v", err.Error())
+		http.Error(w, "failed to load rank movements", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.RankMovementMapToJson(movements))
+	}
+}
+
+// getUserRanksHandler returns the user's daily ranks over the last days, 90 by default
+func getUserRanksHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	days := DEFAULT_TRAJECTORY_DAYS
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 {
+		days = d
+	}
+
+	since := model.SnapshotDay(model.GetMillis()) - int64(days)*model.MILLIS_PER_DAY
+
+	if result := <-Srv.Store.Snapshot().GetForUser(leaderboard.Id, mux.Vars(r)["username"], since); result.Err != nil {
+		l4g.Error("Failed to load rank snapshots, err=%v", result.Err.Error())
+		http.Error(w, "failed to load rank history", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.RankSnapshotListToJson(result.Data.([]*model.RankSnapshot)))
+	}
+}
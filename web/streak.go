@@ -0,0 +1,186 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initStreakApi(api *mux.Router) {
+	api.HandleFunc("/leaderboards/{leaderboard}/streaks", getStreaksHandler).Methods("GET")
+	api.HandleFunc("/leaderboards/{leaderboard}/users/{username}/streak", getUserStreakHandler).Methods("GET")
+}
+
+// getRankedTimeline returns the leaderboard's timeline with excluded users
+// left out and accounts combined into their contributor like the rankings
+func getRankedTimeline(leaderboardId string) ([]*model.LedgerEntry, error) {
+	var timeline []*model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().GetTimeline(leaderboardId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		timeline = result.Data.([]*model.LedgerEntry)
+	}
+
+	byLogin, err := getContributorsByLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*model.LedgerEntry, 0, len(timeline))
+	for _, entry := range timeline {
+		if Srv.Cfg.ExclusionSettings.IsExcluded(entry.Username, "") {
+			continue
+		}
+
+		if contributor, ok := byLogin[strings.ToLower(entry.Username)]; ok {
+			entry.Username = contributor.Name
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// getStreaks returns everyone's streak on the leaderboard, keyed by username
+// with accounts combined into their contributor like the rankings
+func getStreaks(leaderboardId string) (map[string]*model.Streak, error) {
+	entries, err := getRankedTimeline(leaderboardId)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ComputeStreaks(entries, *Srv.Cfg.StreakSettings.Period, model.GetMillis()), nil
+}
+
+// getUserStreak returns the streak of a login or contributor, combining the
+// contributions of all of the contributor's logins
+func getUserStreak(leaderboardId string, username string) (*model.Streak, error) {
+	name, logins, err := getProfileLogins(username)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*model.LedgerEntry{}
+	for _, login := range logins {
+		if result := <-Srv.Store.LedgerEntry().GetForUser(leaderboardId, login); result.Err != nil {
+			return nil, result.Err
+		} else {
+			history = append(history, result.Data.([]*model.LedgerEntry)...)
+		}
+	}
+
+	return computeContributorStreak(name, history, model.GetMillis()), nil
+}
+
+// computeContributorStreak computes the streak of the contributor from the
+// ledger entries of all their logins
+func computeContributorStreak(name string, history []*model.LedgerEntry, now int64) *model.Streak {
+	entries := make([]*model.LedgerEntry, 0, len(history))
+	for _, entry := range history {
+		copied := *entry
+		copied.Username = name
+		entries = append(entries, &copied)
+	}
+
+	if streak, ok := model.ComputeStreaks(entries, *Srv.Cfg.StreakSettings.Period, now)[name]; ok {
+		return streak
+	}
+
+	return &model.Streak{Username: name, Period: *Srv.Cfg.StreakSettings.Period}
+}
+
+// withStreakBonus wraps award to also award the streak bonus of each ledger
+// entry it awards
+func withStreakBonus(award awardFunc) awardFunc {
+	return func(ledgerEntry *model.LedgerEntry) error {
+		if err := award(ledgerEntry); err != nil {
+			return err
+		}
+
+		return awardStreakBonus(ledgerEntry, award)
+	}
+}
+
+// awardStreakBonus grants the configured bonus when the contribution extends
+// the user's streak to a multiple of the bonus interval. Only the first
+// contribution in a period can extend a streak. The contribution doesn't
+// have to be saved yet, so that replays can be tried out.
+func awardStreakBonus(ledgerEntry *model.LedgerEntry, award awardFunc) error {
+	bonus := *Srv.Cfg.StreakSettings.BonusPoints
+	interval := *Srv.Cfg.StreakSettings.BonusInterval
+	period := *Srv.Cfg.StreakSettings.Period
+
+	if bonus <= 0 || interval <= 0 || !ledgerEntry.IsContribution() {
+		return nil
+	}
+
+	var history []*model.LedgerEntry
+	if result := <-Srv.Store.LedgerEntry().GetForUser(ledgerEntry.LeaderboardId, ledgerEntry.Username); result.Err != nil {
+		return result.Err
+	} else {
+		history = result.Data.([]*model.LedgerEntry)
+	}
+
+	if len(ledgerEntry.Id) == 0 {
+		history = append(history, ledgerEntry)
+	}
+
+	index := model.PeriodIndex(ledgerEntry.CreateAt, period)
+	for _, entry := range history {
+		if entry.Id == ledgerEntry.Id || model.PeriodIndex(entry.CreateAt, period) != index {
+			continue
+		}
+
+		if entry.IsContribution() || entry.Type == model.LEDGER_TYPE_STREAK_BONUS {
+			return nil
+		}
+	}
+
+	streak := model.ComputeStreaks(history, period, ledgerEntry.CreateAt)[ledgerEntry.Username]
+	if streak == nil || streak.Current == 0 || streak.Current%interval != 0 {
+		return nil
+	}
+
+	return award(&model.LedgerEntry{
+		LeaderboardId: ledgerEntry.LeaderboardId,
+		Username:      ledgerEntry.Username,
+		Type:          model.LEDGER_TYPE_STREAK_BONUS,
+		Points:        bonus,
+		Title:         fmt.Sprintf("%v %v streak", streak.Current, period),
+		RelatedId:     ledgerEntry.Id,
+		CreateAt:      ledgerEntry.CreateAt,
+	})
+}
+
+func getStreaksHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if streaks, err := getStreaks(leaderboard.Id); err != nil {
+		l4g.Error("Failed to load streaks, err=%v", err.Error())
+		http.Error(w, "failed to load streaks", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.StreakMapToJson(streaks))
+	}
+}
+
+func getUserStreakHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if streak, err := getUserStreak(leaderboard.Id, mux.Vars(r)["username"]); err != nil {
+		l4g.Error("Failed to load streak, err=%v", err.Error())
+		http.Error(w, "failed to load streak", http.StatusInternalServerError)
+	} else {
+		writeJson(w, streak.ToJson())
+	}
+}
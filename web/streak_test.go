@@ -0,0 +1,79 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func TestReplayEventAwardsStreakBonus(t *testing.T) {
+	ts := setupTestServer(false)
+	*Srv.Cfg.StreakSettings.Period = model.STREAK_PERIOD_WEEK
+	*Srv.Cfg.StreakSettings.BonusPoints = 5
+	*Srv.Cfg.StreakSettings.BonusInterval = 2
+
+	lastWeek := model.GetMillis() - 7*model.MILLIS_PER_DAY
+	ts.ledger.entries = append(ts.ledger.entries, &model.LedgerEntry{
+		Id:            model.NewId(),
+		LeaderboardId: Srv.Leaderboard.Id,
+		Username:      "author",
+		Type:          model.LEDGER_TYPE_COMMIT,
+		Points:        1,
+		Url:           "https://github.com/org/repo/commit/1",
+		CreateAt:      lastWeek,
+	})
+
+	event := &model.Event{}
+	event.Commit.User.Login = "author"
+	event.Commit.User.Type = "User"
+	event.Commit.HtmlUrl = "https://github.com/org/repo/commit/2"
+
+	replayed := replayEvent(model.EVENT_TYPE_COMMIT, event, model.GetMillis(), false)
+	if len(replayed.Error) > 0 {
+		t.Fatal(replayed.Error)
+	}
+
+	if len(replayed.Awarded) != 2 || replayed.Awarded[0].Type != model.LEDGER_TYPE_COMMIT || replayed.Awarded[1].Type != model.LEDGER_TYPE_STREAK_BONUS {
+		t.Fatal("the dry run should include the streak bonus", replayed.Awarded)
+	}
+
+	if replayed.Awarded[1].Points != 5 {
+		t.Fatal("wrong streak bonus", replayed.Awarded[1].Points)
+	}
+
+	replayed = replayEvent(model.EVENT_TYPE_COMMIT, event, lastWeek, false)
+	if len(replayed.Awarded) != 1 {
+		t.Fatal("a second contribution in the same period shouldn't earn a bonus", replayed.Awarded)
+	}
+}
+
+func TestGetUserStreakCombinesLogins(t *testing.T) {
+	ts := setupTestServer(false)
+	*Srv.Cfg.StreakSettings.Period = model.STREAK_PERIOD_WEEK
+
+	contributor := &model.Contributor{Id: model.NewId(), Name: "Joram"}
+	ts.contributors.contributors = []*model.Contributor{contributor}
+	ts.contributors.accounts = []*model.ContributorAccount{
+		{ContributorId: contributor.Id, Login: "jwilander"},
+		{ContributorId: contributor.Id, Login: "jwilander-work"},
+	}
+
+	now := model.GetMillis()
+	for i, login := range []string{"jwilander", "jwilander-work"} {
+		ts.ledger.entries = append(ts.ledger.entries, &model.LedgerEntry{
+			Id:            model.NewId(),
+			LeaderboardId: Srv.Leaderboard.Id,
+			Username:      login,
+			Type:          model.LEDGER_TYPE_COMMIT,
+			CreateAt:      now - int64(i)*7*model.MILLIS_PER_DAY,
+		})
+	}
+
+	for _, username := range []string{"jwilander", "Joram"} {
+		if streak, err := getUserStreak(Srv.Leaderboard.Id, username); err != nil {
+			t.Fatal(err)
+		} else if streak.Username != "Joram" || streak.Current != 2 {
+			t.Fatal("the streak should combine the contributor's logins", username, streak)
+		}
+	}
+}
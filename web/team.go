@@ -0,0 +1,223 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+)
+
+func initTeamApi(api *mux.Router) {
+	api.HandleFunc("/teams", getTeams).Methods("GET")
+	api.HandleFunc("/teams", requireRole(model.ROLE_ADMIN, createTeam)).Methods("POST")
+	api.HandleFunc("/teams/sync", requireRole(model.ROLE_ADMIN, syncTeamsHandler)).Methods("POST")
+	api.HandleFunc("/teams/{id}", requireRole(model.ROLE_ADMIN, deleteTeam)).Methods("DELETE")
+	api.HandleFunc("/teams/{id}/members", requireRole(model.ROLE_MODERATOR, saveTeamMember)).Methods("PUT")
+	api.HandleFunc("/teams/{id}/members/{username}", requireRole(model.ROLE_MODERATOR, removeTeamMember)).Methods("DELETE")
+	api.HandleFunc("/leaderboards/{leaderboard}/teams/rankings", getTeamRankingsHandler).Methods("GET")
+}
+
+// syncTeams makes the teams listed in the membership file have exactly the
+// members listed there. Teams missing from the file are left alone so they
+// can still be managed through the API.
+func syncTeams() error {
+	fileName := *Srv.Cfg.TeamSettings.MembershipFile
+	if len(fileName) == 0 {
+		return nil
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return errors.New("Unable to open team membership file, file=" + fileName + ", " + err.Error())
+	}
+	defer file.Close()
+
+	teams := model.TeamListFromJson(file)
+	if teams == nil {
+		return errors.New("Unable to parse team membership file, file=" + fileName)
+	}
+
+	for _, team := range teams {
+		members := team.Members
+
+		if result := <-Srv.Store.Team().GetByName(team.Name); result.Err == nil {
+			team = result.Data.(*model.Team)
+		} else {
+			team.Id = ""
+			if result := <-Srv.Store.Team().Save(team); result.Err != nil {
+				return result.Err
+			}
+		}
+
+		if result := <-Srv.Store.Team().ReplaceMembers(team.Id, members); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	l4g.Info("Synced %v teams from %v", len(teams), fileName)
+
+	return nil
+}
+
+func getTeamsWithMembers() ([]*model.Team, []*model.TeamMember, error) {
+	tchan := Srv.Store.Team().GetAll()
+	mchan := Srv.Store.Team().GetAllMembers()
+
+	var teams []*model.Team
+	if result := <-tchan; result.Err != nil {
+		return nil, nil, result.Err
+	} else {
+		teams = result.Data.([]*model.Team)
+	}
+
+	if result := <-mchan; result.Err != nil {
+		return nil, nil, result.Err
+	} else {
+		members := result.Data.([]*model.TeamMember)
+
+		byId := make(map[string]*model.Team, len(teams))
+		for _, team := range teams {
+			team.Members = []*model.TeamMember{}
+			byId[team.Id] = team
+		}
+
+		for _, member := range members {
+			if team, ok := byId[member.TeamId]; ok {
+				team.Members = append(team.Members, member)
+			}
+		}
+
+		return teams, members, nil
+	}
+}
+
+func getTeamRankings(leaderboardId string) ([]*model.TeamRanking, error) {
+	teams, members, err := getTeamsWithMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	byLogin, err := getContributorsByLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	rankings, err := getRankings(leaderboardId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ComputeTeamRankings(teams, members, rankings, byLogin), nil
+}
+
+func teamsPage(w http.ResponseWriter, r *http.Request) {
+	page := NewHtmlTemplatePage("teams", "Team Leaderboard")
+
+	if teamRankings, err := getTeamRankings(Srv.Leaderboard.Id); err != nil {
+		l4g.Error("Failed to load team rankings, err=%v", err.Error())
+	} else {
+		page.Props["TeamRankings"] = teamRankings
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
+	page.Render(w)
+}
+
+func getTeams(w http.ResponseWriter, r *http.Request) {
+	if teams, _, err := getTeamsWithMembers(); err != nil {
+		l4g.Error("Failed to load teams, err=%v", err.Error())
+		http.Error(w, "failed to load teams", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.TeamListToJson(teams))
+	}
+}
+
+func getTeamRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if teamRankings, err := getTeamRankings(leaderboard.Id); err != nil {
+		l4g.Error("Failed to load team rankings, err=%v", err.Error())
+		http.Error(w, "failed to load team rankings", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.TeamRankingListToJson(teamRankings))
+	}
+}
+
+func createTeam(w http.ResponseWriter, r *http.Request) {
+	team := model.TeamFromJson(r.Body)
+	if team == nil {
+		http.Error(w, "invalid team", http.StatusBadRequest)
+		return
+	}
+
+	members := team.Members
+	team.Id = ""
+	team.Members = nil
+
+	if result := <-Srv.Store.Team().Save(team); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(members) > 0 {
+		if result := <-Srv.Store.Team().ReplaceMembers(team.Id, members); result.Err != nil {
+			http.Error(w, result.Err.Error(), http.StatusBadRequest)
+			return
+		}
+		team.Members = members
+	}
+
+	writeCreatedJson(w, team.ToJson())
+}
+
+func deleteTeam(w http.ResponseWriter, r *http.Request) {
+	if result := <-Srv.Store.Team().Delete(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func saveTeamMember(w http.ResponseWriter, r *http.Request) {
+	member := model.TeamMemberFromJson(r.Body)
+	if member == nil {
+		http.Error(w, "invalid team member", http.StatusBadRequest)
+		return
+	}
+
+	member.TeamId = mux.Vars(r)["id"]
+
+	if result := <-Srv.Store.Team().SaveMember(member); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+	} else {
+		writeJson(w, member.ToJson())
+	}
+}
+
+func removeTeamMember(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	if result := <-Srv.Store.Team().RemoveMember(params["id"], params["username"]); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func syncTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := syncTeams(); err != nil {
+		l4g.Error("Failed to sync teams, err=%v", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	getTeams(w, r)
+}
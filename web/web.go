@@ -1,7 +1,9 @@
 package web
 
 import (
+	"bytes"
 	"html/template"
+	"io"
 	"net/http"
 
 	l4g "github.com/alecthomas/log4go"
@@ -9,6 +11,15 @@ import (
 	"gopkg.in/fsnotify.v1"
 )
 
+const (
+	RECENT_HISTORY_LIMIT = 20
+	MAX_EVENT_SIZE       = 25 << 20
+
+	// Images of private leaderboards must not be kept by shared caches, which
+	// would serve them to anyone
+	PRIVATE_CACHE_CONTROL = "private, no-store"
+)
+
 var Templates *template.Template
 
 type HtmlTemplatePage struct {
@@ -36,9 +47,29 @@ func InitWeb() {
 	l4g.Debug("Using client directory at %v", "web/static/")
 	mainrouter.PathPrefix("/static/").Handler(staticHandler(http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/")))))
 
-	mainrouter.HandleFunc("/", root).Methods("GET")
+	mainrouter.HandleFunc("/", requireLeaderboardAccess(root)).Methods("GET")
+	mainrouter.HandleFunc("/newcomers", requireLeaderboardAccess(newcomersPage)).Methods("GET")
+	mainrouter.HandleFunc("/teams", requireLeaderboardAccess(teamsPage)).Methods("GET")
+	mainrouter.HandleFunc("/seasons", requireLeaderboardAccess(seasonsPage)).Methods("GET")
+	mainrouter.HandleFunc("/seasons/{id}", requireLeaderboardAccess(seasonPage)).Methods("GET")
+	mainrouter.HandleFunc("/leaderboards/{leaderboard}/users/{username}", profilePage).Methods("GET")
+	mainrouter.HandleFunc("/charts/{leaderboard}/points.svg", pointsChart).Methods("GET")
+	mainrouter.HandleFunc("/charts/{leaderboard}/contributions.svg", contributionsChart).Methods("GET")
+	mainrouter.HandleFunc("/badge/{leaderboard}/top.svg", topBadge).Methods("GET")
+	mainrouter.HandleFunc("/badge/{leaderboard}/{login}.svg", userBadge).Methods("GET")
+	mainrouter.HandleFunc("/admin/adjustments", requireRole(model.ROLE_MODERATOR, adjustmentsPage)).Methods("GET")
+	mainrouter.HandleFunc("/admin/adjustments", requireRole(model.ROLE_MODERATOR, requireCsrfToken(submitAdjustment))).Methods("POST")
+	mainrouter.HandleFunc("/admin/adjustments/{id}/reverse", requireRole(model.ROLE_MODERATOR, requireCsrfToken(submitReversal))).Methods("POST")
+	mainrouter.HandleFunc("/admin/deliveries", requireRole(model.ROLE_ADMIN, deliveriesPage)).Methods("GET")
+	mainrouter.HandleFunc("/admin/deliveries/replay", requireRole(model.ROLE_ADMIN, requireCsrfToken(submitReplay))).Methods("POST")
+	mainrouter.HandleFunc("/admin/deliveries/{id}", requireRole(model.ROLE_ADMIN, deliveryPage)).Methods("GET")
+	mainrouter.HandleFunc("/oauth/login", oauthLogin).Methods("GET")
+	mainrouter.HandleFunc("/oauth/callback", oauthCallback).Methods("GET")
+	mainrouter.HandleFunc("/logout", logout).Methods("GET", "POST")
 	mainrouter.HandleFunc("/event", handleEvent).Methods("POST")
 
+	InitApi()
+
 	watchAndParseTemplates()
 }
 
@@ -86,45 +117,96 @@ func staticHandler(handler http.Handler) http.Handler {
 func root(w http.ResponseWriter, r *http.Request) {
 	page := NewHtmlTemplatePage("leaderboard", "Leaderboard")
 
-	if result := <-Srv.Store.LeaderboardEntry().GetRankings(Srv.Leaderboard.Id); result.Err != nil {
-		l4g.Error("Failed to load rankings, err=%v", result.Err.Error())
+	page.Props["LeaderboardName"] = Srv.Leaderboard.Name
+	page.Props["LiveUpdates"] = *Srv.Cfg.LiveSettings.Enable
+
+	page.Props["ShowActiveScore"] = *Srv.Cfg.DecaySettings.Enable
+	page.Props["RankByActiveScore"] = rankByActiveScore(r)
+
+	if rankings, err := getRankings(Srv.Leaderboard.Id, rankByActiveScore(r)); err != nil {
+		l4g.Error("Failed to load rankings, err=%v", err.Error())
+	} else {
+		page.Props["Rankings"] = rankings
+	}
+
+	// Rank snapshots are taken of the all time rankings, so there are no
+	// movements to show when ranking by active score
+	page.Props["Movement"] = getMovementParam(r)
+	page.Props["Movements"] = map[string]*model.RankMovement{}
+	if !rankByActiveScore(r) {
+		if movements, err := getMovements(Srv.Leaderboard.Id, getMovementParam(r)); err != nil {
+			l4g.Error("Failed to load rank movements, err=%v", err.Error())
+		} else {
+			page.Props["Movements"] = movements
+		}
+	}
+
+	page.Props["Badges"] = getBadgesByUsername(Srv.Leaderboard.Id)
+
+	page.Props["StreakPeriod"] = *Srv.Cfg.StreakSettings.Period
+	if streaks, err := getStreaks(Srv.Leaderboard.Id); err != nil {
+		l4g.Error("Failed to load streaks, err=%v", err.Error())
+		page.Props["Streaks"] = map[string]*model.Streak{}
+	} else {
+		page.Props["Streaks"] = streaks
+	}
+
+	if result := <-Srv.Store.LedgerEntry().GetRecent(Srv.Leaderboard.Id, RECENT_HISTORY_LIMIT); result.Err != nil {
+		l4g.Error("Failed to load recent history, err=%v", result.Err.Error())
 	} else {
-		page.Props["Rankings"] = result.Data.([]*model.LeaderboardEntry)
+		history := []*model.LedgerEntry{}
+		for _, entry := range result.Data.([]*model.LedgerEntry) {
+			if !Srv.Cfg.ExclusionSettings.IsExcluded(entry.Username, "") {
+				history = append(history, entry)
+			}
+		}
+		page.Props["History"] = history
 	}
 
 	w.Header().Set("Cache-Control", "no-cache, max-age=31556926, public")
 	page.Render(w)
 }
 
+// handleEvent queues a GitHub webhook delivery to be scored by the queue
+// workers, responding as soon as it's stored
 func handleEvent(w http.ResponseWriter, r *http.Request) {
-	event := model.EventFromJson(r.Body)
+	body, err := io.ReadAll(io.LimitReader(r.Body, MAX_EVENT_SIZE))
 
-	l4g.Debug(event.ToJson())
+	w.Header().Set("Content-Type", "text/plain")
 
-	fail := false
+	var event *model.Event
+	if err == nil {
+		event = model.EventFromJson(bytes.NewReader(body))
+	}
 
-	if event.Action == "closed" && event.PullRequest.Merged {
-		entry := &model.LeaderboardEntry{
-			LeaderboardId: Srv.Leaderboard.Id,
-			Username:      event.PullRequest.User.Login,
-		}
+	if event == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("fail"))
+		return
+	}
 
-		if result := <-Srv.Store.LeaderboardEntry().Save(entry); result.Err != nil {
-			l4g.Error("Unable to save entry, err=%v", result.Err.Error())
-			fail = true
-		}
+	eventType := r.Header.Get("X-GitHub-Event")
 
-		if result := <-Srv.Store.LeaderboardEntry().IncrementPoints(entry.Username, entry.LeaderboardId); result.Err != nil {
-			l4g.Error("Unable to update points, err=%v", result.Err.Error())
-			fail = true
-		}
+	if result := <-Srv.Store.Archive().Save(model.NewArchivedDelivery(r.Header, event, body)); result.Err != nil {
+		l4g.Error("Unable to archive event, err=%v", result.Err.Error())
 	}
 
-	w.Header().Set("Content-Type", "text/plain")
+	queued := &model.QueuedEvent{
+		EventType:    eventType,
+		DeliveryId:   r.Header.Get("X-GitHub-Delivery"),
+		PartitionKey: event.PartitionKey(eventType),
+		Payload:      string(body),
+	}
 
-	if fail {
+	if result := <-Srv.Store.Queue().Save(queued); result.Err != nil {
+		l4g.Error("Unable to queue event, err=%v", result.Err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("fail"))
 		return
 	}
-	w.Write([]byte("ok"))
+
+	wakeQueue()
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("queued"))
 }
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/jwilander/contributor-leaderboard/model"
+	"github.com/jwilander/contributor-leaderboard/utils"
+)
+
+const (
+	DEFAULT_DELIVERY_LIMIT = 50
+	MAX_DELIVERY_LIMIT     = 200
+)
+
+var webhookSender *utils.WebhookSender
+
+func initWebhookApi(api *mux.Router) {
+	webhooks := api.PathPrefix("/leaderboards/{leaderboard}/webhooks").Subrouter()
+	webhooks.HandleFunc("", requireRole(model.ROLE_ADMIN, getWebhooksHandler)).Methods("GET")
+	webhooks.HandleFunc("", requireRole(model.ROLE_ADMIN, createWebhookHandler)).Methods("POST")
+	webhooks.HandleFunc("/{id}", requireRole(model.ROLE_ADMIN, deleteWebhookHandler)).Methods("DELETE")
+	webhooks.HandleFunc("/{id}/deliveries", requireRole(model.ROLE_ADMIN, getWebhookDeliveriesHandler)).Methods("GET")
+	webhooks.HandleFunc("/{id}/deliveries/{delivery_id}/redeliver", requireRole(model.ROLE_ADMIN, redeliverWebhookHandler)).Methods("POST")
+}
+
+// initWebhooks starts delivering the events published to the hub to webhook
+// subscriptions
+func initWebhooks() {
+	settings := &Srv.Cfg.OutgoingWebhookSettings
+
+	webhookSender = utils.NewWebhookSender(
+		*settings.MaxRetries,
+		time.Duration(*settings.RetryBackoffMillis)*time.Millisecond,
+		time.Duration(*settings.TimeoutSeconds)*time.Second,
+	)
+
+	Srv.Hub.AddListener(dispatchWebhooks)
+}
+
+func dispatchWebhooks(event *model.DomainEvent) {
+	if result := <-Srv.Store.Webhook().GetSubscriptions(event.LeaderboardId); result.Err != nil {
+		l4g.Error("Unable to load webhook subscriptions, err=%v", result.Err.Error())
+	} else {
+		for _, subscription := range result.Data.([]*model.WebhookSubscription) {
+			if !subscription.Matches(event) {
+				continue
+			}
+
+			if err := queueWebhookDelivery(subscription, model.NewWebhookDelivery(subscription, event)); err != nil {
+				l4g.Error("Unable to queue webhook delivery, err=%v", err.Error())
+			}
+		}
+	}
+}
+
+// queueWebhookDelivery logs the delivery and sends it in the background,
+// updating the log once it has succeeded or run out of retries
+func queueWebhookDelivery(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) error {
+	if result := <-Srv.Store.Webhook().SaveDelivery(delivery); result.Err != nil {
+		return result.Err
+	}
+
+	go func() {
+		webhookSender.Send(subscription, delivery)
+
+		if !delivery.Success {
+			l4g.Warn("Webhook delivery %v to %v failed after %v attempts, err=%v", delivery.Id, subscription.Url, delivery.Attempts, delivery.Error)
+		}
+
+		if result := <-Srv.Store.Webhook().UpdateDelivery(delivery); result.Err != nil {
+			l4g.Error("Unable to update webhook delivery, err=%v", result.Err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// getWebhook looks up the subscription named in the request's route, writing
+// an error and returning nil if it doesn't belong to the leaderboard
+func getWebhook(w http.ResponseWriter, r *http.Request, leaderboard *model.Leaderboard) *model.WebhookSubscription {
+	if result := <-Srv.Store.Webhook().GetSubscription(mux.Vars(r)["id"]); result.Err != nil {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return nil
+	} else if subscription := result.Data.(*model.WebhookSubscription); subscription.LeaderboardId != leaderboard.Id {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return nil
+	} else {
+		return subscription
+	}
+}
+
+func getWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Webhook().GetSubscriptions(leaderboard.Id); result.Err != nil {
+		l4g.Error("Failed to load webhooks, err=%v", result.Err.Error())
+		http.Error(w, "failed to load webhooks", http.StatusInternalServerError)
+	} else {
+		subscriptions := result.Data.([]*model.WebhookSubscription)
+		for _, subscription := range subscriptions {
+			subscription.Sanitize()
+		}
+		writeJson(w, model.WebhookSubscriptionListToJson(subscriptions))
+	}
+}
+
+// createWebhookHandler subscribes a url to the leaderboard's events. The
+// secret payloads are signed with is generated unless one is given, and is
+// only returned in this response.
+func createWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	subscription := model.WebhookSubscriptionFromJson(r.Body)
+	if subscription == nil {
+		http.Error(w, "invalid webhook", http.StatusBadRequest)
+		return
+	}
+
+	subscription.Id = ""
+	subscription.LeaderboardId = leaderboard.Id
+
+	if result := <-Srv.Store.Webhook().SaveSubscription(subscription); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l4g.Info("%v subscribed %v to the events of %v", getActor(r), subscription.Url, leaderboard.Name)
+
+	writeCreatedJson(w, subscription.ToJson())
+}
+
+func deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	subscription := getWebhook(w, r, leaderboard)
+	if subscription == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Webhook().DeleteSubscription(subscription.Id); result.Err != nil {
+		http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	l4g.Info("%v unsubscribed %v from the events of %v", getActor(r), subscription.Url, leaderboard.Name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	subscription := getWebhook(w, r, leaderboard)
+	if subscription == nil {
+		return
+	}
+
+	limit := getIntParam(r, "limit", DEFAULT_DELIVERY_LIMIT, MAX_DELIVERY_LIMIT)
+
+	if result := <-Srv.Store.Webhook().GetDeliveries(subscription.Id, limit); result.Err != nil {
+		l4g.Error("Failed to load webhook deliveries, err=%v", result.Err.Error())
+		http.Error(w, "failed to load webhook deliveries", http.StatusInternalServerError)
+	} else {
+		writeJson(w, model.WebhookDeliveryListToJson(result.Data.([]*model.WebhookDelivery)))
+	}
+}
+
+// redeliverWebhookHandler sends a logged delivery's payload again as a new
+// delivery, which is returned before it's sent
+func redeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	leaderboard := getLeaderboard(w, r)
+	if leaderboard == nil {
+		return
+	}
+
+	subscription := getWebhook(w, r, leaderboard)
+	if subscription == nil {
+		return
+	}
+
+	var delivery *model.WebhookDelivery
+	if result := <-Srv.Store.Webhook().GetDelivery(mux.Vars(r)["delivery_id"]); result.Err != nil {
+		http.Error(w, "delivery not found", http.StatusNotFound)
+		return
+	} else if delivery = result.Data.(*model.WebhookDelivery); delivery.SubscriptionId != subscription.Id {
+		http.Error(w, "delivery not found", http.StatusNotFound)
+		return
+	}
+
+	redelivery := delivery.Redeliver()
+	if err := queueWebhookDelivery(subscription, redelivery); err != nil {
+		l4g.Error("Failed to redeliver webhook, err=%v", err.Error())
+		http.Error(w, "failed to redeliver webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJson(w, redelivery.ToJson())
+}